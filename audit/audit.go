@@ -0,0 +1,373 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSize is the size in bytes the audit file reaches before it is rotated by default
+const DefaultMaxSize = 100 << 20
+
+// DefaultMaxBackups is how many rotated audit files are kept by default
+const DefaultMaxBackups = 5
+
+// DefaultRecentSize is how many entries are kept in memory to answer queries
+const DefaultRecentSize = 1000
+
+// Outcomes of the audited requests
+const (
+	OutcomeAllowed         = "allowed"
+	OutcomeUnauthenticated = "unauthenticated"
+	OutcomeDenied          = "denied"
+	OutcomeRateLimited     = "rate-limited"
+	OutcomeFailed          = "failed"
+)
+
+// Entry records who queried what and how the query ended
+type Entry struct {
+	Timestamp time.Time
+	// Identity is the name of the credential, it is empty when the request was not authenticated
+	Identity  string `json:",omitempty"`
+	ClientIP  string `json:",omitempty"`
+	RequestID string `json:",omitempty"`
+	Method    string
+	// Endpoint is the route of the request or the full name of the gRPC method
+	Endpoint string
+	Cluster  string `json:",omitempty"`
+	Service  string `json:",omitempty"`
+	Stack    string `json:",omitempty"`
+	// Status is the HTTP status code of the response, Code the status code of a gRPC call
+	Status  int    `json:",omitempty"`
+	Code    string `json:",omitempty"`
+	Outcome string
+}
+
+// Entries are audit entries, from the most to the least recent
+type Entries []Entry
+
+// Rows implements report.Tabular
+func (e Entries) Rows() [][]string {
+	rows := [][]string{{"TIMESTAMP", "IDENTITY", "METHOD", "ENDPOINT", "SERVICE", "STACK", "STATUS", "OUTCOME"}}
+	for _, entry := range e {
+		status := entry.Code
+		if entry.Status != 0 {
+			status = fmt.Sprintf("%d", entry.Status)
+		}
+		rows = append(rows, []string{entry.Timestamp.Format(time.RFC3339), entry.Identity, entry.Method, entry.Endpoint, entry.Service, entry.Stack, status, entry.Outcome})
+	}
+
+	return rows
+}
+
+// Outcome returns the outcome of a request answered with the HTTP status code
+func Outcome(status int) string {
+	switch {
+	case status == 401:
+		return OutcomeUnauthenticated
+	case status == 403:
+		return OutcomeDenied
+	case status == 429:
+		return OutcomeRateLimited
+	case status >= 400:
+		return OutcomeFailed
+	default:
+		return OutcomeAllowed
+	}
+}
+
+// Query selects audit entries, empty fields match every entry
+type Query struct {
+	Identity string
+	Service  string
+	Stack    string
+	Outcome  string
+	Since    time.Time
+	// Limit is the maximum number of entries returned, every matching entry is returned when 0
+	Limit int
+}
+
+// matches reports whether the entry is selected by the query
+func (q Query) matches(entry Entry) bool {
+	return (q.Identity == "" || q.Identity == entry.Identity) &&
+		(q.Service == "" || q.Service == entry.Service) &&
+		(q.Stack == "" || q.Stack == entry.Stack) &&
+		(q.Outcome == "" || q.Outcome == entry.Outcome) &&
+		!entry.Timestamp.Before(q.Since)
+}
+
+// Log appends the entries as JSON lines to a file, rotating it once it reaches MaxSize, and keeps the most recent
+// entries in memory to answer queries
+type Log struct {
+	// MaxSize is the size in bytes the file reaches before it is renamed to <path>.1, the older files are renamed
+	// to <path>.2 and so on
+	MaxSize int64
+	// MaxBackups is how many rotated files are kept, a full file is discarded when it is 0
+	MaxBackups int
+	// RecentSize is how many entries are kept in memory, DefaultRecentSize when it is 0
+	RecentSize int
+
+	path   string
+	mutex  sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+	// recent is a ring buffer of the entries kept in memory, next is where the next entry goes and count how many
+	// entries it holds
+	recent []Entry
+	next   int
+	count  int
+}
+
+// Open opens the audit file at path for appending, creating it when needed, and loads its most recent entries
+func Open(path string) (*Log, error) {
+	l := &Log{MaxSize: DefaultMaxSize, MaxBackups: DefaultMaxBackups, RecentSize: DefaultRecentSize, path: path}
+
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// load reads the last entries of the current file into memory
+func (l *Log) load() error {
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	tail, err := tail(file, l.recentSize())
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(tail)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		entry := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		l.remember(entry)
+	}
+
+	return scanner.Err()
+}
+
+// tail returns a reader of the last lines of the file, reading it backwards block by block until it found them
+func tail(file *os.File, lines int) (io.Reader, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := info.Size()
+	block := make([]byte, 64*1024)
+	// the last line ends with a newline, so one more newline than lines is needed to start at a line boundary
+	for newlines := 0; offset > 0 && newlines <= lines; {
+		n := int64(len(block))
+		if n > offset {
+			n = offset
+		}
+		offset -= n
+
+		if _, err := file.ReadAt(block[:n], offset); err != nil {
+			return nil, err
+		}
+		newlines += bytes.Count(block[:n], []byte{'\n'})
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(file, offset, info.Size()-offset))
+	if offset > 0 {
+		if _, err := reader.ReadBytes('\n'); err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+
+	return reader, nil
+}
+
+func (l *Log) open() error {
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// Record appends the entry to the file
+func (l *Log) Record(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.closed {
+		return fmt.Errorf("The audit log %s is closed.", l.path)
+	}
+
+	// a file that could not be reopened after a rotation is opened again
+	if l.file == nil {
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+
+	var rotateErr error
+	if l.MaxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.MaxSize {
+		if rotateErr = l.rotate(); l.file == nil {
+			return rotateErr
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+
+	l.remember(entry)
+	return rotateErr
+}
+
+// rotate renames the file to <path>.1, shifting the older files and removing the oldest one, and opens a new file.
+// The current file is opened again when it could not be renamed, the rotation is then retried by the next entry.
+func (l *Log) rotate() error {
+	err := l.file.Close()
+	l.file = nil
+	if err == nil {
+		err = l.shift()
+	}
+
+	if openErr := l.open(); openErr != nil {
+		return openErr
+	}
+
+	return err
+}
+
+// shift renames the closed file to <path>.1, shifting the older files and removing the oldest one, or removes it
+// when no backup is kept
+func (l *Log) shift() error {
+	if l.MaxBackups > 0 {
+		os.Remove(fmt.Sprintf("%s.%d", l.path, l.MaxBackups))
+		for i := l.MaxBackups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+		}
+		if err := os.Rename(l.path, l.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(l.path); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// recentSize returns how many entries are kept in memory
+func (l *Log) recentSize() int {
+	if l.RecentSize > 0 {
+		return l.RecentSize
+	}
+
+	return DefaultRecentSize
+}
+
+// remember keeps the entry in memory, overwriting the oldest entry once RecentSize entries are kept
+func (l *Log) remember(entry Entry) {
+	if size := l.recentSize(); len(l.recent) != size {
+		l.resize(size)
+	}
+
+	l.recent[l.next] = entry
+	l.next = (l.next + 1) % len(l.recent)
+	if l.count < len(l.recent) {
+		l.count++
+	}
+}
+
+// resize moves the most recent entries to a ring buffer of the size, RecentSize may change after Open
+func (l *Log) resize(size int) {
+	entries := l.entries()
+	if len(entries) > size {
+		entries = entries[:size]
+	}
+
+	l.recent = make([]Entry, size)
+	l.count = len(entries)
+	l.next = l.count % size
+	for i, entry := range entries {
+		l.recent[l.count-1-i] = entry
+	}
+}
+
+// entries returns the entries kept in memory, from the most to the least recent
+func (l *Log) entries() Entries {
+	entries := make(Entries, 0, l.count)
+	for i := 1; i <= l.count; i++ {
+		entries = append(entries, l.recent[(l.next-i+len(l.recent))%len(l.recent)])
+	}
+
+	return entries
+}
+
+// Recent returns the entries kept in memory selected by the query, from the most to the least recent
+func (l *Log) Recent(query Query) Entries {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entries := Entries{}
+	for i := 1; i <= l.count; i++ {
+		if query.Limit > 0 && len(entries) == query.Limit {
+			break
+		}
+
+		if entry := l.recent[(l.next-i+len(l.recent))%len(l.recent)]; query.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// Close closes the file, entries can no longer be recorded
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.closed = true
+	if l.file == nil {
+		return nil
+	}
+
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
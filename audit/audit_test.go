@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestAuditTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
+
+func (s *AuditTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "audit")
+	s.Require().NoError(err)
+	s.dir = dir
+}
+
+func (s *AuditTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+func (s *AuditTestSuite) entry(i int, identity string, outcome string) Entry {
+	return Entry{
+		Timestamp: time.Date(2017, time.November, 26, 21, 47, i, 0, time.UTC),
+		Identity:  identity,
+		Method:    "GET",
+		Endpoint:  "/v1/docker-swarm-service-status/service-status/{service}",
+		Service:   fmt.Sprintf("billing_%d", i),
+		Status:    200,
+		Outcome:   outcome,
+	}
+}
+
+func (s *AuditTestSuite) lines(path string) []Entry {
+	file, err := os.Open(path)
+	s.Require().NoError(err)
+	defer file.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := Entry{}
+		s.Require().NoError(json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func (s *AuditTestSuite) Test_Record_AppendsJSONLines() {
+	path := filepath.Join(s.dir, "audit.log")
+
+	log, err := Open(path)
+	s.Require().NoError(err)
+	s.NoError(log.Record(s.entry(1, "jenkins", OutcomeAllowed)))
+	s.NoError(log.Record(s.entry(2, "jenkins", OutcomeDenied)))
+	s.NoError(log.Close())
+
+	s.Equal([]Entry{s.entry(1, "jenkins", OutcomeAllowed), s.entry(2, "jenkins", OutcomeDenied)}, s.lines(path))
+
+	info, err := os.Stat(path)
+	s.NoError(err)
+	s.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	log, err = Open(path)
+	s.Require().NoError(err)
+	defer log.Close()
+	s.NoError(log.Record(s.entry(3, "admin", OutcomeAllowed)))
+
+	s.Len(s.lines(path), 3, "the file is appended to")
+	s.Equal(Entries{s.entry(3, "admin", OutcomeAllowed), s.entry(2, "jenkins", OutcomeDenied), s.entry(1, "jenkins", OutcomeAllowed)}, log.Recent(Query{}), "the entries of the file are loaded")
+}
+
+func (s *AuditTestSuite) Test_Record_RotatesTheFile() {
+	path := filepath.Join(s.dir, "audit.log")
+
+	log, err := Open(path)
+	s.Require().NoError(err)
+	defer log.Close()
+
+	line, _ := json.Marshal(s.entry(1, "jenkins", OutcomeAllowed))
+	log.MaxSize = int64(2 * (len(line) + 1))
+	log.MaxBackups = 2
+
+	for i := 1; i <= 7; i++ {
+		s.NoError(log.Record(s.entry(i, "jenkins", OutcomeAllowed)))
+	}
+
+	s.Equal([]Entry{s.entry(7, "jenkins", OutcomeAllowed)}, s.lines(path))
+	s.Equal([]Entry{s.entry(5, "jenkins", OutcomeAllowed), s.entry(6, "jenkins", OutcomeAllowed)}, s.lines(path+".1"))
+	s.Equal([]Entry{s.entry(3, "jenkins", OutcomeAllowed), s.entry(4, "jenkins", OutcomeAllowed)}, s.lines(path+".2"))
+	_, err = os.Stat(path + ".3")
+	s.True(os.IsNotExist(err))
+
+	s.Len(log.Recent(Query{}), 7, "rotation keeps the recent entries")
+}
+
+func (s *AuditTestSuite) Test_Record_ReopensTheFileWhenRotationFails() {
+	path := filepath.Join(s.dir, "audit.log")
+
+	log, err := Open(path)
+	s.Require().NoError(err)
+	defer log.Close()
+
+	line, _ := json.Marshal(s.entry(1, "jenkins", OutcomeAllowed))
+	log.MaxSize = int64(len(line) + 1)
+	log.MaxBackups = 1
+
+	// a directory that is not empty can neither be removed nor replaced by the file
+	s.Require().NoError(os.MkdirAll(filepath.Join(path+".1", "lost+found"), 0700))
+
+	s.NoError(log.Record(s.entry(1, "jenkins", OutcomeAllowed)))
+	s.Error(log.Record(s.entry(2, "jenkins", OutcomeAllowed)))
+	s.Equal([]Entry{s.entry(1, "jenkins", OutcomeAllowed), s.entry(2, "jenkins", OutcomeAllowed)}, s.lines(path), "the entry is appended to the current file")
+
+	s.Require().NoError(os.RemoveAll(path + ".1"))
+
+	s.NoError(log.Record(s.entry(3, "jenkins", OutcomeAllowed)))
+	s.Equal([]Entry{s.entry(3, "jenkins", OutcomeAllowed)}, s.lines(path))
+	s.Equal([]Entry{s.entry(1, "jenkins", OutcomeAllowed), s.entry(2, "jenkins", OutcomeAllowed)}, s.lines(path+".1"))
+}
+
+func (s *AuditTestSuite) Test_Open_LoadsTheTailOfTheFile() {
+	path := filepath.Join(s.dir, "audit.log")
+
+	log, err := Open(path)
+	s.Require().NoError(err)
+	for i := 1; i <= DefaultRecentSize+500; i++ {
+		s.Require().NoError(log.Record(s.entry(i, "jenkins", OutcomeAllowed)))
+	}
+	s.NoError(log.Close())
+
+	log, err = Open(path)
+	s.Require().NoError(err)
+	defer log.Close()
+
+	entries := log.Recent(Query{})
+	s.Require().Len(entries, DefaultRecentSize)
+	s.Equal(s.entry(DefaultRecentSize+500, "jenkins", OutcomeAllowed), entries[0])
+	s.Equal(s.entry(501, "jenkins", OutcomeAllowed), entries[DefaultRecentSize-1])
+}
+
+func (s *AuditTestSuite) Test_Recent_FiltersEntries() {
+	log, err := Open(filepath.Join(s.dir, "audit.log"))
+	s.Require().NoError(err)
+	defer log.Close()
+	log.RecentSize = 3
+
+	s.NoError(log.Record(s.entry(1, "jenkins", OutcomeAllowed)))
+	s.NoError(log.Record(s.entry(2, "jenkins", OutcomeDenied)))
+	s.NoError(log.Record(s.entry(3, "admin", OutcomeAllowed)))
+	s.NoError(log.Record(s.entry(4, "jenkins", OutcomeAllowed)))
+
+	s.Equal(Entries{s.entry(4, "jenkins", OutcomeAllowed), s.entry(3, "admin", OutcomeAllowed), s.entry(2, "jenkins", OutcomeDenied)}, log.Recent(Query{}))
+	s.Equal(Entries{s.entry(4, "jenkins", OutcomeAllowed), s.entry(2, "jenkins", OutcomeDenied)}, log.Recent(Query{Identity: "jenkins"}))
+	s.Equal(Entries{s.entry(4, "jenkins", OutcomeAllowed)}, log.Recent(Query{Identity: "jenkins", Limit: 1}))
+	s.Equal(Entries{s.entry(2, "jenkins", OutcomeDenied)}, log.Recent(Query{Outcome: OutcomeDenied}))
+	s.Equal(Entries{s.entry(3, "admin", OutcomeAllowed)}, log.Recent(Query{Service: "billing_3"}))
+	s.Equal(Entries{s.entry(4, "jenkins", OutcomeAllowed), s.entry(3, "admin", OutcomeAllowed)}, log.Recent(Query{Since: s.entry(3, "", "").Timestamp}))
+}
+
+func (s *AuditTestSuite) Test_Record_ClosedLog() {
+	log, err := Open(filepath.Join(s.dir, "audit.log"))
+	s.Require().NoError(err)
+	s.NoError(log.Close())
+
+	s.Error(log.Record(s.entry(1, "jenkins", OutcomeAllowed)))
+}
+
+func (s *AuditTestSuite) Test_Outcome() {
+	s.Equal(OutcomeAllowed, Outcome(200))
+	s.Equal(OutcomeAllowed, Outcome(304))
+	s.Equal(OutcomeUnauthenticated, Outcome(401))
+	s.Equal(OutcomeDenied, Outcome(403))
+	s.Equal(OutcomeRateLimited, Outcome(429))
+	s.Equal(OutcomeFailed, Outcome(404))
+	s.Equal(OutcomeFailed, Outcome(500))
+}
@@ -0,0 +1,216 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+)
+
+// ClusterHeader selects the cluster queried on the server
+const ClusterHeader = "X-Swarm-Cluster"
+
+// Client queries a docker-swarm-service-status server
+type Client struct {
+	// URL is the base URL of the server, e.g. http://service-status:8080
+	URL string
+	// Token is sent as bearer token when not empty
+	Token string
+	// Cluster selects a named cluster on the server when not empty
+	Cluster    string
+	HTTPClient *http.Client
+	// Retries is how many times a request is retried after a network error or a 502, 503 or 504 response
+	Retries int
+	// Backoff is the delay before the first retry, it doubles on every retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Error is returned when the server answers with an unexpected status code
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("the server returned %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("the server returned %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// NewClient returns a new instance of the Client structure with the default retry policy
+func NewClient(serverURL string) *Client {
+	return &Client{
+		URL:        strings.TrimRight(serverURL, "/"),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Retries:    3,
+		Backoff:    500 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+	}
+}
+
+// ServiceStatus returns the current state of the service
+func (c *Client) ServiceStatus(ctx context.Context, serviceName string) (service.ServiceStatus, error) {
+	status := service.ServiceStatus{}
+	err := c.get(ctx, fmt.Sprintf("/v1/docker-swarm-service-status/service-status/%s", url.PathEscape(serviceName)), &status)
+
+	return status, err
+}
+
+// DeploymentStatus returns the state of the service and verifies that the image was deployed
+func (c *Client) DeploymentStatus(ctx context.Context, serviceName string, image string) (service.ServiceStatus, error) {
+	status := service.ServiceStatus{}
+	err := c.get(ctx, fmt.Sprintf("/v1/docker-swarm-service-status/deployment-status/%s/%s", url.PathEscape(serviceName), base64.URLEncoding.EncodeToString([]byte(image))), &status)
+
+	return status, err
+}
+
+// StackStatus returns the current state of every service of the stack
+func (c *Client) StackStatus(ctx context.Context, stackName string) (service.StackStatus, error) {
+	status := service.StackStatus{}
+	err := c.get(ctx, fmt.Sprintf("/v1/docker-swarm-service-status/stack-status/%s", url.PathEscape(stackName)), &status)
+
+	return status, err
+}
+
+// BatchDeploymentStatus returns the state of every deployment together with the worst of their verdicts
+func (c *Client) BatchDeploymentStatus(ctx context.Context, deployments ...service.DeploymentCheck) (service.BatchStatus, error) {
+	body, err := json.Marshal(service.BatchRequest{Deployments: deployments})
+	if err != nil {
+		return service.BatchStatus{}, err
+	}
+
+	status := service.BatchStatus{}
+	err = c.retry(ctx, "POST", "/v1/docker-swarm-service-status/batch/deployment-status", body, &status)
+
+	return status, err
+}
+
+// WaitForDeployment polls the deployment status every interval until its verdict is no longer in progress.
+// When ctx expires it returns the last status together with the context error.
+func (c *Client) WaitForDeployment(ctx context.Context, serviceName string, image string, interval time.Duration) (service.ServiceStatus, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := service.ServiceStatus{}
+	for {
+		status, err := c.DeploymentStatus(ctx, serviceName, image)
+		if err != nil && ctx.Err() != nil {
+			return last, ctx.Err()
+		}
+		if err != nil {
+			return status, err
+		}
+
+		if status.Verdict() != service.VerdictInProgress {
+			return status, nil
+		}
+		last = status
+
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	return c.retry(ctx, "GET", path, nil, v)
+}
+
+// retry sends the request until it succeeds, fails with an error that is not retryable or runs out of retries.
+// Every endpoint is read-only, so POST requests are retried as well.
+func (c *Client) retry(ctx context.Context, method string, path string, body []byte, v interface{}) error {
+	backoff := c.Backoff
+
+	for attempt := 0; ; attempt++ {
+		err := c.do(ctx, method, path, body, v)
+		if err == nil || attempt >= c.Retries || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if c.MaxBackoff > 0 && backoff > c.MaxBackoff {
+			backoff = c.MaxBackoff
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body []byte, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, c.URL+path, reader)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if c.Cluster != "" {
+		req.Header.Set(ClusterHeader, c.Cluster)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func decodeError(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	message := struct {
+		Error string `json:"error"`
+	}{}
+	json.Unmarshal(body, &message)
+
+	return &Error{resp.StatusCode, message.Error}
+}
+
+// retryable reports whether a request failing with err may succeed when retried
+func retryable(err error) bool {
+	if apiErr, ok := err.(*Error); ok {
+		switch apiErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	_, ok := err.(*url.Error)
+	return ok
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/server"
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientTestSuite struct {
+	suite.Suite
+	serviceMock *ServiceMock
+	httpServer  *httptest.Server
+	client      *Client
+}
+
+func TestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
+
+func (s *ClientTestSuite) SetupTest() {
+	s.serviceMock = new(ServiceMock)
+	s.httpServer = httptest.NewServer(server.NewServer(s.serviceMock).Handler())
+	s.client = NewClient(s.httpServer.URL)
+	s.client.Backoff = time.Millisecond
+}
+
+func (s *ClientTestSuite) TearDownTest() {
+	s.httpServer.Close()
+}
+
+func (s *ClientTestSuite) Test_ServiceStatus_ReturnServiceStatus() {
+	replicas := uint64(1)
+	expected := service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "docker-routing-mesh", Replicas: &replicas, RunningReplicas: 1}
+	s.serviceMock.On("GetServiceStatus", "docker-routing-mesh").Return(expected, nil)
+
+	status, err := s.client.ServiceStatus(context.Background(), "docker-routing-mesh")
+
+	s.NoError(err)
+	s.Equal(expected, status)
+}
+
+func (s *ClientTestSuite) Test_DeploymentStatus_EncodesImage() {
+	image := "albertogviana/docker-routing-mesh:1.0.0"
+	s.serviceMock.On("GetDeploymentStatus", "docker-routing-mesh", image).Return(service.ServiceStatus{Name: "docker-routing-mesh"}, nil)
+
+	status, err := s.client.DeploymentStatus(context.Background(), "docker-routing-mesh", image)
+
+	s.NoError(err)
+	s.Equal("docker-routing-mesh", status.Name)
+}
+
+func (s *ClientTestSuite) Test_StackStatus_ReturnStackStatus() {
+	expected := service.StackStatus{Name: "prod", Services: []service.ServiceStatus{{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web"}}}
+	s.serviceMock.On("GetStackStatus", "prod").Return(expected, nil)
+
+	status, err := s.client.StackStatus(context.Background(), "prod")
+
+	s.NoError(err)
+	s.Equal(expected, status)
+}
+
+func (s *ClientTestSuite) Test_BatchDeploymentStatus_ReturnBatchStatus() {
+	replicas := uint64(1)
+	s.serviceMock.On("GetDeploymentStatus", "prod_web", "prod/web:1.1.0").Return(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web", Replicas: &replicas, RunningReplicas: 1}, nil)
+	s.serviceMock.On("GetDeploymentStatus", "prod_api", "prod/api:1.1.0").Return(service.ServiceStatus{Name: "prod_api"}, nil)
+
+	status, err := s.client.BatchDeploymentStatus(context.Background(),
+		service.DeploymentCheck{Service: "prod_web", Image: "prod/web:1.1.0"},
+		service.DeploymentCheck{Service: "prod_api", Image: "prod/api:1.1.0"},
+	)
+
+	s.NoError(err)
+	s.Equal(service.VerdictNotFound, status.Verdict)
+	s.Require().Len(status.Deployments, 2)
+	s.Equal(service.VerdictSucceeded, status.Deployments[0].Verdict)
+	s.Equal("prod_api", status.Deployments[1].Service)
+}
+
+func (s *ClientTestSuite) Test_ServiceStatus_ReturnError() {
+	s.serviceMock.On("GetServiceStatus", "docker-routing-mesh").Return(service.ServiceStatus{}, errors.New("Not able to connect on unix:///var/run/docker.sock"))
+
+	_, err := s.client.ServiceStatus(context.Background(), "docker-routing-mesh")
+
+	s.Equal(&Error{500, "Not able to connect on unix:///var/run/docker.sock"}, err)
+	s.serviceMock.AssertNumberOfCalls(s.T(), "GetServiceStatus", 1)
+}
+
+func (s *ClientTestSuite) Test_WaitForDeployment_ReturnFinalStatus() {
+	image := "albertogviana/docker-routing-mesh:1.0.0"
+	replicas := uint64(2)
+	inProgress := service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Replicas: &replicas, RunningReplicas: 1}
+	done := service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Replicas: &replicas, RunningReplicas: 2}
+	s.serviceMock.On("GetDeploymentStatus", "docker-routing-mesh", image).Return(inProgress, nil).Once()
+	s.serviceMock.On("GetDeploymentStatus", "docker-routing-mesh", image).Return(done, nil).Once()
+
+	status, err := s.client.WaitForDeployment(context.Background(), "docker-routing-mesh", image, time.Millisecond)
+
+	s.NoError(err)
+	s.Equal(service.VerdictSucceeded, status.Verdict())
+}
+
+func (s *ClientTestSuite) Test_WaitForDeployment_ReturnContextError() {
+	image := "albertogviana/docker-routing-mesh:1.0.0"
+	replicas := uint64(2)
+	s.serviceMock.On("GetDeploymentStatus", "docker-routing-mesh", image).Return(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Replicas: &replicas}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	status, err := s.client.WaitForDeployment(ctx, "docker-routing-mesh", image, 5*time.Millisecond)
+
+	s.Equal(context.DeadlineExceeded, err)
+	s.Equal(service.VerdictInProgress, status.Verdict())
+}
+
+func (s *ClientTestSuite) Test_Get_RetriesUnavailableServer() {
+	calls := int32(0)
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"Name":"docker-routing-mesh"}`))
+	}))
+	defer unavailable.Close()
+
+	client := NewClient(unavailable.URL)
+	client.Backoff = time.Millisecond
+
+	status, err := client.ServiceStatus(context.Background(), "docker-routing-mesh")
+
+	s.NoError(err)
+	s.Equal("docker-routing-mesh", status.Name)
+	s.Equal(int32(3), atomic.LoadInt32(&calls))
+}
+
+func (s *ClientTestSuite) Test_Get_SendsTokenAndCluster() {
+	received := http.Header{}
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		w.Write([]byte(`{}`))
+	}))
+	defer echo.Close()
+
+	client := NewClient(echo.URL)
+	client.Token = "s3cr3t"
+	client.Cluster = "staging"
+
+	_, err := client.ServiceStatus(context.Background(), "docker-routing-mesh")
+
+	s.NoError(err)
+	s.Equal("Bearer s3cr3t", received.Get("Authorization"))
+	s.Equal("staging", received.Get(ClusterHeader))
+}
+
+type ServiceMock struct {
+	mock.Mock
+}
+
+func (s *ServiceMock) GetDeploymentStatus(serviceName string, image string) (service.ServiceStatus, error) {
+	args := s.Called(serviceName, image)
+	return args.Get(0).(service.ServiceStatus), args.Error(1)
+}
+
+func (s *ServiceMock) GetServiceStatus(serviceName string) (service.ServiceStatus, error) {
+	args := s.Called(serviceName)
+	return args.Get(0).(service.ServiceStatus), args.Error(1)
+}
+
+func (s *ServiceMock) GetStackStatus(stackName string) (service.StackStatus, error) {
+	args := s.Called(stackName)
+	return args.Get(0).(service.StackStatus), args.Error(1)
+}
+
+func (s *ServiceMock) GetInfo() (service.Info, error) {
+	args := s.Called()
+	return args.Get(0).(service.Info), args.Error(1)
+}
+
+func (s *ServiceMock) GetReadiness() service.Readiness {
+	args := s.Called()
+	return args.Get(0).(service.Readiness)
+}
+
+func (s *ServiceMock) GetServices(filter filters.Args) ([]swarm.Service, error) {
+	args := s.Called(filter)
+	return args.Get(0).([]swarm.Service), args.Error(1)
+}
+
+func (s *ServiceMock) GetService(filter filters.Args) (swarm.Service, error) {
+	args := s.Called(filter)
+	return args.Get(0).(swarm.Service), args.Error(1)
+}
+
+func (s *ServiceMock) GetTask(filter filters.Args) ([]swarm.Task, error) {
+	args := s.Called(filter)
+	return args.Get(0).([]swarm.Task), args.Error(1)
+}
+
+func (s *ServiceMock) GetNodes(filter filters.Args) ([]swarm.Node, error) {
+	args := s.Called(filter)
+	return args.Get(0).([]swarm.Node), args.Error(1)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/client"
+	"github.com/albertogviana/docker-swarm-service-status/report"
+	"github.com/albertogviana/docker-swarm-service-status/service"
+)
+
+// Exit codes returned by the commands
+const (
+	ExitSuccess  = 0
+	ExitFailure  = 1
+	ExitRollback = 2
+	ExitTimeout  = 3
+	ExitNotFound = 4
+	ExitError    = 5
+)
+
+// statusSource is implemented by service.Service, which talks to Docker, and by remoteSource
+type statusSource interface {
+	GetServiceStatus(serviceName string) (service.ServiceStatus, error)
+	GetDeploymentStatus(serviceName string, image string) (service.ServiceStatus, error)
+}
+
+// clientFlags are shared by every client command
+type clientFlags struct {
+	server  string
+	token   string
+	cluster string
+	output  string
+}
+
+func newFlagSet(name, arguments string, stderr io.Writer) (*flag.FlagSet, *clientFlags) {
+	flags := &clientFlags{}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: docker-swarm-service-status %s [flags] %s\n\nFlags:\n", name, arguments)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&flags.server, "server", os.Getenv("SERVICE_STATUS_URL"), "URL of a running server, Docker is queried directly when empty")
+	fs.StringVar(&flags.token, "token", os.Getenv("SERVICE_STATUS_TOKEN"), "bearer token sent to the server")
+	fs.StringVar(&flags.cluster, "cluster", "", "cluster queried on the server")
+	fs.StringVar(&flags.output, "output", "table", "output format, table, json or junit")
+
+	return fs, flags
+}
+
+func (f *clientFlags) validate() error {
+	if f.output != "table" && f.output != "json" && f.output != "junit" {
+		return fmt.Errorf("invalid output %q, expected table, json or junit", f.output)
+	}
+
+	if f.server == "" && f.cluster != "" {
+		return fmt.Errorf("--cluster requires --server")
+	}
+
+	return nil
+}
+
+func (f *clientFlags) source() (statusSource, error) {
+	if f.server != "" {
+		return newRemoteSource(f.server, f.token, f.cluster), nil
+	}
+
+	config := service.ConfigFromEnv()
+	config.DefaultHeaders = map[string]string{"User-Agent": "docker-swarm-service-status-cli-1.0"}
+
+	return service.NewServiceWithConfig(config)
+}
+
+// parse parses the command line, returning the source to query and the positional arguments
+func parse(fs *flag.FlagSet, flags *clientFlags, args []string, expected int, stderr io.Writer) (statusSource, []string, int) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil, nil, ExitSuccess
+		}
+		return nil, nil, ExitError
+	}
+
+	if fs.NArg() != expected {
+		fs.Usage()
+		return nil, nil, ExitError
+	}
+
+	if err := flags.validate(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, nil, ExitError
+	}
+
+	source, err := flags.source()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, nil, ExitError
+	}
+
+	return source, fs.Args(), -1
+}
+
+func statusCommand(args []string, stdout, stderr io.Writer) int {
+	fs, flags := newFlagSet("status", "<service>", stderr)
+	source, args, code := parse(fs, flags, args, 1, stderr)
+	if source == nil {
+		return code
+	}
+
+	status, err := source.GetServiceStatus(args[0])
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+
+	return reportStatus(status, flags.output, stdout, stderr)
+}
+
+func deployStatusCommand(args []string, stdout, stderr io.Writer) int {
+	fs, flags := newFlagSet("deploy-status", "<service> <image>", stderr)
+	source, args, code := parse(fs, flags, args, 2, stderr)
+	if source == nil {
+		return code
+	}
+
+	status, err := source.GetDeploymentStatus(args[0], args[1])
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+
+	return reportStatus(status, flags.output, stdout, stderr)
+}
+
+func waitCommand(args []string, stdout, stderr io.Writer) int {
+	fs, flags := newFlagSet("wait", "<service> <image>", stderr)
+	timeout := fs.Duration("timeout", 10*time.Minute, "maximum time to wait for the deployment")
+	interval := fs.Duration("interval", 5*time.Second, "time between two checks")
+
+	source, args, code := parse(fs, flags, args, 2, stderr)
+	if source == nil {
+		return code
+	}
+
+	deadline := time.Now().Add(*timeout)
+	for {
+		status, err := source.GetDeploymentStatus(args[0], args[1])
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+
+		if status.Verdict() != service.VerdictInProgress {
+			return reportStatus(status, flags.output, stdout, stderr)
+		}
+
+		fmt.Fprintf(stderr, "%s: %s, %s\n", status.Name, status.Verdict(), report.Replicas(status))
+
+		if time.Now().Add(*interval).After(deadline) {
+			fmt.Fprintf(stderr, "Timed out after %s waiting for the deployment of %s\n", *timeout, args[1])
+			reportStatus(status, flags.output, stdout, stderr)
+			return ExitTimeout
+		}
+
+		time.Sleep(*interval)
+	}
+}
+
+// reportStatus prints the status and returns the exit code matching its verdict
+func reportStatus(status service.ServiceStatus, output string, stdout, stderr io.Writer) int {
+	if err := printStatus(stdout, status, output); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+
+	return exitCode(status.Verdict())
+}
+
+func exitCode(verdict service.Verdict) int {
+	switch verdict {
+	case service.VerdictSucceeded:
+		return ExitSuccess
+	case service.VerdictRolledBack:
+		return ExitRollback
+	case service.VerdictNotFound:
+		return ExitNotFound
+	}
+
+	return ExitFailure
+}
+
+// remoteSource queries a running server
+type remoteSource struct {
+	client *client.Client
+}
+
+func newRemoteSource(serverURL, token, cluster string) *remoteSource {
+	c := client.NewClient(serverURL)
+	c.Token = token
+	c.Cluster = cluster
+
+	return &remoteSource{c}
+}
+
+func (r *remoteSource) GetServiceStatus(serviceName string) (service.ServiceStatus, error) {
+	return r.client.ServiceStatus(context.Background(), serviceName)
+}
+
+func (r *remoteSource) GetDeploymentStatus(serviceName string, image string) (service.ServiceStatus, error) {
+	return r.client.DeploymentStatus(context.Background(), serviceName, image)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommandsTestSuite struct {
+	suite.Suite
+	status service.ServiceStatus
+	server *httptest.Server
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+}
+
+func TestCommandsTestSuite(t *testing.T) {
+	suite.Run(t, new(CommandsTestSuite))
+}
+
+func (s *CommandsTestSuite) SetupTest() {
+	replicas := uint64(1)
+	s.status = service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "docker-routing-mesh", Replicas: &replicas, RunningReplicas: 1}
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "Invalid credentials."}`))
+			return
+		}
+
+		js, _ := json.Marshal(s.status)
+		w.Write(js)
+	}))
+
+	s.stdout = new(bytes.Buffer)
+	s.stderr = new(bytes.Buffer)
+}
+
+func (s *CommandsTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *CommandsTestSuite) Test_DeployStatus_ReturnSuccess() {
+	code := run([]string{"deploy-status", "--server", s.server.URL, "--token", "s3cr3t", "docker-routing-mesh", "albertogviana/docker-routing-mesh:1.0.0"}, s.stdout, s.stderr)
+
+	s.Equal(ExitSuccess, code)
+	s.Contains(s.stdout.String(), "VERDICT   succeeded")
+	s.Contains(s.stdout.String(), "REPLICAS  1/1 running, 0 failed")
+}
+
+func (s *CommandsTestSuite) Test_DeployStatus_ReturnRollback() {
+	s.status.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStateRollbackCompleted, Message: "rollback completed"}
+
+	code := run([]string{"deploy-status", "--server", s.server.URL, "--token", "s3cr3t", "--output", "json", "docker-routing-mesh", "albertogviana/docker-routing-mesh:1.0.0"}, s.stdout, s.stderr)
+
+	s.Equal(ExitRollback, code)
+	s.Contains(s.stdout.String(), `"Verdict":"rolled-back"`)
+}
+
+func (s *CommandsTestSuite) Test_Status_ReturnNotFound() {
+	s.status = service.ServiceStatus{Name: "my-service", Err: "The my-service service was not found in the cluster."}
+
+	code := run([]string{"status", "--server", s.server.URL, "--token", "s3cr3t", "my-service"}, s.stdout, s.stderr)
+
+	s.Equal(ExitNotFound, code)
+}
+
+func (s *CommandsTestSuite) Test_Wait_ReturnTimeout() {
+	s.status.RunningReplicas = 0
+
+	code := run([]string{"wait", "--server", s.server.URL, "--token", "s3cr3t", "--timeout", "20ms", "--interval", "10ms", "docker-routing-mesh", "albertogviana/docker-routing-mesh:1.0.0"}, s.stdout, s.stderr)
+
+	s.Equal(ExitTimeout, code)
+	s.Contains(s.stderr.String(), "Timed out after 20ms")
+}
+
+func (s *CommandsTestSuite) Test_Status_ReturnError_Unauthorized() {
+	code := run([]string{"status", "--server", s.server.URL, "docker-routing-mesh"}, s.stdout, s.stderr)
+
+	s.Equal(ExitError, code)
+	s.Contains(s.stderr.String(), "Invalid credentials.")
+}
+
+func (s *CommandsTestSuite) Test_Run_ReturnError_UnknownCommand() {
+	code := run([]string{"deploy"}, s.stdout, s.stderr)
+
+	s.Equal(ExitError, code)
+	s.Contains(s.stderr.String(), `Unknown command "deploy"`)
+}
@@ -1,23 +1,54 @@
 package main
 
 import (
+	"fmt"
+	"io"
 	"os"
-
-	"github.com/albertogviana/docker-swarm-service-status/server"
-	"github.com/albertogviana/docker-swarm-service-status/service"
 )
 
+const usage = `Usage: docker-swarm-service-status [command] [flags]
+
+Commands:
+  serve                            Run the HTTP server (default)
+  status <service>                 Print the current state of a service
+  deploy-status <service> <image>  Verify that an image was deployed to a service
+  wait <service> <image>           Wait until the deployment of an image succeeds, fails or rolls back
+
+The client commands talk to the Docker daemon configured by the DOCKER_* variables, or to a running
+server when --server or SERVICE_STATUS_URL is set. Run "<command> -h" for the command flags.
+
+Exit codes:
+  0  the service is running as expected
+  1  the deployment failed or is not complete
+  2  the deployment was rolled back
+  3  wait timed out
+  4  the service was not found
+  5  the command could not be executed
+`
+
 func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
 
-	dockerHost := "unix:///var/run/docker.sock"
-	if os.Getenv("DOCKER_HOST") != "" {
-		dockerHost = os.Getenv("DOCKER_HOST")
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		return serve(args)
 	}
 
-	dockerAPIVersion := "v1.33"
-	defaultHeaders := map[string]string{"User-Agent": "docker-swarm-service-status-cli-1.0"}
+	switch args[0] {
+	case "serve":
+		return serve(args[1:])
+	case "status":
+		return statusCommand(args[1:], stdout, stderr)
+	case "deploy-status":
+		return deployStatusCommand(args[1:], stdout, stderr)
+	case "wait":
+		return waitCommand(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return ExitSuccess
+	}
 
-	service := service.NewService(dockerHost, dockerAPIVersion, defaultHeaders)
-	server := server.NewServer(service)
-	server.Run()
+	fmt.Fprintf(stderr, "Unknown command %q\n\n%s", args[0], usage)
+	return ExitError
 }
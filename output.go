@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/albertogviana/docker-swarm-service-status/report"
+	"github.com/albertogviana/docker-swarm-service-status/service"
+)
+
+// printStatus writes the status as a human-readable table, as JSON or as a JUnit XML report
+func printStatus(w io.Writer, status service.ServiceStatus, output string) error {
+	if output == "junit" {
+		return report.WriteJUnit(w, status)
+	}
+
+	if output == "json" {
+		js, err := json.Marshal(struct {
+			service.ServiceStatus
+			Verdict service.Verdict
+		}{status, status.Verdict()})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(js))
+		return err
+	}
+
+	return report.WriteTable(w, status)
+}
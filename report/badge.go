@@ -0,0 +1,119 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+)
+
+// BadgeContentType is the media type of status badges
+const BadgeContentType = "image/svg+xml"
+
+// BadgeColours maps each verdict to the colour of its badge
+var BadgeColours = map[service.Verdict]string{
+	service.VerdictSucceeded:  "#4c1",
+	service.VerdictInProgress: "#007ec6",
+	service.VerdictUnknown:    "#9f9f9f",
+	service.VerdictNotFound:   "#9f9f9f",
+	service.VerdictFailed:     "#e05d44",
+	service.VerdictRolledBack: "#fe7d37",
+}
+
+// Badge is a two-part status badge, e.g. "prod_web | 3/3 running"
+type Badge struct {
+	Label   string
+	Message string
+	Colour  string
+}
+
+// ServiceBadge returns the badge of a service, its message is the number of running replicas once the deployment
+// succeeded and the verdict otherwise
+func ServiceBadge(status service.ServiceStatus) Badge {
+	verdict := status.Verdict()
+	badge := Badge{Label: status.Name, Message: badgeMessage(verdict), Colour: BadgeColours[verdict]}
+
+	switch verdict {
+	case service.VerdictSucceeded:
+		badge.Message = fmt.Sprintf("%d running", status.RunningReplicas)
+		if status.Replicas != nil {
+			badge.Message = fmt.Sprintf("%d/%d running", status.RunningReplicas, *status.Replicas)
+		}
+	case service.VerdictInProgress:
+		if status.Replicas != nil {
+			badge.Message = fmt.Sprintf("deploying %d/%d", status.RunningReplicas, *status.Replicas)
+		}
+	}
+
+	return badge
+}
+
+// StackBadge returns the badge of a stack, its message counts the running replicas of every service once the
+// deployment succeeded and is the worst verdict otherwise
+func StackBadge(status service.StackStatus) Badge {
+	verdict := status.Verdict()
+	badge := Badge{Label: status.Name, Message: badgeMessage(verdict), Colour: BadgeColours[verdict]}
+
+	if verdict == service.VerdictSucceeded {
+		running, desired := 0, uint64(0)
+		for _, serviceStatus := range status.Services {
+			running += serviceStatus.RunningReplicas
+			if serviceStatus.Replicas != nil {
+				desired += *serviceStatus.Replicas
+			} else {
+				desired += uint64(serviceStatus.RunningReplicas)
+			}
+		}
+		badge.Message = fmt.Sprintf("%d/%d running", running, desired)
+	}
+
+	return badge
+}
+
+func badgeMessage(verdict service.Verdict) string {
+	switch verdict {
+	case service.VerdictInProgress:
+		return "deploying"
+	case service.VerdictNotFound:
+		return "not found"
+	case service.VerdictRolledBack:
+		return "rolled back"
+	}
+
+	return string(verdict)
+}
+
+// WriteBadge writes the badge as a flat SVG image
+func WriteBadge(w io.Writer, badge Badge) error {
+	labelWidth := textWidth(badge.Label)
+	messageWidth := textWidth(badge.Message)
+	width := labelWidth + messageWidth
+
+	label := html.EscapeString(badge.Label)
+	message := html.EscapeString(badge.Message)
+
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`+
+		`<title>%s: %s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`+
+		`<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`+
+		`</g></svg>`,
+		width, label, message,
+		label, message,
+		width,
+		labelWidth, labelWidth, messageWidth, badge.Colour, width,
+		labelWidth/2, label, labelWidth/2, label,
+		labelWidth+messageWidth/2, message, labelWidth+messageWidth/2, message,
+	)
+
+	return err
+}
+
+// textWidth approximates the width in pixels of the text in 11px Verdana, plus the padding
+func textWidth(text string) int {
+	return len([]rune(text))*7 + 10
+}
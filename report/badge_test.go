@@ -0,0 +1,48 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/suite"
+)
+
+type BadgeTestSuite struct {
+	suite.Suite
+}
+
+func TestBadgeTestSuite(t *testing.T) {
+	suite.Run(t, new(BadgeTestSuite))
+}
+
+func (s *BadgeTestSuite) Test_ServiceBadge() {
+	replicas := uint64(3)
+
+	s.Equal(Badge{"prod_web", "3/3 running", "#4c1"}, ServiceBadge(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web", Replicas: &replicas, RunningReplicas: 3}))
+	s.Equal(Badge{"prod_web", "deploying 1/3", "#007ec6"}, ServiceBadge(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web", Replicas: &replicas, RunningReplicas: 1}))
+	s.Equal(Badge{"prod_web", "rolled back", "#fe7d37"}, ServiceBadge(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web", UpdateStatus: &swarm.UpdateStatus{State: swarm.UpdateStateRollbackCompleted}}))
+	s.Equal(Badge{"prod_web", "not found", "#9f9f9f"}, ServiceBadge(service.ServiceStatus{Name: "prod_web"}))
+}
+
+func (s *BadgeTestSuite) Test_StackBadge() {
+	two, one := uint64(2), uint64(1)
+	web := service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web", Replicas: &two, RunningReplicas: 2}
+	api := service.ServiceStatus{ID: "evv1jw9o7981mrp0p50j1gy5k", Name: "prod_api", Replicas: &one, RunningReplicas: 1}
+	failed := service.ServiceStatus{ID: "ka8a7cwzf0pq4opcscd1yf7rn", Name: "prod_worker", Err: "The image was not deployed."}
+
+	s.Equal(Badge{"prod", "3/3 running", "#4c1"}, StackBadge(service.StackStatus{Name: "prod", Services: []service.ServiceStatus{web, api}}))
+	s.Equal(Badge{"prod", "failed", "#e05d44"}, StackBadge(service.StackStatus{Name: "prod", Services: []service.ServiceStatus{web, failed}}))
+}
+
+func (s *BadgeTestSuite) Test_WriteBadge_ReturnValidSVG() {
+	buffer := &bytes.Buffer{}
+
+	s.NoError(WriteBadge(buffer, Badge{"deploy <prod>", "rolled back", "#fe7d37"}))
+
+	s.NoError(xml.Unmarshal(buffer.Bytes(), new(interface{})))
+	s.Contains(buffer.String(), `aria-label="deploy &lt;prod&gt;: rolled back"`)
+	s.Contains(buffer.String(), `fill="#fe7d37"`)
+}
@@ -0,0 +1,159 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// JUnitContentType is the media type of JUnit XML reports
+const JUnitContentType = "application/junit+xml"
+
+// TestSuites is the root element of a JUnit XML report
+type TestSuites struct {
+	XMLName    xml.Name    `xml:"testsuites"`
+	Name       string      `xml:"name,attr"`
+	Tests      int         `xml:"tests,attr"`
+	Failures   int         `xml:"failures,attr"`
+	TestSuites []TestSuite `xml:"testsuite"`
+}
+
+// TestSuite groups the test cases of a service
+type TestSuite struct {
+	Name      string     `xml:"name,attr"`
+	Tests     int        `xml:"tests,attr"`
+	Failures  int        `xml:"failures,attr"`
+	Timestamp string     `xml:"timestamp,attr,omitempty"`
+	TestCases []TestCase `xml:"testcase"`
+}
+
+// TestCase is a single check of the deployment
+type TestCase struct {
+	ClassName string   `xml:"classname,attr"`
+	Name      string   `xml:"name,attr"`
+	Failure   *Failure `xml:"failure,omitempty"`
+	SystemOut string   `xml:"system-out,omitempty"`
+}
+
+// Failure describes why a test case failed
+type Failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+// JUnit converts the deployment status of each service into a JUnit report. Every service becomes a test suite
+// with a "deployment" test case carrying the verdict and one test case per task.
+func JUnit(statuses ...service.ServiceStatus) TestSuites {
+	report := TestSuites{Name: "docker-swarm-service-status"}
+
+	for _, status := range statuses {
+		suite := junitSuite(status)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.TestSuites = append(report.TestSuites, suite)
+	}
+
+	return report
+}
+
+// WriteJUnit writes the JUnit report of the given statuses as XML
+func WriteJUnit(w io.Writer, statuses ...service.ServiceStatus) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(JUnit(statuses...)); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSuite(status service.ServiceStatus) TestSuite {
+	verdict := status.Verdict()
+	suite := TestSuite{Name: status.Name, Timestamp: time.Now().UTC().Format(time.RFC3339)}
+
+	deployment := TestCase{ClassName: status.Name, Name: "deployment"}
+	if verdict != service.VerdictSucceeded {
+		deployment.Failure = &Failure{
+			Message: deploymentMessage(status),
+			Type:    string(verdict),
+			Details: deploymentDetails(status),
+		}
+	}
+	suite.TestCases = append(suite.TestCases, deployment)
+
+	for _, task := range status.TaskStatus {
+		testCase := TestCase{ClassName: status.Name, Name: fmt.Sprintf("task %s", task.TaskID)}
+
+		if task.State == swarm.TaskStateFailed || task.State == swarm.TaskStateRejected {
+			details := fmt.Sprintf("State: %s\nDesired state: %s\nImage: %s\nMessage: %s\nError: %s\nTimestamp: %s", task.State, task.DesiredState, task.Image, task.Message, task.Err, task.Timestamp.Format(time.RFC3339))
+
+			// Docker replaces failed tasks, so a failure is only reported when the deployment did not recover
+			if verdict == service.VerdictSucceeded {
+				testCase.SystemOut = details
+			} else {
+				testCase.Failure = &Failure{Message: taskMessage(task), Type: string(task.State), Details: details}
+			}
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	suite.Tests = len(suite.TestCases)
+	for _, testCase := range suite.TestCases {
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+	}
+
+	return suite
+}
+
+func deploymentMessage(status service.ServiceStatus) string {
+	if status.Err != "" {
+		return status.Err
+	}
+
+	if status.UpdateStatus != nil && status.UpdateStatus.Message != "" {
+		return status.UpdateStatus.Message
+	}
+
+	return fmt.Sprintf("The deployment of the %s service is %s.", status.Name, status.Verdict())
+}
+
+func deploymentDetails(status service.ServiceStatus) string {
+	lines := []string{fmt.Sprintf("Verdict: %s", status.Verdict())}
+
+	if status.Replicas != nil {
+		lines = append(lines, fmt.Sprintf("Replicas: %d/%d running, %d failed", status.RunningReplicas, *status.Replicas, status.FailedReplicas))
+	}
+
+	if status.UpdateStatus != nil {
+		lines = append(lines, fmt.Sprintf("Update state: %s", status.UpdateStatus.State), fmt.Sprintf("Update message: %s", status.UpdateStatus.Message))
+	}
+
+	if status.Err != "" {
+		lines = append(lines, fmt.Sprintf("Error: %s", status.Err))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func taskMessage(task service.TaskStatus) string {
+	if task.Err != "" {
+		return task.Err
+	}
+
+	return task.Message
+}
@@ -0,0 +1,74 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/suite"
+)
+
+type JUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestJUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(JUnitTestSuite))
+}
+
+func (s *JUnitTestSuite) Test_JUnit_Succeeded() {
+	replicas := uint64(1)
+	status := service.ServiceStatus{
+		ID:              "tt3otdsnkd1kgh80u45bwmcb4",
+		Name:            "docker-routing-mesh",
+		Replicas:        &replicas,
+		RunningReplicas: 1,
+		TaskStatus: []service.TaskStatus{
+			{TaskID: "evv1jw9o7981mrp0p50j1gy5k", State: swarm.TaskStateRunning, DesiredState: swarm.TaskStateRunning},
+			{TaskID: "ka8a7cwzf0pq4opcscd1yf7rn", State: swarm.TaskStateFailed, DesiredState: swarm.TaskStateShutdown, Err: "task: non-zero exit (1)"},
+		},
+	}
+
+	report := JUnit(status)
+
+	s.Equal(3, report.Tests)
+	s.Equal(0, report.Failures)
+	s.Equal("docker-routing-mesh", report.TestSuites[0].Name)
+	s.Equal("deployment", report.TestSuites[0].TestCases[0].Name)
+	s.Contains(report.TestSuites[0].TestCases[2].SystemOut, "task: non-zero exit (1)")
+}
+
+func (s *JUnitTestSuite) Test_JUnit_RolledBack() {
+	status := service.ServiceStatus{
+		ID:           "tt3otdsnkd1kgh80u45bwmcb4",
+		Name:         "docker-routing-mesh",
+		UpdateStatus: &swarm.UpdateStatus{State: swarm.UpdateStateRollbackCompleted, Message: "rollback completed"},
+		TaskStatus: []service.TaskStatus{
+			{TaskID: "ka8a7cwzf0pq4opcscd1yf7rn", State: swarm.TaskStateRejected, DesiredState: swarm.TaskStateShutdown, Err: "No such image: albertogviana/docker-routing-mesh:error", Timestamp: time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC)},
+		},
+	}
+
+	report := JUnit(status)
+
+	s.Equal(2, report.Tests)
+	s.Equal(2, report.Failures)
+	s.Equal(&Failure{"rollback completed", "rolled-back", "Verdict: rolled-back\nUpdate state: rollback_completed\nUpdate message: rollback completed"}, report.TestSuites[0].TestCases[0].Failure)
+	s.Equal("No such image: albertogviana/docker-routing-mesh:error", report.TestSuites[0].TestCases[1].Failure.Message)
+	s.Equal("rejected", report.TestSuites[0].TestCases[1].Failure.Type)
+}
+
+func (s *JUnitTestSuite) Test_WriteJUnit_ReturnValidXML() {
+	buffer := new(bytes.Buffer)
+
+	err := WriteJUnit(buffer, service.ServiceStatus{Name: "my-service", Err: "The my-service service was not found in the cluster."})
+
+	s.NoError(err)
+
+	report := TestSuites{}
+	s.NoError(xml.Unmarshal(buffer.Bytes(), &report))
+	s.Equal(1, report.Failures)
+	s.Equal("The my-service service was not found in the cluster.", report.TestSuites[0].TestCases[0].Failure.Message)
+}
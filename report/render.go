@@ -0,0 +1,127 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Format is a representation the API and the command line can render a response in
+type Format string
+
+const (
+	// FormatJSON renders the response as JSON
+	FormatJSON Format = "json"
+	// FormatYAML renders the response as YAML
+	FormatYAML Format = "yaml"
+	// FormatTable renders the response as a plain-text table
+	FormatTable Format = "table"
+	// FormatJUnit renders service and stack statuses as a JUnit XML report
+	FormatJUnit Format = "junit"
+)
+
+// Formats lists every supported format
+var Formats = []Format{FormatJSON, FormatYAML, FormatTable, FormatJUnit}
+
+// ErrUnsupported is returned when a value cannot be rendered in the requested format
+type ErrUnsupported struct {
+	Format Format
+	Value  interface{}
+}
+
+func (e *ErrUnsupported) Error() string {
+	return fmt.Sprintf("The %s format is not supported for this resource.", e.Format)
+}
+
+// ParseFormat returns the format with the given name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("The %s format is not supported, use one of json, yaml, table or junit.", name)
+}
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatYAML:
+		return "application/yaml"
+	case FormatTable:
+		return "text/plain; charset=utf-8"
+	case FormatJUnit:
+		return JUnitContentType
+	}
+
+	return "application/json"
+}
+
+// Supports reports whether the value can be rendered in the format
+func (f Format) Supports(v interface{}) bool {
+	switch f {
+	case FormatJUnit:
+		switch v.(type) {
+		case service.ServiceStatus, service.StackStatus:
+			return true
+		}
+		return false
+	case FormatTable:
+		return tableWriter(v) != nil
+	}
+
+	return true
+}
+
+// Render writes the value in the given format
+func Render(w io.Writer, format Format, v interface{}) error {
+	if !format.Supports(v) {
+		return &ErrUnsupported{format, v}
+	}
+
+	switch format {
+	case FormatYAML:
+		return renderYAML(w, v)
+	case FormatTable:
+		return WriteTable(w, v)
+	case FormatJUnit:
+		if stack, ok := v.(service.StackStatus); ok {
+			return WriteJUnit(w, stack.Services...)
+		}
+		return WriteJUnit(w, v.(service.ServiceStatus))
+	}
+
+	js, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(js)
+	return err
+}
+
+// renderYAML goes through JSON first so the YAML keys match the JSON field names
+func renderYAML(w io.Writer, v interface{}) error {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(js, &generic); err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(generic); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
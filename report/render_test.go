@@ -0,0 +1,99 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/suite"
+)
+
+type RenderTestSuite struct {
+	suite.Suite
+}
+
+func TestRenderTestSuite(t *testing.T) {
+	suite.Run(t, new(RenderTestSuite))
+}
+
+func (s *RenderTestSuite) status() service.ServiceStatus {
+	replicas := uint64(2)
+	return service.ServiceStatus{
+		ID:              "tt3otdsnkd1kgh80u45bwmcb4",
+		Name:            "docker-routing-mesh",
+		Replicas:        &replicas,
+		RunningReplicas: 2,
+		TaskStatus: []service.TaskStatus{
+			{TaskID: "evv1jw9o7981mrp0p50j1gy5k", Slot: 1, NodeID: "x8mjy3ys2ntcq3ypbhvbs6jyf", State: swarm.TaskStateRunning, Image: "albertogviana/docker-routing-mesh:1.0.0"},
+			{TaskID: "ka8a7cwzf0pq4opcscd1yf7rn", Slot: 2, NodeID: "x8mjy3ys2ntcq3ypbhvbs6jyf", State: swarm.TaskStateFailed, Image: "albertogviana/docker-routing-mesh:1.0.0", Err: "task: non-zero exit (1)"},
+		},
+	}
+}
+
+func (s *RenderTestSuite) Test_ParseFormat() {
+	format, err := ParseFormat("YAML")
+	s.NoError(err)
+	s.Equal(FormatYAML, format)
+
+	_, err = ParseFormat("csv")
+	s.EqualError(err, "The csv format is not supported, use one of json, yaml, table or junit.")
+}
+
+func (s *RenderTestSuite) Test_Render_JSON() {
+	buffer := &bytes.Buffer{}
+
+	s.NoError(Render(buffer, FormatJSON, s.status()))
+	s.Contains(buffer.String(), `"Name":"docker-routing-mesh"`)
+	s.Equal("application/json", FormatJSON.ContentType())
+}
+
+func (s *RenderTestSuite) Test_Render_YAML() {
+	buffer := &bytes.Buffer{}
+
+	s.NoError(Render(buffer, FormatYAML, s.status()))
+	s.Contains(buffer.String(), "Name: docker-routing-mesh\n")
+	s.Contains(buffer.String(), "    TaskID: evv1jw9o7981mrp0p50j1gy5k\n")
+}
+
+func (s *RenderTestSuite) Test_Render_Table() {
+	buffer := &bytes.Buffer{}
+
+	s.NoError(Render(buffer, FormatTable, s.status()))
+	s.Contains(buffer.String(), "VERDICT   succeeded\n")
+	s.Contains(buffer.String(), "REPLICAS  2/2 running, 0 failed\n")
+	s.Contains(buffer.String(), "ID                         SLOT  NODE                       STATE    IMAGE                                    ERROR\n")
+	s.Contains(buffer.String(), "ka8a7cwzf0pq4opcscd1yf7rn  2     x8mjy3ys2ntcq3ypbhvbs6jyf  failed   albertogviana/docker-routing-mesh:1.0.0  task: non-zero exit (1)\n")
+}
+
+func (s *RenderTestSuite) Test_Render_StackTable() {
+	buffer := &bytes.Buffer{}
+
+	s.NoError(Render(buffer, FormatTable, service.StackStatus{Name: "prod", Services: []service.ServiceStatus{s.status()}}))
+	s.Contains(buffer.String(), "STACK    prod\n")
+	s.Contains(buffer.String(), "docker-routing-mesh  succeeded  2/2 running, 0 failed  -\n")
+}
+
+func (s *RenderTestSuite) Test_Render_BatchTable() {
+	buffer := &bytes.Buffer{}
+	status := s.status()
+
+	s.NoError(Render(buffer, FormatTable, service.BatchStatus{
+		Verdict: service.VerdictFailed,
+		Deployments: []service.DeploymentResult{
+			{Service: "docker-routing-mesh", Image: "albertogviana/docker-routing-mesh:1.0.0", Verdict: service.VerdictSucceeded, Status: &status},
+			{Service: "prod_web", Image: "prod/web:1.1.0", Verdict: service.VerdictUnknown, Err: "Cannot connect to the Docker daemon"},
+		},
+	}))
+	s.Contains(buffer.String(), "VERDICT  failed\n")
+	s.Contains(buffer.String(), "docker-routing-mesh  albertogviana/docker-routing-mesh:1.0.0  succeeded  2/2 running, 0 failed  -\n")
+	s.Contains(buffer.String(), "prod_web             prod/web:1.1.0                           unknown    -                      Cannot connect to the Docker daemon\n")
+}
+
+func (s *RenderTestSuite) Test_Render_Unsupported() {
+	err := Render(&bytes.Buffer{}, FormatJUnit, service.Info{})
+
+	s.IsType(&ErrUnsupported{}, err)
+	s.False(FormatJUnit.Supports(service.Info{}))
+	s.False(FormatTable.Supports(struct{}{}))
+}
@@ -0,0 +1,181 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+)
+
+// Tabular is implemented by values that know how to describe themselves as table rows
+type Tabular interface {
+	Rows() [][]string
+}
+
+// WriteTable writes the value as a plain-text table. Service statuses are rendered as a summary followed by
+// one row per task with its ID, slot, node, state, image and error.
+func WriteTable(w io.Writer, v interface{}) error {
+	write := tableWriter(v)
+	if write == nil {
+		return &ErrUnsupported{FormatTable, v}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	write(tw)
+
+	return tw.Flush()
+}
+
+func tableWriter(v interface{}) func(io.Writer) {
+	switch value := v.(type) {
+	case service.ServiceStatus:
+		return func(w io.Writer) { serviceTable(w, value) }
+	case service.StackStatus:
+		return func(w io.Writer) { stackTable(w, value) }
+	case []service.ClusterStatus:
+		return func(w io.Writer) { clusterTable(w, value) }
+	case service.BatchStatus:
+		return func(w io.Writer) { batchTable(w, value) }
+	case service.Info:
+		return func(w io.Writer) { infoTable(w, value) }
+	case service.Readiness:
+		return func(w io.Writer) { readinessTable(w, value) }
+	case []string:
+		return func(w io.Writer) {
+			for _, line := range value {
+				fmt.Fprintln(w, line)
+			}
+		}
+	case Tabular:
+		return func(w io.Writer) { rows(w, value.Rows()) }
+	}
+
+	return nil
+}
+
+func serviceTable(w io.Writer, status service.ServiceStatus) {
+	fmt.Fprintf(w, "SERVICE\t%s\n", status.Name)
+	if status.ID != "" {
+		fmt.Fprintf(w, "ID\t%s\n", status.ID)
+	}
+	fmt.Fprintf(w, "VERDICT\t%s\n", status.Verdict())
+	if status.ID != "" {
+		fmt.Fprintf(w, "REPLICAS\t%s\n", Replicas(status))
+	}
+	if status.UpdateStatus != nil {
+		fmt.Fprintf(w, "UPDATE\t%s %s\n", status.UpdateStatus.State, status.UpdateStatus.Message)
+	}
+	if status.Err != "" {
+		fmt.Fprintf(w, "ERROR\t%s\n", status.Err)
+	}
+
+	if len(status.TaskStatus) > 0 {
+		fmt.Fprintf(w, "\nID\tSLOT\tNODE\tSTATE\tIMAGE\tERROR\n")
+		for _, task := range status.TaskStatus {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", task.TaskID, slot(task), dash(task.NodeID), task.State, task.Image, dash(task.Err))
+		}
+	}
+}
+
+func stackTable(w io.Writer, status service.StackStatus) {
+	fmt.Fprintf(w, "STACK\t%s\n", status.Name)
+	fmt.Fprintf(w, "VERDICT\t%s\n", status.Verdict())
+	if status.Err != "" {
+		fmt.Fprintf(w, "ERROR\t%s\n", status.Err)
+	}
+
+	if len(status.Services) > 0 {
+		fmt.Fprintf(w, "\nSERVICE\tVERDICT\tREPLICAS\tERROR\n")
+		for _, serviceStatus := range status.Services {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", serviceStatus.Name, serviceStatus.Verdict(), Replicas(serviceStatus), dash(serviceStatus.Err))
+		}
+	}
+}
+
+func clusterTable(w io.Writer, statuses []service.ClusterStatus) {
+	fmt.Fprintf(w, "CLUSTER\tVERDICT\tREPLICAS\tERROR\n")
+	for _, status := range statuses {
+		if status.Status == nil {
+			fmt.Fprintf(w, "%s\t-\t-\t%s\n", status.Cluster, dash(status.Err))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status.Cluster, status.Status.Verdict(), Replicas(*status.Status), dash(status.Status.Err))
+	}
+}
+
+func batchTable(w io.Writer, status service.BatchStatus) {
+	fmt.Fprintf(w, "VERDICT\t%s\n", status.Verdict)
+
+	if len(status.Deployments) > 0 {
+		fmt.Fprintf(w, "\nSERVICE\tIMAGE\tVERDICT\tREPLICAS\tERROR\n")
+		for _, deployment := range status.Deployments {
+			if deployment.Status == nil {
+				fmt.Fprintf(w, "%s\t%s\t%s\t-\t%s\n", deployment.Service, deployment.Image, deployment.Verdict, dash(deployment.Err))
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", deployment.Service, deployment.Image, deployment.Verdict, Replicas(*deployment.Status), dash(deployment.Status.Err))
+		}
+	}
+}
+
+func infoTable(w io.Writer, info service.Info) {
+	fmt.Fprintf(w, "API VERSION\t%s\n", info.APIVersion)
+	fmt.Fprintf(w, "SERVER API VERSION\t%s\n", info.ServerAPIVersion)
+	fmt.Fprintf(w, "SERVER MIN API VERSION\t%s\n", info.ServerMinAPIVersion)
+	fmt.Fprintf(w, "SERVER VERSION\t%s\n", info.ServerVersion)
+
+	if len(info.Features) > 0 {
+		fmt.Fprintf(w, "\nFEATURE\tMIN API VERSION\tSUPPORTED\n")
+		for _, feature := range info.Features {
+			fmt.Fprintf(w, "%s\t%s\t%t\n", feature.Name, feature.MinAPIVersion, feature.Supported)
+		}
+	}
+}
+
+func readinessTable(w io.Writer, readiness service.Readiness) {
+	fmt.Fprintf(w, "READY\t%t\n", readiness.Ready)
+	if readiness.APIVersion != "" {
+		fmt.Fprintf(w, "API VERSION\t%s\n", readiness.APIVersion)
+	}
+
+	if len(readiness.Checks) > 0 {
+		fmt.Fprintf(w, "\nCHECK\tOK\tMESSAGE\n")
+		for _, check := range readiness.Checks {
+			fmt.Fprintf(w, "%s\t%t\t%s\n", check.Name, check.OK, dash(check.Message))
+		}
+	}
+}
+
+func rows(w io.Writer, rows [][]string) {
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+}
+
+// Replicas describes how many replicas of the service are running and failed
+func Replicas(status service.ServiceStatus) string {
+	if status.Replicas == nil {
+		return fmt.Sprintf("%d running, %d failed", status.RunningReplicas, status.FailedReplicas)
+	}
+
+	return fmt.Sprintf("%d/%d running, %d failed", status.RunningReplicas, *status.Replicas, status.FailedReplicas)
+}
+
+func slot(task service.TaskStatus) string {
+	// global services have no slots
+	if task.Slot == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d", task.Slot)
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/audit"
+	"github.com/albertogviana/docker-swarm-service-status/server"
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/albertogviana/docker-swarm-service-status/webhook"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// serve runs the HTTP server configured from the environment until it receives SIGINT or SIGTERM
+func serve(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "serve takes no arguments, it is configured through environment variables\n")
+		return ExitError
+	}
+
+	config, err := serverConfig()
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+
+	authenticator, err := authenticator()
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+
+	defaultService, clusters, err := clusters()
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	shutdownTracing, err := tracing(ctx)
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+	defer shutdownTracing()
+
+	dispatcher, err := watchDeployments(ctx, clusters)
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+
+	server := server.NewServer(defaultService)
+	server.Clusters = clusters
+	server.Authenticator = authenticator
+	server.Webhooks = dispatcher
+	server.Cache, err = cache()
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+	server.RateLimiter, err = rateLimiter()
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+	server.AccessLog, err = accessLog()
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+	server.CORS, err = cors()
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+	server.PublicBadges, err = boolFromEnv("SERVICE_STATUS_PUBLIC_BADGES")
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+	server.Audit, err = auditLog()
+	if err != nil {
+		log.Println(err)
+		return ExitError
+	}
+	if server.Audit != nil {
+		defer server.Audit.Close()
+	}
+	if err := server.Run(config); err != nil {
+		log.Println(err)
+		return ExitError
+	}
+
+	return ExitSuccess
+}
+
+// clusters connects to the Docker daemon described by the DOCKER_* variables and to every cluster of
+// SERVICE_STATUS_CLUSTERS_FILE. The former is the default cluster, registered as SERVICE_STATUS_CLUSTER_NAME.
+func clusters() (service.Services, map[string]service.Services, error) {
+	defaultName := service.DefaultCluster
+	if os.Getenv("SERVICE_STATUS_CLUSTER_NAME") != "" {
+		defaultName = os.Getenv("SERVICE_STATUS_CLUSTER_NAME")
+	}
+
+	configs := map[string]service.Config{}
+	if os.Getenv("SERVICE_STATUS_CLUSTERS_FILE") != "" {
+		loaded, err := service.LoadClusterConfigs(os.Getenv("SERVICE_STATUS_CLUSTERS_FILE"))
+		if err != nil {
+			return nil, nil, err
+		}
+		configs = loaded
+	}
+
+	if _, ok := configs[defaultName]; !ok {
+		configs[defaultName] = service.ConfigFromEnv()
+	}
+
+	limiter, err := dockerLimiter()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clusters := map[string]service.Services{}
+	for name, config := range configs {
+		config.DefaultHeaders = map[string]string{"User-Agent": "docker-swarm-service-status-cli-1.0"}
+
+		svc, err := service.NewServiceWithConfig(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s cluster: %s", name, err.Error())
+		}
+		svc.Limiter = limiter
+
+		if info, err := svc.Negotiate(); err != nil {
+			log.Printf("Unable to negotiate the Docker API version of the %s cluster, retrying on the first request: %s", name, err.Error())
+		} else {
+			log.Printf("Using Docker API version %s (daemon %s) for the %s cluster", info.APIVersion, info.ServerVersion, name)
+		}
+
+		clusters[name] = svc
+	}
+
+	return clusters[defaultName], clusters, nil
+}
+
+// watchDeployments starts a watcher per cluster notifying the webhooks of SERVICE_STATUS_WEBHOOKS_FILE and, when
+// SERVICE_STATUS_SMTP_ADDRESS is set, the email recipients until ctx is done. It returns the webhook dispatcher,
+// nil when no webhook is configured.
+func watchDeployments(ctx context.Context, clusters map[string]service.Services) (*webhook.Dispatcher, error) {
+	notifiers := webhook.Notifiers{}
+
+	var dispatcher *webhook.Dispatcher
+	if os.Getenv("SERVICE_STATUS_WEBHOOKS_FILE") != "" {
+		hooks, err := webhook.LoadWebhooks(os.Getenv("SERVICE_STATUS_WEBHOOKS_FILE"))
+		if err != nil {
+			return nil, err
+		}
+
+		dispatcher = webhook.NewDispatcher(hooks)
+		notifiers = append(notifiers, dispatcher)
+	}
+
+	if os.Getenv("SERVICE_STATUS_SMTP_ADDRESS") != "" {
+		notifiers = append(notifiers, webhook.NewEmailNotifier(smtpConfig()))
+	}
+
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+
+	interval := 10 * time.Second
+	if err := durationFromEnv("SERVICE_STATUS_WEBHOOKS_INTERVAL", &interval); err != nil {
+		return nil, err
+	}
+
+	for name, svc := range clusters {
+		watcher := webhook.NewWatcher(name, svc, notifiers)
+		watcher.Interval = interval
+		go watcher.Run(ctx)
+	}
+
+	log.Printf("Watching deployments every %s", interval)
+	return dispatcher, nil
+}
+
+// smtpConfig builds the email configuration from the environment
+func smtpConfig() webhook.SMTPConfig {
+	config := webhook.SMTPConfig{
+		Address:  os.Getenv("SERVICE_STATUS_SMTP_ADDRESS"),
+		Username: os.Getenv("SERVICE_STATUS_SMTP_USERNAME"),
+		Password: os.Getenv("SERVICE_STATUS_SMTP_PASSWORD"),
+		From:     os.Getenv("SERVICE_STATUS_SMTP_FROM"),
+	}
+
+	config.Recipients = append(config.Recipients, listFromEnv("SERVICE_STATUS_SMTP_RECIPIENTS")...)
+
+	return config
+}
+
+// serverConfig builds the server configuration from the environment
+func serverConfig() (server.Config, error) {
+	config := server.DefaultConfig()
+
+	if os.Getenv("SERVICE_STATUS_ADDRESS") != "" {
+		config.Address = os.Getenv("SERVICE_STATUS_ADDRESS")
+	}
+
+	config.GRPCAddress = os.Getenv("SERVICE_STATUS_GRPC_ADDRESS")
+	config.TLSCertFile = os.Getenv("SERVICE_STATUS_TLS_CERT")
+	config.TLSKeyFile = os.Getenv("SERVICE_STATUS_TLS_KEY")
+	config.TLSClientCAFile = os.Getenv("SERVICE_STATUS_TLS_CLIENT_CA")
+
+	durations := map[string]*time.Duration{
+		"SERVICE_STATUS_READ_TIMEOUT":     &config.ReadTimeout,
+		"SERVICE_STATUS_WRITE_TIMEOUT":    &config.WriteTimeout,
+		"SERVICE_STATUS_IDLE_TIMEOUT":     &config.IdleTimeout,
+		"SERVICE_STATUS_SHUTDOWN_TIMEOUT": &config.ShutdownTimeout,
+	}
+
+	for name, value := range durations {
+		if err := durationFromEnv(name, value); err != nil {
+			return config, err
+		}
+	}
+
+	return config, config.Validate()
+}
+
+// cache builds the status cache from SERVICE_STATUS_CACHE_TTL, it returns nil when the TTL is 0
+func cache() (*server.Cache, error) {
+	ttl := server.DefaultCacheTTL
+	if err := durationFromEnv("SERVICE_STATUS_CACHE_TTL", &ttl); err != nil {
+		return nil, err
+	}
+
+	if ttl < 0 {
+		return nil, errors.New("the cache TTL must not be negative")
+	}
+
+	if ttl == 0 {
+		return nil, nil
+	}
+
+	return server.NewCache(ttl), nil
+}
+
+// rateLimiter builds the per client rate limit from SERVICE_STATUS_RATE_LIMIT, in requests per second, and
+// SERVICE_STATUS_RATE_BURST, it returns nil when no rate limit is set
+func rateLimiter() (*server.RateLimiter, error) {
+	if os.Getenv("SERVICE_STATUS_RATE_LIMIT") == "" {
+		return nil, nil
+	}
+
+	limit, err := strconv.ParseFloat(os.Getenv("SERVICE_STATUS_RATE_LIMIT"), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		return nil, errors.New("the rate limit must be positive")
+	}
+
+	burst := server.DefaultRateBurst
+	if os.Getenv("SERVICE_STATUS_RATE_BURST") != "" {
+		burst, err = strconv.Atoi(os.Getenv("SERVICE_STATUS_RATE_BURST"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if burst < 1 {
+		return nil, errors.New("the rate burst must be at least 1")
+	}
+
+	return server.NewRateLimiter(limit, burst), nil
+}
+
+// accessLog returns where the request log is written from SERVICE_STATUS_ACCESS_LOG, stdout by default, stderr,
+// or nil when it is off
+func accessLog() (io.Writer, error) {
+	switch os.Getenv("SERVICE_STATUS_ACCESS_LOG") {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	case "off":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("the access log must be stdout, stderr or off, not %q", os.Getenv("SERVICE_STATUS_ACCESS_LOG"))
+	}
+}
+
+// auditLog opens the audit log named by SERVICE_STATUS_AUDIT_FILE, rotated once it reaches
+// SERVICE_STATUS_AUDIT_MAX_SIZE_MB megabytes keeping SERVICE_STATUS_AUDIT_MAX_BACKUPS files, it returns nil when
+// no file is set
+func auditLog() (*audit.Log, error) {
+	path := os.Getenv("SERVICE_STATUS_AUDIT_FILE")
+	if path == "" {
+		return nil, nil
+	}
+
+	maxSize := audit.DefaultMaxSize >> 20
+	if value := os.Getenv("SERVICE_STATUS_AUDIT_MAX_SIZE_MB"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if size < 1 {
+			return nil, errors.New("the audit log size must be at least 1 MB")
+		}
+		maxSize = size
+	}
+
+	maxBackups := audit.DefaultMaxBackups
+	if value := os.Getenv("SERVICE_STATUS_AUDIT_MAX_BACKUPS"); value != "" {
+		backups, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if backups < 0 {
+			return nil, errors.New("the number of audit log backups must not be negative")
+		}
+		maxBackups = backups
+	}
+
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	auditLog.MaxSize = int64(maxSize) << 20
+	auditLog.MaxBackups = maxBackups
+
+	return auditLog, nil
+}
+
+// cors builds the CORS configuration from SERVICE_STATUS_CORS_ORIGINS, SERVICE_STATUS_CORS_METHODS,
+// SERVICE_STATUS_CORS_HEADERS and SERVICE_STATUS_CORS_MAX_AGE, it returns nil when no origin is allowed
+func cors() (*server.CORS, error) {
+	origins := listFromEnv("SERVICE_STATUS_CORS_ORIGINS")
+	if len(origins) == 0 {
+		return nil, nil
+	}
+
+	cors := server.NewCORS(origins)
+	if methods := listFromEnv("SERVICE_STATUS_CORS_METHODS"); len(methods) > 0 {
+		cors.AllowedMethods = methods
+	}
+	if headers := listFromEnv("SERVICE_STATUS_CORS_HEADERS"); len(headers) > 0 {
+		cors.AllowedHeaders = headers
+	}
+	if err := durationFromEnv("SERVICE_STATUS_CORS_MAX_AGE", &cors.MaxAge); err != nil {
+		return nil, err
+	}
+
+	return cors, nil
+}
+
+// listFromEnv returns the non-empty items of a comma separated environment variable
+func listFromEnv(name string) []string {
+	items := []string{}
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// tracing exports the spans of the requests and of the Docker API calls to the OTLP/HTTP endpoint configured with
+// the standard OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT variables, it does nothing when
+// neither is set. The returned function flushes the pending spans.
+func tracing(ctx context.Context) (func(), error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func() {}, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "docker-swarm-service-status")),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK())
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := provider.Shutdown(ctx); err != nil {
+			log.Println(err)
+		}
+	}, nil
+}
+
+// dockerLimiter builds the cap on concurrent Docker API calls shared by every cluster from
+// SERVICE_STATUS_DOCKER_MAX_CALLS, it returns nil when the cap is 0
+func dockerLimiter() (*service.Limiter, error) {
+	max := service.DefaultMaxConcurrentCalls
+	if os.Getenv("SERVICE_STATUS_DOCKER_MAX_CALLS") != "" {
+		var err error
+		max, err = strconv.Atoi(os.Getenv("SERVICE_STATUS_DOCKER_MAX_CALLS"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if max < 0 {
+		return nil, errors.New("the maximum of concurrent Docker API calls must not be negative")
+	}
+
+	if max == 0 {
+		return nil, nil
+	}
+
+	return service.NewLimiter(max), nil
+}
+
+// authenticator builds the authentication chain from the configured token and HMAC key files,
+// it returns nil when authentication is not configured
+func authenticator() (server.Authenticator, error) {
+	authenticators := server.Authenticators{}
+
+	tokens, err := credentialsFromEnv("SERVICE_STATUS_TOKENS")
+	if err != nil {
+		return nil, err
+	}
+	if tokens != nil {
+		authenticators = append(authenticators, server.NewTokenAuthenticator(tokens))
+	}
+
+	keys, err := credentialsFromEnv("SERVICE_STATUS_HMAC_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	if keys != nil {
+		authenticators = append(authenticators, server.NewHMACAuthenticator(keys))
+	}
+
+	if len(authenticators) == 0 {
+		return nil, nil
+	}
+
+	return authenticators, nil
+}
+
+// credentialsFromEnv loads credentials from the file named by <prefix>_FILE or the Docker secret named by <prefix>_SECRET
+func credentialsFromEnv(prefix string) ([]server.Credential, error) {
+	if os.Getenv(prefix+"_FILE") != "" {
+		return server.LoadCredentials(os.Getenv(prefix + "_FILE"))
+	}
+
+	if os.Getenv(prefix+"_SECRET") != "" {
+		return server.LoadCredentialsFromSecret(os.Getenv(prefix + "_SECRET"))
+	}
+
+	return nil, nil
+}
+
+func boolFromEnv(name string) (bool, error) {
+	if os.Getenv(name) == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(os.Getenv(name))
+}
+
+func durationFromEnv(name string, value *time.Duration) error {
+	if os.Getenv(name) == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(os.Getenv(name))
+	if err != nil {
+		return err
+	}
+
+	*value = d
+	return nil
+}
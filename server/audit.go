@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/audit"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultAuditLimit is how many audit entries are returned when the request does not set a limit
+const DefaultAuditLimit = 100
+
+type auditKey struct{}
+
+// audited records the request in the audit log once it was answered
+func (s *Server) audited(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.Audit == nil {
+			next(w, r)
+			return
+		}
+
+		vars := mux.Vars(r)
+		entry := &audit.Entry{
+			Timestamp: time.Now().UTC(),
+			ClientIP:  clientIP(r.RemoteAddr),
+			RequestID: requestID(r),
+			Method:    r.Method,
+			Cluster:   vars["cluster"],
+			Service:   vars["service"],
+			Stack:     vars["stack"],
+		}
+		if route := mux.CurrentRoute(r); route != nil {
+			entry.Endpoint, _ = route.GetPathTemplate()
+		}
+		if entry.Cluster == "" {
+			entry.Cluster = r.Header.Get(ClusterHeader)
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next(recorder, r.WithContext(context.WithValue(r.Context(), auditKey{}, entry)))
+
+		entry.Status = recorder.status
+		if entry.Status == 0 {
+			entry.Status = http.StatusOK
+		}
+		entry.Outcome = audit.Outcome(entry.Status)
+
+		if err := s.Audit.Record(*entry); err != nil {
+			logError(r, err)
+		}
+	}
+}
+
+// auditIdentity records the authenticated identity in the audit entry of the request
+func auditIdentity(r *http.Request, identity *Identity) {
+	if entry, ok := r.Context().Value(auditKey{}).(*audit.Entry); ok {
+		entry.Identity = identity.Name
+	}
+}
+
+// auditServices records the services queried by a request without a service path segment
+func auditServices(r *http.Request, serviceNames []string) {
+	if entry, ok := r.Context().Value(auditKey{}).(*audit.Entry); ok {
+		entry.Service = strings.Join(serviceNames, ",")
+	}
+}
+
+// auditRPC records a gRPC call in the audit log, req is the request message, which names the service or the stack
+func (s *Server) auditRPC(ctx context.Context, method string, req interface{}, err error) {
+	if s.Audit == nil {
+		return
+	}
+
+	entry := audit.Entry{Timestamp: time.Now().UTC(), Method: "gRPC", Endpoint: method, Code: status.Code(err).String()}
+	if identity, ok := IdentityFromContext(ctx); ok {
+		entry.Identity = identity.Name
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		entry.ClientIP = clientIP(p.Addr.String())
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ClusterHeader); len(values) > 0 {
+			entry.Cluster = values[0]
+		}
+	}
+	if named, ok := req.(interface{ GetService() string }); ok {
+		entry.Service = named.GetService()
+	}
+	if named, ok := req.(interface{ GetStack() string }); ok {
+		entry.Stack = named.GetStack()
+	}
+
+	switch status.Code(err) {
+	case codes.OK:
+		entry.Outcome = audit.OutcomeAllowed
+	case codes.Unauthenticated:
+		entry.Outcome = audit.OutcomeUnauthenticated
+	case codes.PermissionDenied:
+		entry.Outcome = audit.OutcomeDenied
+	case codes.ResourceExhausted:
+		entry.Outcome = audit.OutcomeRateLimited
+	default:
+		entry.Outcome = audit.OutcomeFailed
+	}
+
+	if err := s.Audit.Record(entry); err != nil {
+		internalError(err)
+	}
+}
+
+// AuditHandler returns the most recent audit entries, filtered by ?identity=, ?service=, ?stack=, ?outcome= and
+// ?since= (RFC 3339). Reading the audit log requires authentication and the "*" scope, it is not served when the
+// server has no Authenticator.
+func (s *Server) AuditHandler(w http.ResponseWriter, r *http.Request) {
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		renderError(w, r, http.StatusNotFound, "The audit log is only served when authentication is enabled.")
+		return
+	}
+
+	if !identity.CanQueryAudit() {
+		renderError(w, r, http.StatusForbidden, fmt.Sprintf("%s is not allowed to query the audit log.", identity.Name))
+		return
+	}
+
+	if s.Audit == nil {
+		renderError(w, r, http.StatusNotFound, "The audit log is not configured.")
+		return
+	}
+
+	query := r.URL.Query()
+	auditQuery := audit.Query{
+		Identity: query.Get("identity"),
+		Service:  query.Get("service"),
+		Stack:    query.Get("stack"),
+		Outcome:  query.Get("outcome"),
+		Limit:    DefaultAuditLimit,
+	}
+
+	if value := query.Get("since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, "The since parameter must be a RFC 3339 timestamp, e.g. 2017-11-26T21:47:35Z.")
+			return
+		}
+		auditQuery.Since = since
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > audit.DefaultRecentSize {
+			renderError(w, r, http.StatusBadRequest, fmt.Sprintf("The limit parameter must be a number between 1 and %d.", audit.DefaultRecentSize))
+			return
+		}
+		auditQuery.Limit = limit
+	}
+
+	render(w, r, http.StatusOK, s.Audit.Recent(auditQuery))
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/albertogviana/docker-swarm-service-status/audit"
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/gorilla/mux"
+)
+
+func (s *ServerTestSuite) auditServer(serviceMock *ServiceMock) (*Server, func()) {
+	dir, err := ioutil.TempDir("", "audit")
+	s.Require().NoError(err)
+
+	log, err := audit.Open(filepath.Join(dir, "audit.log"))
+	s.Require().NoError(err)
+
+	server := &Server{
+		Service:       serviceMock,
+		Authenticator: NewTokenAuthenticator([]Credential{{"admin", "s3cr3t", []string{"*"}}, {"ci", "t0k3n", []string{"stack:billing"}}}),
+		Audit:         log,
+	}
+
+	return server, func() {
+		log.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func (s *ServerTestSuite) serveAudited(server *Server, method string, target string, body string, token string) *httptest.ResponseRecorder {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	req.RemoteAddr = "10.0.0.1:51234"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	muxRouter.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *ServerTestSuite) Test_Audit_RecordsRequests() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+	serviceMock.On("GetDeploymentStatus", "billing_api", "billing/api:1.1.0").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+	server, cleanup := s.auditServer(serviceMock)
+	defer cleanup()
+
+	s.Equal(200, s.serveAudited(server, "GET", "/v1/docker-swarm-service-status/service-status/billing_api", "", "t0k3n").Code)
+	s.Equal(403, s.serveAudited(server, "GET", "/v1/docker-swarm-service-status/stack-status/prod", "", "t0k3n").Code)
+	s.Equal(401, s.serveAudited(server, "GET", "/v1/docker-swarm-service-status/service-status/billing_api", "", "").Code)
+	s.Equal(200, s.serveAudited(server, "POST", "/v1/docker-swarm-service-status/batch/deployment-status", `{"Deployments":[{"Service":"billing_api","Image":"billing/api:1.1.0"}]}`, "t0k3n").Code)
+	s.Equal(200, s.serveAudited(server, "GET", "/v1/docker-swarm-service-status/health", "", "").Code)
+
+	entries := server.Audit.Recent(audit.Query{})
+	s.Require().Len(entries, 4, "public routes are not audited")
+
+	for _, entry := range entries {
+		s.False(entry.Timestamp.IsZero())
+		s.Equal("10.0.0.1", entry.ClientIP)
+	}
+
+	s.Equal([]string{"ci", "POST", "/v1/docker-swarm-service-status/batch/deployment-status", "billing_api", "allowed"}, []string{entries[0].Identity, entries[0].Method, entries[0].Endpoint, entries[0].Service, entries[0].Outcome})
+	s.Equal([]string{"", "GET", "/v1/docker-swarm-service-status/service-status/{service}", "billing_api", "unauthenticated"}, []string{entries[1].Identity, entries[1].Method, entries[1].Endpoint, entries[1].Service, entries[1].Outcome})
+	s.Equal([]string{"ci", "GET", "/v1/docker-swarm-service-status/stack-status/{stack}", "prod", "denied"}, []string{entries[2].Identity, entries[2].Method, entries[2].Endpoint, entries[2].Stack, entries[2].Outcome})
+	s.Equal([]string{"ci", "GET", "/v1/docker-swarm-service-status/service-status/{service}", "billing_api", "allowed"}, []string{entries[3].Identity, entries[3].Method, entries[3].Endpoint, entries[3].Service, entries[3].Outcome})
+	s.Equal(403, entries[2].Status)
+}
+
+func (s *ServerTestSuite) Test_Audit_ReturnRecentEntries() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+	server, cleanup := s.auditServer(serviceMock)
+	defer cleanup()
+
+	s.serveAudited(server, "GET", "/v1/docker-swarm-service-status/service-status/billing_api", "", "t0k3n")
+	s.serveAudited(server, "GET", "/v1/docker-swarm-service-status/stack-status/prod", "", "t0k3n")
+
+	rec := s.serveAudited(server, "GET", "/v1/docker-swarm-service-status/audit?identity=ci&outcome=denied", "", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+	entries := audit.Entries{}
+	s.NoError(json.Unmarshal(rec.Body.Bytes(), &entries))
+	s.Require().Len(entries, 1)
+	s.Equal("prod", entries[0].Stack)
+
+	rec = s.serveAudited(server, "GET", "/v1/docker-swarm-service-status/audit?limit=2", "", "s3cr3t")
+
+	s.NoError(json.Unmarshal(rec.Body.Bytes(), &entries))
+	s.Len(entries, 2)
+	s.Equal("/v1/docker-swarm-service-status/audit", entries[0].Endpoint, "reading the audit log is audited")
+	s.Equal("admin", entries[0].Identity)
+}
+
+func (s *ServerTestSuite) Test_Audit_InvalidRequest() {
+	server, cleanup := s.auditServer(new(ServiceMock))
+	defer cleanup()
+
+	requests := []struct {
+		target  string
+		token   string
+		code    int
+		message string
+	}{
+		{"/audit", "t0k3n", 403, `{"error": "ci is not allowed to query the audit log."}`},
+		{"/audit?since=yesterday", "s3cr3t", 400, `{"error": "The since parameter must be a RFC 3339 timestamp, e.g. 2017-11-26T21:47:35Z."}`},
+		{"/audit?limit=0", "s3cr3t", 400, `{"error": "The limit parameter must be a number between 1 and 1000."}`},
+	}
+
+	for _, request := range requests {
+		rec := s.serveAudited(server, "GET", "/v1/docker-swarm-service-status"+request.target, "", request.token)
+
+		s.Equal(request.code, rec.Code, request.target)
+		s.Equal(request.message, rec.Body.String())
+	}
+
+	rec := s.serveAudited(&Server{Service: new(ServiceMock), Authenticator: server.Authenticator}, "GET", "/v1/docker-swarm-service-status/audit", "", "s3cr3t")
+
+	s.Equal(404, rec.Code)
+	s.Equal(`{"error": "The audit log is not configured."}`, rec.Body.String())
+
+	rec = s.serveAudited(&Server{Service: new(ServiceMock), Audit: server.Audit}, "GET", "/v1/docker-swarm-service-status/audit", "", "")
+
+	s.Equal(404, rec.Code, "the audit log is not served without authentication")
+	s.Equal(`{"error": "The audit log is only served when authentication is enabled."}`, rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_Audit_ResponsesConform() {
+	document := s.openAPI()
+
+	server, cleanup := s.auditServer(new(ServiceMock))
+	defer cleanup()
+
+	requests := []struct {
+		server *Server
+		target string
+		token  string
+		code   int
+	}{
+		{server, "/audit", "s3cr3t", 200},
+		{server, "/audit?format=table", "s3cr3t", 200},
+		{server, "/audit?since=2017-11-26T21:47:35Z&outcome=denied", "s3cr3t", 200},
+		{server, "/audit?limit=abc", "s3cr3t", 400},
+		{server, "/audit", "", 401},
+		{server, "/audit", "t0k3n", 403},
+		{&Server{Service: new(ServiceMock), Audit: server.Audit}, "/audit", "", 404},
+		{server, "/audit?format=junit", "s3cr3t", 406},
+	}
+
+	for _, request := range requests {
+		rec := s.serveAudited(request.server, "GET", "/v1/docker-swarm-service-status"+request.target, "", request.token)
+
+		s.Equal(request.code, rec.Code, request.target)
+		s.assertConforms(document, "GET", "/v1/docker-swarm-service-status/audit", rec)
+	}
+}
@@ -0,0 +1,385 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// DockerSecretsPath is where Docker mounts the secrets granted to a service
+const DockerSecretsPath = "/run/secrets"
+
+// HMACScheme is the Authorization scheme used for signed requests
+const HMACScheme = "HMAC-SHA256"
+
+// HMACTimestampHeader carries the unix time used when signing a request
+const HMACTimestampHeader = "X-Signature-Timestamp"
+
+// ErrUnauthenticated is returned when a request carries no credentials the authenticator understands
+var ErrUnauthenticated = errors.New("Missing or unsupported credentials.")
+
+// ErrInvalidCredentials is returned when a request carries credentials that do not verify
+var ErrInvalidCredentials = errors.New("Invalid credentials.")
+
+// Identity describes an authenticated caller and what it may query
+type Identity struct {
+	Name string
+	// Scopes are patterns such as "service:billing-*", "stack:prod" or "*"
+	Scopes []string
+}
+
+// Authenticator verifies the credentials of a request
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+// Credential is a named secret with the scopes granted to it
+type Credential struct {
+	Name   string
+	Secret string
+	Scopes []string
+}
+
+// CanQueryService reports whether the identity may query the given service. Stack scopes match the
+// services deployed by "docker stack deploy", which are named <stack>_<service>.
+func (i *Identity) CanQueryService(serviceName string) bool {
+	for _, scope := range i.Scopes {
+		if scope == "*" {
+			return true
+		}
+
+		kind, pattern := splitScope(scope)
+		switch kind {
+		case "service":
+			if matched, _ := path.Match(pattern, serviceName); matched {
+				return true
+			}
+		case "stack":
+			if idx := strings.Index(serviceName, "_"); idx > 0 && i.CanQueryStack(serviceName[:idx]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// CanQueryStack reports whether the identity may query every service of the given stack
+func (i *Identity) CanQueryStack(stackName string) bool {
+	for _, scope := range i.Scopes {
+		if scope == "*" {
+			return true
+		}
+
+		kind, pattern := splitScope(scope)
+		if kind != "stack" {
+			continue
+		}
+
+		if matched, _ := path.Match(pattern, stackName); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanQueryAudit reports whether the identity may read the audit log, which requires the "*" scope
+func (i *Identity) CanQueryAudit() bool {
+	return i.hasWildcardScope()
+}
+
+// CanQueryNodes reports whether the identity may query the nodes of the cluster, which requires the "*" scope
+func (i *Identity) CanQueryNodes() bool {
+	return i.hasWildcardScope()
+}
+
+// hasWildcardScope reports whether the identity has the "*" scope, granting access to every resource
+func (i *Identity) hasWildcardScope() bool {
+	for _, scope := range i.Scopes {
+		if scope == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitScope(scope string) (string, string) {
+	parts := strings.SplitN(scope, ":", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// LoadCredentials reads credentials from a file with one "<name> <secret> [scope,scope...]" entry per line.
+// Empty lines and lines starting with # are ignored; entries without scopes may query everything.
+func LoadCredentials(filename string) ([]Credential, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseCredentials(file)
+}
+
+// LoadCredentialsFromSecret reads credentials from a Docker secret mounted under DockerSecretsPath
+func LoadCredentialsFromSecret(secretName string) ([]Credential, error) {
+	return LoadCredentials(filepath.Join(DockerSecretsPath, secretName))
+}
+
+// ParseCredentials parses the credential file format described in LoadCredentials
+func ParseCredentials(reader io.Reader) ([]Credential, error) {
+	credentials := []Credential{}
+	scanner := bufio.NewScanner(reader)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("invalid credential on line %d: expected \"<name> <secret> [scopes]\"", line)
+		}
+
+		credential := Credential{Name: fields[0], Secret: fields[1], Scopes: []string{"*"}}
+		if len(fields) == 3 {
+			credential.Scopes = strings.Split(fields[2], ",")
+		}
+
+		credentials = append(credentials, credential)
+	}
+
+	return credentials, scanner.Err()
+}
+
+// TokenAuthenticator accepts "Authorization: Bearer <token>" requests
+type TokenAuthenticator struct {
+	credentials []Credential
+}
+
+// NewTokenAuthenticator returns an authenticator that accepts the secrets of the given credentials as bearer tokens
+func NewTokenAuthenticator(credentials []Credential) *TokenAuthenticator {
+	return &TokenAuthenticator{credentials}
+}
+
+// Authenticate implements Authenticator
+func (a *TokenAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	scheme, token := authorization(r)
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	for _, credential := range a.credentials {
+		if subtle.ConstantTimeCompare([]byte(credential.Secret), []byte(token)) == 1 {
+			return &Identity{credential.Name, credential.Scopes}, nil
+		}
+	}
+
+	return nil, ErrInvalidCredentials
+}
+
+// HMACAuthenticator accepts requests signed with a shared key, sent as
+// "Authorization: HMAC-SHA256 keyId=<name>,signature=<hex>" together with the X-Signature-Timestamp header
+type HMACAuthenticator struct {
+	credentials map[string]Credential
+	// MaxSkew is how far the signature timestamp may be from the server clock
+	MaxSkew time.Duration
+	now     func() time.Time
+}
+
+// NewHMACAuthenticator returns an authenticator that verifies requests signed with the credential secrets
+func NewHMACAuthenticator(credentials []Credential) *HMACAuthenticator {
+	keys := map[string]Credential{}
+	for _, credential := range credentials {
+		keys[credential.Name] = credential
+	}
+
+	return &HMACAuthenticator{
+		credentials: keys,
+		MaxSkew:     5 * time.Minute,
+		now:         time.Now,
+	}
+}
+
+// Authenticate implements Authenticator
+func (a *HMACAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	scheme, params := authorization(r)
+	if !strings.EqualFold(scheme, HMACScheme) {
+		return nil, ErrUnauthenticated
+	}
+
+	keyID, signature := "", ""
+	for _, param := range strings.Split(params, ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch kv[0] {
+		case "keyId":
+			keyID = kv[1]
+		case "signature":
+			signature = kv[1]
+		}
+	}
+
+	credential, ok := a.credentials[keyID]
+	if !ok || signature == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	timestamp, err := strconv.ParseInt(r.Header.Get(HMACTimestampHeader), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	skew := a.now().Sub(time.Unix(timestamp, 0))
+	if skew > a.MaxSkew || skew < -a.MaxSkew {
+		return nil, ErrInvalidCredentials
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	expected := SignRequest(credential.Secret, r.Method, r.URL.RequestURI(), timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Identity{credential.Name, credential.Scopes}, nil
+}
+
+// SignRequest returns the hex encoded HMAC-SHA256 signature expected for a request. A request with a body also
+// signs the hex encoded SHA-256 of the body, so a captured signature can not be replayed with another body.
+func SignRequest(secret, method, requestURI string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%d", method, requestURI, timestamp)
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		fmt.Fprintf(mac, "\n%s", hex.EncodeToString(sum[:]))
+	}
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// readBody reads the body of the request, at most maxBatchBody bytes, and puts it back for the handler
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBatchBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxBatchBody {
+		return nil, fmt.Errorf("The request body is larger than %d bytes.", maxBatchBody)
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// Authenticators tries each authenticator in turn until one recognises the request credentials
+type Authenticators []Authenticator
+
+// Authenticate implements Authenticator
+func (a Authenticators) Authenticate(r *http.Request) (*Identity, error) {
+	for _, authenticator := range a {
+		identity, err := authenticator.Authenticate(r)
+		if err != ErrUnauthenticated {
+			return identity, err
+		}
+	}
+
+	return nil, ErrUnauthenticated
+}
+
+func authorization(r *http.Request) (string, string) {
+	parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 {
+		return parts[0], ""
+	}
+
+	return parts[0], strings.TrimSpace(parts[1])
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the identity of the authenticated caller, if any
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+// authenticate wraps a handler so it is only served to callers allowed to query the requested service or stack.
+// Requests pass through untouched when the server has no Authenticator. Requests are rate limited per credential,
+// or per IP address when the server has no Authenticator or the credentials are invalid, and recorded in the
+// audit log when the server has one.
+func (s *Server) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return s.audited(func(w http.ResponseWriter, r *http.Request) {
+		if s.Authenticator == nil {
+			if s.rateLimited(w, r, "ip:"+clientIP(r.RemoteAddr)) {
+				return
+			}
+			next(w, r)
+			return
+		}
+
+		identity, err := s.Authenticator.Authenticate(r)
+		if err != nil {
+			if s.rateLimited(w, r, "ip:"+clientIP(r.RemoteAddr)) {
+				return
+			}
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="docker-swarm-service-status", %s realm="docker-swarm-service-status"`, HMACScheme))
+			renderError(w, r, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		auditIdentity(r, identity)
+
+		if s.rateLimited(w, r, "identity:"+identity.Name) {
+			return
+		}
+
+		if serviceName, ok := mux.Vars(r)["service"]; ok && !identity.CanQueryService(serviceName) {
+			renderError(w, r, http.StatusForbidden, fmt.Sprintf("%s is not allowed to query the %s service.", identity.Name, serviceName))
+			return
+		}
+
+		if stackName, ok := mux.Vars(r)["stack"]; ok && !identity.CanQueryStack(stackName) {
+			renderError(w, r, http.StatusForbidden, fmt.Sprintf("%s is not allowed to query the %s stack.", identity.Name, stackName))
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, identity)))
+	})
+}
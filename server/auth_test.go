@@ -0,0 +1,171 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthTestSuite struct {
+	suite.Suite
+	credentials []Credential
+}
+
+func TestAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
+
+func (s *AuthTestSuite) SetupTest() {
+	credentials, err := ParseCredentials(strings.NewReader(`
+# name secret scopes
+jenkins s3cr3t service:docker-routing-mesh,stack:prod
+admin adm1n
+`))
+	s.Require().NoError(err)
+	s.credentials = credentials
+}
+
+func (s *AuthTestSuite) Test_ParseCredentials_ReturnCredentials() {
+	s.Len(s.credentials, 2)
+	s.Equal(Credential{"jenkins", "s3cr3t", []string{"service:docker-routing-mesh", "stack:prod"}}, s.credentials[0])
+	s.Equal([]string{"*"}, s.credentials[1].Scopes)
+}
+
+func (s *AuthTestSuite) Test_ParseCredentials_ReturnError_InvalidLine() {
+	_, err := ParseCredentials(strings.NewReader("jenkins"))
+
+	s.EqualError(err, `invalid credential on line 1: expected "<name> <secret> [scopes]"`)
+}
+
+func (s *AuthTestSuite) Test_Identity_CanQueryService() {
+	identity := &Identity{"jenkins", []string{"service:billing-*", "stack:prod"}}
+
+	s.True(identity.CanQueryService("billing-api"))
+	s.True(identity.CanQueryService("prod_frontend"))
+	s.False(identity.CanQueryService("staging_frontend"))
+	s.False(identity.CanQueryService("frontend"))
+}
+
+func (s *AuthTestSuite) Test_HealthHandler_StaysUnauthenticated() {
+	rec := s.serve(http.Header{}, "/v1/docker-swarm-service-status/health")
+
+	s.Equal(200, rec.Code)
+}
+
+func (s *AuthTestSuite) Test_ServiceStatus_ReturnUnauthorized_NoCredentials() {
+	rec := s.serve(http.Header{}, "/v1/docker-swarm-service-status/service-status/docker-routing-mesh")
+
+	s.Equal(401, rec.Code)
+	s.Equal(`{"error": "Missing or unsupported credentials."}`, rec.Body.String())
+	s.NotEmpty(rec.Header().Get("WWW-Authenticate"))
+}
+
+func (s *AuthTestSuite) Test_ServiceStatus_ReturnUnauthorized_InvalidToken() {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer wrong")
+
+	rec := s.serve(header, "/v1/docker-swarm-service-status/service-status/docker-routing-mesh")
+
+	s.Equal(401, rec.Code)
+	s.Equal(`{"error": "Invalid credentials."}`, rec.Body.String())
+}
+
+func (s *AuthTestSuite) Test_ServiceStatus_ReturnForbidden_OutOfScope() {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer s3cr3t")
+
+	rec := s.serve(header, "/v1/docker-swarm-service-status/service-status/billing")
+
+	s.Equal(403, rec.Code)
+	s.Equal(`{"error": "jenkins is not allowed to query the billing service."}`, rec.Body.String())
+}
+
+func (s *AuthTestSuite) Test_ServiceStatus_ReturnSuccess_ValidToken() {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer s3cr3t")
+
+	rec := s.serve(header, "/v1/docker-swarm-service-status/service-status/docker-routing-mesh")
+
+	s.Equal(200, rec.Code)
+}
+
+func (s *AuthTestSuite) Test_ServiceStatus_ReturnSuccess_ValidSignature() {
+	uri := "/v1/docker-swarm-service-status/service-status/prod_frontend"
+	timestamp := time.Now().Unix()
+
+	header := http.Header{}
+	header.Set("Authorization", fmt.Sprintf("%s keyId=jenkins,signature=%s", HMACScheme, SignRequest("s3cr3t", "GET", uri, timestamp, nil)))
+	header.Set(HMACTimestampHeader, fmt.Sprint(timestamp))
+
+	rec := s.serve(header, uri)
+
+	s.Equal(200, rec.Code)
+}
+
+func (s *AuthTestSuite) Test_ServiceStatus_ReturnUnauthorized_ExpiredSignature() {
+	uri := "/v1/docker-swarm-service-status/service-status/prod_frontend"
+	timestamp := time.Now().Add(-time.Hour).Unix()
+
+	header := http.Header{}
+	header.Set("Authorization", fmt.Sprintf("%s keyId=jenkins,signature=%s", HMACScheme, SignRequest("s3cr3t", "GET", uri, timestamp, nil)))
+	header.Set(HMACTimestampHeader, fmt.Sprint(timestamp))
+
+	rec := s.serve(header, uri)
+
+	s.Equal(401, rec.Code)
+}
+
+func (s *AuthTestSuite) Test_HMACAuthenticator_SignatureCoversTheBody() {
+	uri := "/v1/docker-swarm-service-status/batch/deployment-status"
+	body := `{"Deployments":[{"Service":"prod_web","Image":"prod/web:1.1.0"}]}`
+	timestamp := time.Now().Unix()
+	signature := SignRequest("s3cr3t", "POST", uri, timestamp, []byte(body))
+
+	request := func(body string) *http.Request {
+		r := httptest.NewRequest("POST", uri, strings.NewReader(body))
+		r.Header.Set("Authorization", fmt.Sprintf("%s keyId=jenkins,signature=%s", HMACScheme, signature))
+		r.Header.Set(HMACTimestampHeader, fmt.Sprint(timestamp))
+
+		return r
+	}
+
+	r := request(body)
+	identity, err := NewHMACAuthenticator(s.credentials).Authenticate(r)
+	s.Require().NoError(err)
+	s.Equal("jenkins", identity.Name)
+	read, _ := ioutil.ReadAll(r.Body)
+	s.Equal(body, string(read))
+
+	_, err = NewHMACAuthenticator(s.credentials).Authenticate(request(`{"Deployments":[{"Service":"prod_db","Image":"postgres:10"}]}`))
+	s.Equal(ErrInvalidCredentials, err)
+}
+
+func (s *AuthTestSuite) serve(header http.Header, uri string) *httptest.ResponseRecorder {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", mock.AnythingOfType("string")).Return(service.ServiceStatus{}, nil)
+
+	server := &Server{
+		Service:       serviceMock,
+		Authenticator: Authenticators{NewTokenAuthenticator(s.credentials), NewHMACAuthenticator(s.credentials)},
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	r := httptest.NewRequest("GET", uri, nil)
+	r.Header = header
+
+	rec := httptest.NewRecorder()
+	muxRouter.ServeHTTP(rec, r)
+
+	return rec
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/report"
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/gorilla/mux"
+)
+
+// BadgeMaxAge is how long clients and proxies may cache a badge
+const BadgeMaxAge = 30 * time.Second
+
+// badge authenticates the requests of a badge route, unless PublicBadges is set in which case badges are served to
+// anyone and only rate limited by client IP
+func (s *Server) badge(next http.HandlerFunc) http.HandlerFunc {
+	if !s.PublicBadges {
+		return s.authenticate(next)
+	}
+
+	return s.audited(func(w http.ResponseWriter, r *http.Request) {
+		if s.rateLimited(w, r, "ip:"+clientIP(r.RemoteAddr)) {
+			return
+		}
+		next(w, r)
+	})
+}
+
+// ServiceBadgeHandler returns an SVG badge with the deployment state of the service
+func (s *Server) ServiceBadgeHandler(w http.ResponseWriter, r *http.Request) {
+	serviceName := mux.Vars(r)["service"]
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := svc.GetServiceStatus(serviceName)
+	if err != nil {
+		logError(r, err)
+		writeBadge(w, r, http.StatusInternalServerError, report.Badge{Label: serviceName, Message: "unavailable", Colour: report.BadgeColours[service.VerdictNotFound]})
+		return
+	}
+
+	writeBadge(w, r, http.StatusOK, report.ServiceBadge(status))
+}
+
+// StackBadgeHandler returns an SVG badge with the deployment state of the stack
+func (s *Server) StackBadgeHandler(w http.ResponseWriter, r *http.Request) {
+	stackName := mux.Vars(r)["stack"]
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := svc.GetStackStatus(stackName)
+	if err != nil {
+		logError(r, err)
+		writeBadge(w, r, http.StatusInternalServerError, report.Badge{Label: stackName, Message: "unavailable", Colour: report.BadgeColours[service.VerdictNotFound]})
+		return
+	}
+
+	writeBadge(w, r, http.StatusOK, report.StackBadge(status))
+}
+
+// writeBadge writes the badge with caching headers, ?label= replaces the left part of the badge.
+// Errors are not cached, badges answered to an identity are only cached by the client and unchanged badges are
+// answered with 304 Not Modified.
+func writeBadge(w http.ResponseWriter, r *http.Request, code int, badge report.Badge) {
+	if label := r.URL.Query().Get("label"); label != "" {
+		badge.Label = label
+	}
+
+	buffer := &bytes.Buffer{}
+	report.WriteBadge(buffer, badge)
+
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(buffer.Bytes()))
+
+	w.Header().Set("Content-Type", report.BadgeContentType)
+	if code != http.StatusOK {
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	} else {
+		cacheControl := "public"
+		if _, authenticated := IdentityFromContext(r.Context()); authenticated {
+			cacheControl = "private"
+		}
+		w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", cacheControl, int(BadgeMaxAge.Seconds())))
+		w.Header().Set("Expires", time.Now().Add(BadgeMaxAge).UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", etag)
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.WriteHeader(code)
+	w.Write(buffer.Bytes())
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/gorilla/mux"
+)
+
+func (s *ServerTestSuite) serveBadge(serviceMock *ServiceMock, target string, etag string) *httptest.ResponseRecorder {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: serviceMock})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", target, nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	muxRouter.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *ServerTestSuite) Test_ServiceBadge_ReturnSVG() {
+	serviceMock := new(ServiceMock)
+	replicas := uint64(3)
+	serviceMock.On("GetServiceStatus", "prod_web").Return(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web", Replicas: &replicas, RunningReplicas: 3}, nil)
+
+	rec := s.serveBadge(serviceMock, "/v1/docker-swarm-service-status/badge/service/prod_web?label=prod", "")
+
+	s.Equal(200, rec.Code)
+	s.Equal("image/svg+xml", rec.Header().Get("Content-Type"))
+	s.Equal("public, max-age=30", rec.Header().Get("Cache-Control"))
+	s.NotEmpty(rec.Header().Get("Expires"))
+	s.Contains(rec.Body.String(), "<title>prod: 3/3 running</title>")
+
+	rec = s.serveBadge(serviceMock, "/v1/docker-swarm-service-status/badge/service/prod_web?label=prod", rec.Header().Get("ETag"))
+
+	s.Equal(304, rec.Code)
+	s.Empty(rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_StackBadge_ReturnSVG() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetStackStatus", "prod").Return(service.StackStatus{Name: "prod", Err: "The prod stack was not found in the cluster."}, nil)
+
+	rec := s.serveBadge(serviceMock, "/v1/docker-swarm-service-status/badge/stack/prod", "")
+
+	s.Equal(200, rec.Code)
+	s.Contains(rec.Body.String(), "<title>prod: not found</title>")
+}
+
+func (s *ServerTestSuite) Test_ServiceBadge_ReturnError() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "prod_web").Return(service.ServiceStatus{}, errors.New("Cannot connect to the Docker daemon."))
+
+	rec := s.serveBadge(serviceMock, "/v1/docker-swarm-service-status/badge/service/prod_web", "")
+
+	s.Equal(500, rec.Code)
+	s.Equal("no-cache, no-store, must-revalidate", rec.Header().Get("Cache-Control"))
+	s.Empty(rec.Header().Get("ETag"))
+	s.Contains(rec.Body.String(), "<title>prod_web: unavailable</title>")
+}
+
+func (s *ServerTestSuite) Test_ServiceBadge_AuthenticatedIsPrivate() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "prod_web").Return(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web"}, nil)
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: serviceMock, Authenticator: NewTokenAuthenticator([]Credential{{"ci", "s3cr3t", []string{"*"}}})})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/badge/service/prod_web", nil)
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(401, rec.Code)
+
+	rec = httptest.NewRecorder()
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.Equal("private, max-age=30", rec.Header().Get("Cache-Control"))
+}
+
+func (s *ServerTestSuite) Test_ServiceBadge_PublicBadges() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "prod_web").Return(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web"}, nil)
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: serviceMock, Authenticator: NewTokenAuthenticator([]Credential{{"ci", "s3cr3t", []string{"*"}}}), PublicBadges: true})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/badge/service/prod_web", nil)
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.Equal("public, max-age=30", rec.Header().Get("Cache-Control"))
+
+	rec = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/v1/docker-swarm-service-status/service-status/prod_web", nil)
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(401, rec.Code, "only the badges are public")
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+)
+
+// MaxBatchSize is the maximum number of deployments a batch request may check
+const MaxBatchSize = 100
+
+// BatchConcurrency is the maximum number of deployments of a batch request checked at the same time
+const BatchConcurrency = 8
+
+// maxBatchBody is the maximum size of the body of a batch request
+const maxBatchBody = 1 << 20
+
+// BatchDeploymentStatusHandler returns the state of every service and image pair of the request body together with
+// the worst of their verdicts. Deployments are checked concurrently, at most BatchConcurrency at a time, and a
+// deployment whose status could not be read carries the error with the unknown verdict.
+func (s *Server) BatchDeploymentStatusHandler(w http.ResponseWriter, r *http.Request) {
+	request := service.BatchRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBody)).Decode(&request); err != nil {
+		renderError(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid batch request: %s.", err))
+		return
+	}
+
+	if len(request.Deployments) == 0 {
+		renderError(w, r, http.StatusBadRequest, "The batch request has no deployments.")
+		return
+	}
+
+	if len(request.Deployments) > MaxBatchSize {
+		renderError(w, r, http.StatusBadRequest, fmt.Sprintf("The batch request has %d deployments, the maximum is %d.", len(request.Deployments), MaxBatchSize))
+		return
+	}
+
+	serviceNames := make([]string, len(request.Deployments))
+	for i, deployment := range request.Deployments {
+		serviceNames[i] = deployment.Service
+	}
+	auditServices(r, serviceNames)
+
+	identity, authenticated := IdentityFromContext(r.Context())
+	for i, deployment := range request.Deployments {
+		if deployment.Service == "" || deployment.Image == "" {
+			renderError(w, r, http.StatusBadRequest, fmt.Sprintf("The deployment %d must have a service and an image.", i))
+			return
+		}
+
+		if authenticated && !identity.CanQueryService(deployment.Service) {
+			renderError(w, r, http.StatusForbidden, fmt.Sprintf("%s is not allowed to query the %s service.", identity.Name, deployment.Service))
+			return
+		}
+	}
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	render(w, r, http.StatusOK, batchDeploymentStatus(r, svc, request.Deployments))
+}
+
+// batchDeploymentStatus checks the deployments concurrently, at most BatchConcurrency at a time, and logs the
+// errors of the deployments whose status could not be read
+func batchDeploymentStatus(r *http.Request, svc service.Services, deployments []service.DeploymentCheck) service.BatchStatus {
+	results := make([]service.DeploymentResult, len(deployments))
+	slots := make(chan struct{}, BatchConcurrency)
+
+	var wg sync.WaitGroup
+	for i, deployment := range deployments {
+		wg.Add(1)
+		go func(i int, deployment service.DeploymentCheck) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			results[i] = service.DeploymentResult{Service: deployment.Service, Image: deployment.Image}
+
+			status, err := svc.GetDeploymentStatus(deployment.Service, deployment.Image)
+			if err != nil {
+				logError(r, err)
+				results[i].Verdict = service.VerdictUnknown
+				results[i].Err = err.Error()
+				return
+			}
+
+			results[i].Verdict = status.Verdict()
+			results[i].Status = &status
+		}(i, deployment)
+	}
+	wg.Wait()
+
+	verdicts := make([]service.Verdict, len(results))
+	for i, result := range results {
+		verdicts[i] = result.Verdict
+	}
+
+	return service.BatchStatus{Verdict: service.WorstVerdict(verdicts...), Deployments: results}
+}
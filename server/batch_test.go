@@ -0,0 +1,173 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+)
+
+func (s *ServerTestSuite) serveBatch(server *Server, target string, body string, token string) *httptest.ResponseRecorder {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/docker-swarm-service-status"+target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	muxRouter.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *ServerTestSuite) Test_BatchDeploymentStatus_ReturnWorstVerdict() {
+	replicas := uint64(2)
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetDeploymentStatus", "billing_api", "billing/api:1.1.0").Return(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "billing_api", Replicas: &replicas, RunningReplicas: 2}, nil)
+	serviceMock.On("GetDeploymentStatus", "billing_web", "billing/web:1.1.0").Return(service.ServiceStatus{ID: "evv1jw9o7981mrp0p50j1gy5k", Name: "billing_web", Replicas: &replicas, RunningReplicas: 1}, nil)
+	serviceMock.On("GetDeploymentStatus", "billing_db", "postgres:10").Return(service.ServiceStatus{}, errors.New("Cannot connect to the Docker daemon"))
+
+	rec := s.serveBatch(&Server{Service: serviceMock}, "/batch/deployment-status", `{"Deployments":[
+		{"Service":"billing_api","Image":"billing/api:1.1.0"},
+		{"Service":"billing_web","Image":"billing/web:1.1.0"}]}`, "")
+
+	s.Equal(200, rec.Code)
+	s.Equal(`{"Verdict":"in-progress","Deployments":[`+
+		`{"Service":"billing_api","Image":"billing/api:1.1.0","Verdict":"succeeded","Status":{"ID":"tt3otdsnkd1kgh80u45bwmcb4","Name":"billing_api","Replicas":2,"RunningReplicas":2}},`+
+		`{"Service":"billing_web","Image":"billing/web:1.1.0","Verdict":"in-progress","Status":{"ID":"evv1jw9o7981mrp0p50j1gy5k","Name":"billing_web","Replicas":2,"RunningReplicas":1}}]}`, rec.Body.String())
+
+	rec = s.serveBatch(&Server{Service: serviceMock}, "/batch/deployment-status", `{"Deployments":[
+		{"Service":"billing_api","Image":"billing/api:1.1.0"},
+		{"Service":"billing_db","Image":"postgres:10"}]}`, "")
+
+	s.Equal(200, rec.Code)
+	s.Contains(rec.Body.String(), `{"Verdict":"unknown",`)
+	s.Contains(rec.Body.String(), `{"Service":"billing_db","Image":"postgres:10","Verdict":"unknown","Err":"Cannot connect to the Docker daemon"}`)
+}
+
+func (s *ServerTestSuite) Test_BatchDeploymentStatus_BoundsConcurrency() {
+	var running, peak int32
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetDeploymentStatus", "billing_api", "billing/api:1.1.0").Return(service.ServiceStatus{}, nil).Run(func(args mock.Arguments) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			previous := atomic.LoadInt32(&peak)
+			if current <= previous || atomic.CompareAndSwapInt32(&peak, previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	})
+
+	deployments := []string{}
+	for i := 0; i < 3*BatchConcurrency; i++ {
+		deployments = append(deployments, `{"Service":"billing_api","Image":"billing/api:1.1.0"}`)
+	}
+
+	rec := s.serveBatch(&Server{Service: serviceMock}, "/batch/deployment-status", `{"Deployments":[`+strings.Join(deployments, ",")+`]}`, "")
+
+	s.Equal(200, rec.Code)
+	serviceMock.AssertNumberOfCalls(s.T(), "GetDeploymentStatus", 3*BatchConcurrency)
+	s.True(atomic.LoadInt32(&peak) <= BatchConcurrency, "%d deployments were checked at the same time", peak)
+}
+
+func (s *ServerTestSuite) Test_BatchDeploymentStatus_InvalidRequest() {
+	tooMany := strings.Repeat(`{"Service":"billing_api","Image":"billing/api:1.1.0"},`, MaxBatchSize+1)
+
+	requests := []struct {
+		body    string
+		message string
+	}{
+		{`{"Deployments":`, `{"error": "Invalid batch request: unexpected EOF."}`},
+		{`{"Deployments":[]}`, `{"error": "The batch request has no deployments."}`},
+		{`{"Deployments":[{"Service":"billing_api"}]}`, `{"error": "The deployment 0 must have a service and an image."}`},
+		{`{"Deployments":[` + strings.TrimSuffix(tooMany, ",") + `]}`, `{"error": "The batch request has 101 deployments, the maximum is 100."}`},
+	}
+
+	for _, request := range requests {
+		rec := s.serveBatch(&Server{Service: new(ServiceMock)}, "/batch/deployment-status", request.body, "")
+
+		s.Equal(400, rec.Code)
+		s.Equal(request.message, rec.Body.String())
+	}
+}
+
+func (s *ServerTestSuite) Test_BatchDeploymentStatus_ChecksEveryServiceScope() {
+	serviceMock := new(ServiceMock)
+	server := &Server{
+		Service:       serviceMock,
+		Authenticator: NewTokenAuthenticator([]Credential{{"ci", "t0k3n", []string{"stack:billing"}}}),
+	}
+
+	rec := s.serveBatch(server, "/batch/deployment-status", `{"Deployments":[
+		{"Service":"billing_api","Image":"billing/api:1.1.0"},
+		{"Service":"prod_web","Image":"prod/web:1.1.0"}]}`, "t0k3n")
+
+	s.Equal(403, rec.Code)
+	s.Equal(`{"error": "ci is not allowed to query the prod_web service."}`, rec.Body.String())
+	serviceMock.AssertNotCalled(s.T(), "GetDeploymentStatus", "billing_api", "billing/api:1.1.0")
+}
+
+func (s *ServerTestSuite) Test_BatchDeploymentStatus_RoutedByCluster() {
+	staging := new(ServiceMock)
+	staging.On("GetDeploymentStatus", "billing_api", "billing/api:1.1.0").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+	server := &Server{Service: new(ServiceMock), Clusters: map[string]service.Services{"staging": staging}}
+
+	rec := s.serveBatch(server, "/clusters/staging/batch/deployment-status", `{"Deployments":[{"Service":"billing_api","Image":"billing/api:1.1.0"}]}`, "")
+
+	s.Equal(200, rec.Code)
+	s.Equal(`{"Verdict":"not-found","Deployments":[{"Service":"billing_api","Image":"billing/api:1.1.0","Verdict":"not-found","Status":{"Name":"billing_api"}}]}`, rec.Body.String())
+
+	rec = s.serveBatch(server, "/clusters/production/batch/deployment-status", `{"Deployments":[{"Service":"billing_api","Image":"billing/api:1.1.0"}]}`, "")
+
+	s.Equal(404, rec.Code)
+}
+
+func (s *ServerTestSuite) Test_BatchDeploymentStatus_ResponsesConform() {
+	document := s.openAPI()
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetDeploymentStatus", "billing_api", "billing/api:1.1.0").Return(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "billing_api"}, nil)
+	serviceMock.On("GetDeploymentStatus", "billing_db", "postgres:10").Return(service.ServiceStatus{}, errors.New("Cannot connect to the Docker daemon"))
+	server := &Server{
+		Service:       serviceMock,
+		Clusters:      map[string]service.Services{"staging": serviceMock},
+		Authenticator: NewTokenAuthenticator([]Credential{{"admin", "s3cr3t", []string{"*"}}, {"ci", "t0k3n", []string{"stack:billing"}}}),
+	}
+
+	body := `{"Deployments":[{"Service":"billing_api","Image":"billing/api:1.1.0"}]}`
+	unavailable := `{"Deployments":[{"Service":"billing_api","Image":"billing/api:1.1.0"},{"Service":"billing_db","Image":"postgres:10"}]}`
+	requests := []struct {
+		route  string
+		target string
+		body   string
+		token  string
+		code   int
+	}{
+		{"/batch/deployment-status", "/batch/deployment-status", body, "s3cr3t", 200},
+		{"/batch/deployment-status", "/batch/deployment-status?format=table", body, "s3cr3t", 200},
+		{"/batch/deployment-status", "/batch/deployment-status", unavailable, "s3cr3t", 200},
+		{"/batch/deployment-status", "/batch/deployment-status", `{}`, "s3cr3t", 400},
+		{"/batch/deployment-status", "/batch/deployment-status", body, "", 401},
+		{"/batch/deployment-status", "/batch/deployment-status", `{"Deployments":[{"Service":"prod_web","Image":"prod/web:1.1.0"}]}`, "t0k3n", 403},
+		{"/batch/deployment-status", "/batch/deployment-status?format=junit", body, "s3cr3t", 406},
+		{"/clusters/{cluster}/batch/deployment-status", "/clusters/staging/batch/deployment-status", body, "s3cr3t", 200},
+		{"/clusters/{cluster}/batch/deployment-status", "/clusters/production/batch/deployment-status", body, "s3cr3t", 404},
+	}
+
+	for _, request := range requests {
+		rec := s.serveBatch(server, request.target, request.body, request.token)
+
+		s.Equal(request.code, rec.Code, request.target)
+		s.assertConforms(document, "POST", "/v1/docker-swarm-service-status"+request.route, rec)
+	}
+}
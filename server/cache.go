@@ -0,0 +1,146 @@
+package server
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+)
+
+// DefaultCacheTTL is how long a service status is served from the cache by default
+const DefaultCacheTTL = 2 * time.Second
+
+// Cache keeps the service statuses read from Docker for a short time, so clients polling the same service
+// share a single query to the daemon
+type Cache struct {
+	ttl     time.Duration
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+	// calls are the queries to the daemon in flight, the misses of the same key wait for them
+	calls map[string]*cacheCall
+	now   func() time.Time
+}
+
+type cacheEntry struct {
+	status  service.ServiceStatus
+	expires time.Time
+}
+
+// cacheCall is a query to the daemon shared by the concurrent misses of a key
+type cacheCall struct {
+	done   chan struct{}
+	status service.ServiceStatus
+	err    error
+}
+
+// NewCache returns a cache keeping the statuses for ttl
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, entries: map[string]cacheEntry{}, calls: map[string]*cacheCall{}, now: time.Now}
+}
+
+// TTL returns how long the statuses are kept
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
+// ServiceStatus returns the cached status stored under key, or calls get and caches its result when it succeeds.
+// Concurrent misses of the same key wait for a single call of get and share its result.
+func (c *Cache) ServiceStatus(key string, get func() (service.ServiceStatus, error)) (service.ServiceStatus, error) {
+	if c == nil {
+		return get()
+	}
+
+	c.mutex.Lock()
+	if entry, ok := c.entries[key]; ok && c.now().Before(entry.expires) {
+		c.mutex.Unlock()
+		return entry.status, nil
+	}
+
+	if call, ok := c.calls[key]; ok {
+		c.mutex.Unlock()
+		<-call.done
+		return call.status, call.err
+	}
+
+	call := &cacheCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mutex.Unlock()
+
+	call.status, call.err = get()
+
+	c.mutex.Lock()
+	delete(c.calls, key)
+	if call.err == nil {
+		now := c.now()
+		for key, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+		c.entries[key] = cacheEntry{call.status, now.Add(c.ttl)}
+	}
+	c.mutex.Unlock()
+	close(call.done)
+
+	return call.status, call.err
+}
+
+// cacheKey identifies the status requested by r: the cluster header together with the escaped path, which holds
+// the service name, the image of deployments and the cluster path segment
+func cacheKey(r *http.Request) string {
+	return r.Header.Get(ClusterHeader) + "\x00" + r.URL.EscapedPath()
+}
+
+// statusETag is a strong ETag of the representation of the status negotiated for r. It changes whenever the
+// service spec is updated, which bumps its version index, or one of its tasks changes state.
+func statusETag(r *http.Request, status service.ServiceStatus) string {
+	hash := sha1.New()
+
+	fmt.Fprintf(hash, "%s\n%s\n%s\n", r.Header.Get("Accept"), r.URL.Query().Get("format"), status.Name)
+	fmt.Fprintf(hash, "%s\n%d\n%s\n", status.ID, status.Version, status.Err)
+	if status.UpdateStatus != nil {
+		fmt.Fprintf(hash, "%s\n", status.UpdateStatus.State)
+	}
+	for _, task := range status.TaskStatus {
+		fmt.Fprintf(hash, "%s %s %s\n", task.TaskID, task.State, task.DesiredState)
+	}
+
+	return fmt.Sprintf(`"%x"`, hash.Sum(nil))
+}
+
+// notModified sets the ETag of the status, which depends on the Accept header, and answers with 304 Not Modified when it matches the If-None-Match
+// header, in which case it returns true
+func (s *Server) notModified(w http.ResponseWriter, r *http.Request, status service.ServiceStatus) bool {
+	etag := statusETag(r, status)
+
+	w.Header().Set("ETag", etag)
+	varyAccept(w)
+	if s.Cache != nil {
+		w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(s.Cache.TTL().Seconds())))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether the If-None-Match header lists the ETag, weak validators match as well
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
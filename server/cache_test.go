@@ -0,0 +1,176 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/gorilla/mux"
+)
+
+func (s *ServerTestSuite) serveCached(server *Server, target string, header http.Header) *httptest.ResponseRecorder {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status"+target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	muxRouter.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *ServerTestSuite) cachedStatus(state swarm.TaskState) service.ServiceStatus {
+	replicas := uint64(1)
+	return service.ServiceStatus{
+		ID:              "tt3otdsnkd1kgh80u45bwmcb4",
+		Name:            "prod_web",
+		Version:         42,
+		Replicas:        &replicas,
+		RunningReplicas: 1,
+		TaskStatus:      []service.TaskStatus{{TaskID: "evv1jw9o7981mrp0p50j1gy5k", State: state, DesiredState: swarm.TaskStateRunning}},
+	}
+}
+
+func (s *ServerTestSuite) Test_Cache_ServesStatusUntilExpired() {
+	now := time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC)
+	cache := NewCache(2 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "prod_web").Return(s.cachedStatus(swarm.TaskStateRunning), nil)
+	server := &Server{Service: serviceMock, Cache: cache}
+
+	for i := 0; i < 3; i++ {
+		rec := s.serveCached(server, "/service-status/prod_web", nil)
+		s.Equal(200, rec.Code)
+		s.Equal("private, max-age=2", rec.Header().Get("Cache-Control"))
+	}
+	serviceMock.AssertNumberOfCalls(s.T(), "GetServiceStatus", 1)
+
+	now = now.Add(2 * time.Second)
+	s.serveCached(server, "/service-status/prod_web", nil)
+	serviceMock.AssertNumberOfCalls(s.T(), "GetServiceStatus", 2)
+}
+
+func (s *ServerTestSuite) Test_Cache_KeyedByClusterAndPath() {
+	staging := new(ServiceMock)
+	staging.On("GetServiceStatus", "prod_web").Return(service.ServiceStatus{Name: "prod_web", Err: "staging"}, nil)
+	production := new(ServiceMock)
+	production.On("GetServiceStatus", "prod_web").Return(service.ServiceStatus{Name: "prod_web", Err: "production"}, nil)
+	production.On("GetServiceStatus", "prod_api").Return(service.ServiceStatus{Name: "prod_api", Err: "production"}, nil)
+
+	server := &Server{
+		Service:  production,
+		Clusters: map[string]service.Services{"staging": staging, "production": production},
+		Cache:    NewCache(time.Minute),
+	}
+
+	s.Equal(`{"Name":"prod_web","Err":"production"}`, s.serveCached(server, "/service-status/prod_web", nil).Body.String())
+	s.Equal(`{"Name":"prod_web","Err":"staging"}`, s.serveCached(server, "/service-status/prod_web", http.Header{ClusterHeader: {"staging"}}).Body.String())
+	s.Equal(`{"Name":"prod_web","Err":"staging"}`, s.serveCached(server, "/clusters/staging/service-status/prod_web", nil).Body.String())
+	s.Equal(`{"Name":"prod_api","Err":"production"}`, s.serveCached(server, "/service-status/prod_api", nil).Body.String())
+	s.Equal(`{"Name":"prod_web","Err":"production"}`, s.serveCached(server, "/service-status/prod_web", nil).Body.String())
+
+	production.AssertNumberOfCalls(s.T(), "GetServiceStatus", 2)
+	staging.AssertNumberOfCalls(s.T(), "GetServiceStatus", 2)
+}
+
+func (s *ServerTestSuite) Test_Cache_DoesNotCacheErrors() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "prod_web").Return(service.ServiceStatus{}, errors.New("Cannot connect to the Docker daemon")).Once()
+	serviceMock.On("GetServiceStatus", "prod_web").Return(s.cachedStatus(swarm.TaskStateRunning), nil).Once()
+	server := &Server{Service: serviceMock, Cache: NewCache(time.Minute)}
+
+	rec := s.serveCached(server, "/service-status/prod_web", nil)
+	s.Equal(500, rec.Code)
+	s.Empty(rec.Header().Get("ETag"))
+
+	rec = s.serveCached(server, "/service-status/prod_web", nil)
+	s.Equal(200, rec.Code)
+}
+
+func (s *ServerTestSuite) Test_Cache_SharesConcurrentMisses() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "prod_web").Return(s.cachedStatus(swarm.TaskStateRunning), nil).After(100 * time.Millisecond)
+	server := &Server{Service: serviceMock, Cache: NewCache(time.Minute)}
+
+	var wg sync.WaitGroup
+	codes := make([]int, 5)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = s.serveCached(server, "/service-status/prod_web", nil).Code
+		}(i)
+	}
+	wg.Wait()
+
+	s.Equal([]int{200, 200, 200, 200, 200}, codes)
+	serviceMock.AssertNumberOfCalls(s.T(), "GetServiceStatus", 1)
+}
+
+func (s *ServerTestSuite) Test_ServiceStatus_NotModified() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "prod_web").Return(s.cachedStatus(swarm.TaskStateRunning), nil).Twice()
+	serviceMock.On("GetServiceStatus", "prod_web").Return(s.cachedStatus(swarm.TaskStateFailed), nil).Once()
+	server := &Server{Service: serviceMock}
+
+	rec := s.serveCached(server, "/service-status/prod_web", nil)
+	etag := rec.Header().Get("ETag")
+	s.Equal(200, rec.Code)
+	s.NotEmpty(etag)
+	s.Equal("no-cache", rec.Header().Get("Cache-Control"))
+	s.Equal([]string{"Accept"}, rec.Header()["Vary"])
+
+	rec = s.serveCached(server, "/service-status/prod_web", http.Header{"If-None-Match": {etag}})
+	s.Equal(304, rec.Code)
+	s.Empty(rec.Body.String())
+	s.Equal(etag, rec.Header().Get("ETag"))
+	s.Equal([]string{"Accept"}, rec.Header()["Vary"])
+
+	rec = s.serveCached(server, "/service-status/prod_web", http.Header{"If-None-Match": {etag}})
+	s.Equal(200, rec.Code)
+	s.NotEqual(etag, rec.Header().Get("ETag"))
+}
+
+func (s *ServerTestSuite) Test_DeploymentStatus_NotModified() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetDeploymentStatus", "prod_web", "prod/web:1.1.0").Return(s.cachedStatus(swarm.TaskStateRunning), nil)
+	server := &Server{Service: serviceMock, Cache: NewCache(time.Minute)}
+
+	rec := s.serveCached(server, "/deployment-status/prod_web/cHJvZC93ZWI6MS4xLjA=", nil)
+	s.Equal(200, rec.Code)
+
+	rec = s.serveCached(server, "/deployment-status/prod_web/cHJvZC93ZWI6MS4xLjA=", http.Header{"If-None-Match": {`"stale", ` + rec.Header().Get("ETag")}})
+	s.Equal(304, rec.Code)
+	serviceMock.AssertNumberOfCalls(s.T(), "GetDeploymentStatus", 1)
+}
+
+func (s *ServerTestSuite) Test_StatusETag_ChangesWithRepresentation() {
+	status := s.cachedStatus(swarm.TaskStateRunning)
+	asJSON, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/service-status/prod_web", nil)
+	asYAML, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/service-status/prod_web?format=yaml", nil)
+
+	s.Equal(statusETag(asJSON, status), statusETag(asJSON, status))
+	s.NotEqual(statusETag(asJSON, status), statusETag(asYAML, status))
+
+	updated := s.cachedStatus(swarm.TaskStateRunning)
+	updated.Version = 43
+	s.NotEqual(statusETag(asJSON, status), statusETag(asJSON, updated))
+}
+
+func (s *ServerTestSuite) Test_ETagMatches() {
+	s.True(etagMatches(`"abc"`, `"abc"`))
+	s.True(etagMatches(`"def", W/"abc"`, `"abc"`))
+	s.True(etagMatches(`*`, `"abc"`))
+	s.False(etagMatches(``, `"abc"`))
+	s.False(etagMatches(`"abcd"`, `"abc"`))
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/gorilla/mux"
+)
+
+// ClusterHeader selects the cluster of a request when the route has no cluster path segment
+const ClusterHeader = "X-Swarm-Cluster"
+
+// clusters returns every cluster known by the server
+func (s *Server) clusters() map[string]service.Services {
+	if len(s.Clusters) == 0 {
+		return map[string]service.Services{service.DefaultCluster: s.Service}
+	}
+
+	return s.Clusters
+}
+
+// clusterNames returns the sorted names of every cluster known by the server
+func (s *Server) clusterNames() []string {
+	names := []string{}
+	for name := range s.clusters() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// cluster returns the cluster selected by the {cluster} path segment or the X-Swarm-Cluster header,
+// falling back to Server.Service. It writes a 404 and returns false when the cluster is unknown.
+func (s *Server) cluster(w http.ResponseWriter, r *http.Request) (service.Services, bool) {
+	name, ok := mux.Vars(r)["cluster"]
+	if !ok {
+		name = r.Header.Get(ClusterHeader)
+	}
+
+	if name == "" {
+		return withRequestContext(r, s.Service), true
+	}
+
+	if svc, ok := s.clusters()[name]; ok {
+		return withRequestContext(r, svc), true
+	}
+
+	renderError(w, r, http.StatusNotFound, fmt.Sprintf("The %s cluster is not configured.", name))
+	return nil, false
+}
+
+// ClustersHandler returns the names of the configured clusters
+func (s *Server) ClustersHandler(w http.ResponseWriter, r *http.Request) {
+	render(w, r, http.StatusOK, s.clusterNames())
+}
+
+// CrossClusterServiceStatusHandler returns the state of the same service in every cluster, side-by-side
+func (s *Server) CrossClusterServiceStatusHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	serviceName := vars["service"]
+
+	clusters := s.clusters()
+	names := s.clusterNames()
+	statuses := make([]service.ClusterStatus, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+
+			statuses[i].Cluster = name
+			status, err := withRequestContext(r, clusters[name]).GetServiceStatus(serviceName)
+			if err != nil {
+				statuses[i].Err = err.Error()
+				return
+			}
+			statuses[i].Status = &status
+		}(i, name)
+	}
+	wg.Wait()
+
+	render(w, r, http.StatusOK, statuses)
+}
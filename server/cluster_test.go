@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClusterTestSuite struct {
+	suite.Suite
+	staging    *ServiceMock
+	production *ServiceMock
+	muxRouter  *mux.Router
+}
+
+func TestClusterTestSuite(t *testing.T) {
+	suite.Run(t, new(ClusterTestSuite))
+}
+
+func (s *ClusterTestSuite) SetupTest() {
+	s.staging = new(ServiceMock)
+	s.production = new(ServiceMock)
+
+	server := &Server{
+		Service:  s.production,
+		Clusters: map[string]service.Services{"staging": s.staging, "production": s.production},
+	}
+
+	s.muxRouter = mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(s.muxRouter, server)
+}
+
+func (s *ClusterTestSuite) Test_Clusters_ReturnNames() {
+	rec := s.get("/v1/docker-swarm-service-status/clusters", nil)
+
+	s.Equal(200, rec.Code)
+	s.Equal(`["production","staging"]`, rec.Body.String())
+}
+
+func (s *ClusterTestSuite) Test_ServiceStatus_RoutedByPath() {
+	s.staging.On("GetServiceStatus", "api").Return(service.ServiceStatus{Name: "api", Err: "staging"}, nil)
+
+	rec := s.get("/v1/docker-swarm-service-status/clusters/staging/service-status/api", nil)
+
+	s.Equal(200, rec.Code)
+	s.Equal(`{"Name":"api","Err":"staging"}`, rec.Body.String())
+	s.production.AssertNotCalled(s.T(), "GetServiceStatus", "api")
+}
+
+func (s *ClusterTestSuite) Test_ServiceStatus_RoutedByHeader() {
+	s.staging.On("GetServiceStatus", "api").Return(service.ServiceStatus{Name: "api", Err: "staging"}, nil)
+
+	rec := s.get("/v1/docker-swarm-service-status/service-status/api", http.Header{ClusterHeader: []string{"staging"}})
+
+	s.Equal(200, rec.Code)
+	s.Equal(`{"Name":"api","Err":"staging"}`, rec.Body.String())
+}
+
+func (s *ClusterTestSuite) Test_ServiceStatus_DefaultCluster() {
+	s.production.On("GetServiceStatus", "api").Return(service.ServiceStatus{Name: "api"}, nil)
+
+	rec := s.get("/v1/docker-swarm-service-status/service-status/api", nil)
+
+	s.Equal(200, rec.Code)
+	s.staging.AssertNotCalled(s.T(), "GetServiceStatus", "api")
+}
+
+func (s *ClusterTestSuite) Test_ServiceStatus_ReturnNotFound_UnknownCluster() {
+	rec := s.get("/v1/docker-swarm-service-status/clusters/qa/service-status/api", nil)
+
+	s.Equal(404, rec.Code)
+	s.Equal(`{"error": "The qa cluster is not configured."}`, rec.Body.String())
+}
+
+func (s *ClusterTestSuite) Test_CrossClusterServiceStatus_ReturnEveryCluster() {
+	s.staging.On("GetServiceStatus", "api").Return(service.ServiceStatus{}, errors.New("Not able to connect on tcp://staging:2376"))
+	s.production.On("GetServiceStatus", "api").Return(service.ServiceStatus{Name: "api", RunningReplicas: 2}, nil)
+
+	rec := s.get("/v1/docker-swarm-service-status/cross-cluster/service-status/api", nil)
+
+	statuses := []service.ClusterStatus{}
+	json.Unmarshal(rec.Body.Bytes(), &statuses)
+
+	s.Equal(200, rec.Code)
+	s.Len(statuses, 2)
+	s.Equal("production", statuses[0].Cluster)
+	s.Equal(2, statuses[0].Status.RunningReplicas)
+	s.Equal("staging", statuses[1].Cluster)
+	s.Nil(statuses[1].Status)
+	s.Equal("Not able to connect on tcp://staging:2376", statuses[1].Err)
+}
+
+func (s *ClusterTestSuite) get(uri string, header http.Header) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", uri, nil)
+	if header != nil {
+		req.Header = header
+	}
+
+	rec := httptest.NewRecorder()
+	s.muxRouter.ServeHTTP(rec, req)
+
+	return rec
+}
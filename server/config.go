@@ -0,0 +1,92 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// Config defines how the server listens and shuts down
+type Config struct {
+	// Address is the host:port the server binds to
+	Address string
+	// GRPCAddress is the host:port the gRPC API binds to, it is disabled when empty
+	GRPCAddress string
+	// TLSCertFile and TLSKeyFile enable HTTPS when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile enables mutual TLS, requiring clients to present a certificate signed by this CA
+	TLSClientCAFile string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	// ShutdownTimeout is the grace period given to in-flight requests after a SIGTERM
+	ShutdownTimeout time.Duration
+}
+
+// DefaultConfig returns the configuration used when nothing else is specified
+func DefaultConfig() Config {
+	return Config{
+		Address:         "0.0.0.0:8080",
+		ReadTimeout:     10 * time.Second,
+		WriteTimeout:    60 * time.Second,
+		IdleTimeout:     120 * time.Second,
+		ShutdownTimeout: 30 * time.Second,
+	}
+}
+
+// TLSEnabled reports whether the server should serve HTTPS
+func (c Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != ""
+}
+
+// Validate checks that the configuration is consistent
+func (c Config) Validate() error {
+	if c.Address == "" {
+		return errors.New("the server address must not be empty")
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("both the TLS certificate and key must be provided")
+	}
+
+	if c.TLSClientCAFile != "" && !c.TLSEnabled() {
+		return errors.New("a TLS client CA requires the TLS certificate and key")
+	}
+
+	if c.ShutdownTimeout < 0 {
+		return errors.New("the shutdown timeout must not be negative")
+	}
+
+	return nil
+}
+
+func (c Config) tlsConfig() (*tls.Config, error) {
+	if !c.TLSEnabled() {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if c.TLSClientCAFile != "" {
+		pem, err := ioutil.ReadFile(c.TLSClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.TLSClientCAFile)
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
+
+func (s *ConfigTestSuite) Test_DefaultConfig_IsValid() {
+	config := DefaultConfig()
+
+	s.NoError(config.Validate())
+	s.Equal("0.0.0.0:8080", config.Address)
+	s.False(config.TLSEnabled())
+}
+
+func (s *ConfigTestSuite) Test_Validate_ReturnError_EmptyAddress() {
+	config := DefaultConfig()
+	config.Address = ""
+
+	s.EqualError(config.Validate(), "the server address must not be empty")
+}
+
+func (s *ConfigTestSuite) Test_Validate_ReturnError_MissingTLSKey() {
+	config := DefaultConfig()
+	config.TLSCertFile = "/run/secrets/cert.pem"
+
+	s.EqualError(config.Validate(), "both the TLS certificate and key must be provided")
+}
+
+func (s *ConfigTestSuite) Test_Validate_ReturnError_ClientCAWithoutTLS() {
+	config := DefaultConfig()
+	config.TLSClientCAFile = "/run/secrets/ca.pem"
+
+	s.EqualError(config.Validate(), "a TLS client CA requires the TLS certificate and key")
+}
+
+func (s *ConfigTestSuite) Test_TLSConfig_ReturnError_ClientCANotFound() {
+	config := DefaultConfig()
+	config.TLSCertFile = "/run/secrets/cert.pem"
+	config.TLSKeyFile = "/run/secrets/key.pem"
+	config.TLSClientCAFile = "/does/not/exist.pem"
+
+	_, err := config.tlsConfig()
+
+	s.Error(err)
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// DefaultCORSMethods are the methods cross-origin requests may use by default
+var DefaultCORSMethods = []string{"GET", "POST"}
+
+// DefaultCORSHeaders are the request headers cross-origin requests may send by default
+var DefaultCORSHeaders = []string{"Accept", "Authorization", "Content-Type", "If-None-Match", ClusterHeader, RequestIDHeader, "X-Signature-Timestamp"}
+
+// corsExposedHeaders are the response headers browsers let cross-origin scripts read
+var corsExposedHeaders = []string{"ETag", "Retry-After", RequestIDHeader}
+
+// DefaultCORSMaxAge is how long browsers cache the answer to a preflight request
+const DefaultCORSMaxAge = 10 * time.Minute
+
+// CORS lists the origins allowed to call the API from a browser, together with the methods and headers they may use
+type CORS struct {
+	// AllowedOrigins holds origins such as https://portal.example.com, patterns such as https://*.example.com or *
+	AllowedOrigins []string
+	// AllowedMethods defaults to DefaultCORSMethods when empty
+	AllowedMethods []string
+	// AllowedHeaders defaults to DefaultCORSHeaders when empty
+	AllowedHeaders []string
+	// MaxAge defaults to DefaultCORSMaxAge when zero
+	MaxAge time.Duration
+}
+
+// NewCORS returns a CORS configuration allowing origins with the default methods and headers
+func NewCORS(origins []string) *CORS {
+	return &CORS{
+		AllowedOrigins: origins,
+		AllowedMethods: DefaultCORSMethods,
+		AllowedHeaders: DefaultCORSHeaders,
+		MaxAge:         DefaultCORSMaxAge,
+	}
+}
+
+// allowsOrigin reports whether the origin matches one of the allowed origins
+func (c *CORS) allowsOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+
+	for _, pattern := range c.AllowedOrigins {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(origin)); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// methods returns the allowed methods among the methods of a route
+func (c *CORS) methods(routeMethods []string) []string {
+	allowed := c.AllowedMethods
+	if len(allowed) == 0 {
+		allowed = DefaultCORSMethods
+	}
+
+	methods := []string{}
+	for _, method := range routeMethods {
+		for _, allowedMethod := range allowed {
+			if strings.EqualFold(method, allowedMethod) {
+				methods = append(methods, method)
+				break
+			}
+		}
+	}
+
+	return methods
+}
+
+// headers returns the allowed request headers
+func (c *CORS) headers() []string {
+	if len(c.AllowedHeaders) == 0 {
+		return DefaultCORSHeaders
+	}
+
+	return c.AllowedHeaders
+}
+
+// maxAge returns how long browsers cache the answer to a preflight request
+func (c *CORS) maxAge() time.Duration {
+	if c.MaxAge == 0 {
+		return DefaultCORSMaxAge
+	}
+
+	return c.MaxAge
+}
+
+// cors lets the allowed origins read the responses of every route registered in router()
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			w.Header().Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			if s.CORS.allowsOrigin(origin) && len(s.CORS.methods([]string{r.Method})) > 0 {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routePreflights answers the OPTIONS preflight requests of every route registered so far, without authentication
+// since browsers do not send credentials with them
+func (s *Server) routePreflights(r *mux.Router) error {
+	methodsByPath := map[string][]string{}
+	paths := []string{}
+
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := methodsByPath[template]; !ok {
+			paths = append(paths, template)
+		}
+		methodsByPath[template] = append(methodsByPath[template], methods...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, template := range paths {
+		r.HandleFunc(template, s.preflight(methodsByPath[template])).Methods(http.MethodOptions)
+	}
+
+	return nil
+}
+
+// preflight answers the preflight requests of a route accepting routeMethods
+func (s *Server) preflight(routeMethods []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Allow", strings.Join(append(append([]string{}, routeMethods...), http.MethodOptions), ", "))
+
+		origin := r.Header.Get("Origin")
+		if !s.CORS.allowsOrigin(origin) {
+			renderError(w, r, http.StatusForbidden, fmt.Sprintf("The %q origin is not allowed.", origin))
+			return
+		}
+
+		methods := s.CORS.methods(routeMethods)
+		if requested := r.Header.Get("Access-Control-Request-Method"); requested != "" {
+			if !containsMethod(routeMethods, requested) {
+				renderError(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("The %s method is not supported by this route.", requested))
+				return
+			}
+
+			if !containsMethod(methods, requested) {
+				renderError(w, r, http.StatusForbidden, fmt.Sprintf("The %s method is not allowed for cross-origin requests.", requested))
+				return
+			}
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(s.CORS.headers(), ", "))
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(s.CORS.maxAge().Seconds())))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, candidate := range methods {
+		if strings.EqualFold(candidate, method) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/gorilla/mux"
+)
+
+func (s *ServerTestSuite) serveCORS(server *Server, method string, target string, headers map[string]string) *httptest.ResponseRecorder {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, target, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	muxRouter.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *ServerTestSuite) corsServer(serviceMock *ServiceMock) *Server {
+	return &Server{
+		Service:       serviceMock,
+		Authenticator: NewTokenAuthenticator([]Credential{{"portal", "s3cr3t", []string{"*"}}}),
+		CORS:          NewCORS([]string{"https://portal.example.com", "https://*.preview.example.com"}),
+	}
+}
+
+func (s *ServerTestSuite) Test_CORS_AnswersPreflightOnEveryRoute() {
+	server := s.corsServer(new(ServiceMock))
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	templates := []string{}
+	muxRouter.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		methods, _ := route.GetMethods()
+		if methods[0] != http.MethodOptions {
+			template, _ := route.GetPathTemplate()
+			templates = append(templates, template)
+		}
+		return nil
+	})
+	s.NotEmpty(templates)
+
+	for _, template := range templates {
+		target := strings.NewReplacer("{service}", "billing_api", "{image}", "YmlsbGluZy9hcGk6MS4wLjA=", "{stack}", "billing", "{cluster}", "staging", "{task}", "x8mjy3ys", "{node}", "manager1").Replace(template)
+
+		rec := s.serveCORS(server, "OPTIONS", target, map[string]string{"Origin": "https://portal.example.com"})
+
+		s.Equal(204, rec.Code, template)
+		s.Equal("https://portal.example.com", rec.Header().Get("Access-Control-Allow-Origin"), template)
+	}
+}
+
+func (s *ServerTestSuite) Test_CORS_Preflight() {
+	server := s.corsServer(new(ServiceMock))
+
+	rec := s.serveCORS(server, "OPTIONS", "/v1/docker-swarm-service-status/service-status/billing_api", map[string]string{
+		"Origin":                         "https://pr-42.preview.example.com",
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "authorization",
+	})
+
+	s.Equal(204, rec.Code)
+	s.Equal("https://pr-42.preview.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	s.Equal("GET", rec.Header().Get("Access-Control-Allow-Methods"))
+	s.Equal("Accept, Authorization, Content-Type, If-None-Match, X-Swarm-Cluster, X-Request-ID, X-Signature-Timestamp", rec.Header().Get("Access-Control-Allow-Headers"))
+	s.Equal("600", rec.Header().Get("Access-Control-Max-Age"))
+	s.Equal("GET, OPTIONS", rec.Header().Get("Allow"))
+	s.Equal("Origin", rec.Header().Get("Vary"))
+
+	rec = s.serveCORS(server, "OPTIONS", "/v1/docker-swarm-service-status/batch/deployment-status", map[string]string{
+		"Origin":                        "https://portal.example.com",
+		"Access-Control-Request-Method": "POST",
+	})
+
+	s.Equal(204, rec.Code)
+	s.Equal("POST", rec.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func (s *ServerTestSuite) Test_CORS_PreflightRejected() {
+	server := s.corsServer(new(ServiceMock))
+	server.CORS.AllowedMethods = []string{"GET"}
+
+	requests := []struct {
+		target  string
+		headers map[string]string
+		code    int
+		message string
+	}{
+		{"/v1/docker-swarm-service-status/service-status/billing_api", map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": "GET"}, 403, `{"error": "The \"https://evil.example.com\" origin is not allowed."}`},
+		{"/v1/docker-swarm-service-status/service-status/billing_api", map[string]string{"Origin": "https://portal.example.com", "Access-Control-Request-Method": "DELETE"}, 405, `{"error": "The DELETE method is not supported by this route."}`},
+		{"/v1/docker-swarm-service-status/batch/deployment-status", map[string]string{"Origin": "https://portal.example.com", "Access-Control-Request-Method": "POST"}, 403, `{"error": "The POST method is not allowed for cross-origin requests."}`},
+	}
+
+	for _, request := range requests {
+		rec := s.serveCORS(server, "OPTIONS", request.target, request.headers)
+
+		s.Equal(request.code, rec.Code, request.message)
+		s.Equal(request.message, rec.Body.String())
+		s.Empty(rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func (s *ServerTestSuite) Test_CORS_ActualRequests() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+	server := s.corsServer(serviceMock)
+
+	rec := s.serveCORS(server, "GET", "/v1/docker-swarm-service-status/service-status/billing_api", map[string]string{"Origin": "https://portal.example.com", "Authorization": "Bearer s3cr3t"})
+
+	s.Equal(200, rec.Code)
+	s.Equal("https://portal.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	s.Equal("ETag, Retry-After, X-Request-ID", rec.Header().Get("Access-Control-Expose-Headers"))
+	s.Contains(rec.Header()["Vary"], "Origin")
+
+	rec = s.serveCORS(server, "GET", "/v1/docker-swarm-service-status/service-status/billing_api", map[string]string{"Origin": "https://portal.example.com"})
+
+	s.Equal(401, rec.Code)
+	s.Equal("https://portal.example.com", rec.Header().Get("Access-Control-Allow-Origin"), "browsers must be able to read errors")
+
+	rec = s.serveCORS(server, "GET", "/v1/docker-swarm-service-status/service-status/billing_api", map[string]string{"Origin": "https://evil.example.com", "Authorization": "Bearer s3cr3t"})
+
+	s.Equal(200, rec.Code)
+	s.Empty(rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func (s *ServerTestSuite) Test_CORS_Disabled() {
+	rec := s.serveCORS(&Server{Service: new(ServiceMock)}, "OPTIONS", "/v1/docker-swarm-service-status/service-status/billing_api", map[string]string{"Origin": "https://portal.example.com"})
+
+	s.Equal(405, rec.Code)
+	s.Empty(rec.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,245 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/report"
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/gorilla/mux"
+)
+
+// StatusConcurrency is the maximum number of service statuses read at the same time to list services
+const StatusConcurrency = 8
+
+// DashboardRefresh is the default delay in seconds between two reloads of the dashboard, ?refresh=0 disables it
+const DashboardRefresh = 10
+
+// dashboardService is a row of the dashboard
+type dashboardService struct {
+	Status service.ServiceStatus
+	Stack  string
+	Image  string
+}
+
+// dashboardPage is the data of the dashboard templates
+type dashboardPage struct {
+	Title    string
+	Base     string
+	Cluster  string
+	Clusters []string
+	Refresh  int
+	Updated  time.Time
+	Err      string
+	Services []dashboardService
+	Service  *dashboardService
+}
+
+// DashboardHandler renders an HTML page listing every service of the cluster with its replicas, image,
+// update state and verdict
+func (s *Server) DashboardHandler(w http.ResponseWriter, r *http.Request) {
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	page := s.dashboardPage(r, "Services")
+
+	swarmServices, err := svc.GetServices(filters.NewArgs())
+	if err != nil {
+		logError(r, err)
+		page.Err = err.Error()
+		writeDashboard(w, http.StatusInternalServerError, page)
+		return
+	}
+
+	identity, authenticated := IdentityFromContext(r.Context())
+
+	allowed := []swarm.Service{}
+	for _, swarmService := range swarmServices {
+		if !authenticated || identity.CanQueryService(swarmService.Spec.Name) {
+			allowed = append(allowed, swarmService)
+		}
+	}
+
+	sort.Slice(allowed, func(i, j int) bool {
+		return allowed[i].Spec.Name < allowed[j].Spec.Name
+	})
+
+	rows := make([]dashboardService, len(allowed))
+	errs := make([]error, len(allowed))
+	slots := make(chan struct{}, StatusConcurrency)
+
+	var wg sync.WaitGroup
+	for i, swarmService := range allowed {
+		rows[i] = dashboardService{Stack: swarmService.Spec.Labels[service.StackNamespaceLabel], Image: service.SpecImage(&swarmService.Spec)}
+
+		wg.Add(1)
+		go func(i int, swarmService swarm.Service) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			rows[i].Status, errs[i] = service.StatusOf(svc, swarmService)
+		}(i, swarmService)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			logError(r, err)
+			page.Err = err.Error()
+			writeDashboard(w, http.StatusInternalServerError, page)
+			return
+		}
+	}
+
+	page.Services = rows
+	writeDashboard(w, http.StatusOK, page)
+}
+
+// DashboardServiceHandler renders an HTML page with the tasks of a service
+func (s *Server) DashboardServiceHandler(w http.ResponseWriter, r *http.Request) {
+	serviceName := mux.Vars(r)["service"]
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	page := s.dashboardPage(r, serviceName)
+
+	swarmService, found, err := findService(svc, serviceName)
+	if err == nil && found {
+		row := dashboardService{Stack: swarmService.Spec.Labels[service.StackNamespaceLabel], Image: service.SpecImage(&swarmService.Spec)}
+		row.Status, err = service.StatusOf(svc, swarmService)
+		page.Service = &row
+	}
+
+	if err != nil {
+		logError(r, err)
+		page.Service = nil
+		page.Err = err.Error()
+		writeDashboard(w, http.StatusInternalServerError, page)
+		return
+	}
+
+	if !found {
+		page.Err = fmt.Sprintf("The %s service was not found in the cluster.", serviceName)
+		writeDashboard(w, http.StatusNotFound, page)
+		return
+	}
+
+	writeDashboard(w, http.StatusOK, page)
+}
+
+// dashboardPage returns the page data shared by the dashboard pages
+func (s *Server) dashboardPage(r *http.Request, title string) dashboardPage {
+	page := dashboardPage{
+		Title:   title,
+		Base:    "/v1/docker-swarm-service-status/dashboard",
+		Refresh: DashboardRefresh,
+		Updated: time.Now().UTC(),
+	}
+
+	if len(s.Clusters) > 0 {
+		page.Clusters = s.clusterNames()
+	}
+
+	if cluster, ok := mux.Vars(r)["cluster"]; ok {
+		page.Cluster = cluster
+		page.Base = page.Base + "/clusters/" + cluster
+	}
+
+	if refresh, err := strconv.Atoi(r.URL.Query().Get("refresh")); err == nil && refresh >= 0 {
+		page.Refresh = refresh
+	}
+
+	return page
+}
+
+func writeDashboard(w http.ResponseWriter, code int, page dashboardPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	if err := dashboardTemplate.Execute(w, page); err != nil {
+		log.Println(err)
+	}
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"replicas": report.Replicas,
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339)
+	},
+	"image": func(image string) string {
+		return strings.SplitN(image, "@", 2)[0]
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - Docker Swarm Service Status</title>
+{{if .Refresh}}<meta http-equiv="refresh" content="{{.Refresh}}">{{end}}
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1d1c1d; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .4em .8em; border-bottom: 1px solid #ddd; font-size: .9em; }
+th { background: #f6f6f6; }
+nav a { margin-right: 1em; }
+.verdict { padding: .1em .5em; border-radius: .3em; color: #fff; }
+.succeeded { background: #2eb67d; }
+.in-progress { background: #36c5f0; }
+.not-found { background: #808080; }
+.failed { background: #e01e5a; }
+.rolled-back { background: #ecb22e; }
+.error { color: #e01e5a; }
+footer { margin-top: 1em; color: #808080; font-size: .8em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Clusters}}<nav>{{range .Clusters}}<a href="/v1/docker-swarm-service-status/dashboard/clusters/{{.}}">{{.}}</a>{{end}}</nav>{{end}}
+{{if .Err}}<p class="error">{{.Err}}</p>{{end}}
+{{with .Service}}
+<p><a href="{{$.Base}}">&larr; All services</a></p>
+<table>
+<tr><th>ID</th><td>{{.Status.ID}}</td></tr>
+<tr><th>Stack</th><td>{{.Stack}}</td></tr>
+<tr><th>Image</th><td>{{.Image}}</td></tr>
+<tr><th>Replicas</th><td>{{replicas .Status}}</td></tr>
+<tr><th>Update</th><td>{{with .Status.UpdateStatus}}{{.State}} {{.Message}}{{else}}-{{end}}</td></tr>
+<tr><th>Verdict</th><td><span class="verdict {{.Status.Verdict}}">{{.Status.Verdict}}</span></td></tr>
+{{if .Status.Err}}<tr><th>Error</th><td class="error">{{.Status.Err}}</td></tr>{{end}}
+</table>
+<h2>Tasks</h2>
+<table>
+<tr><th>ID</th><th>Slot</th><th>Node</th><th>State</th><th>Desired</th><th>Image</th><th>Updated</th><th>Error</th></tr>
+{{range .Status.TaskStatus}}<tr><td>{{.TaskID}}</td><td>{{if .Slot}}{{.Slot}}{{else}}-{{end}}</td><td>{{.NodeID}}</td><td>{{.State}}</td><td>{{.DesiredState}}</td><td>{{image .Image}}</td><td>{{time .Timestamp}}</td><td class="error">{{.Err}}</td></tr>
+{{else}}<tr><td colspan="8">No tasks.</td></tr>
+{{end}}</table>
+{{else}}{{if not .Err}}
+<table>
+<tr><th>Service</th><th>Stack</th><th>Image</th><th>Replicas</th><th>Update</th><th>Verdict</th></tr>
+{{range .Services}}<tr><td><a href="{{$.Base}}/services/{{.Status.Name}}">{{.Status.Name}}</a></td><td>{{.Stack}}</td><td>{{.Image}}</td><td>{{replicas .Status}}</td><td>{{with .Status.UpdateStatus}}{{.State}}{{else}}-{{end}}</td><td><span class="verdict {{.Status.Verdict}}">{{.Status.Verdict}}</span></td></tr>
+{{else}}<tr><td colspan="6">No services.</td></tr>
+{{end}}</table>
+{{end}}{{end}}
+<footer>Updated {{time .Updated}}{{if .Refresh}}, refreshing every {{.Refresh}}s{{end}}{{if .Cluster}} &middot; {{.Cluster}} cluster{{end}}</footer>
+</body>
+</html>
+`))
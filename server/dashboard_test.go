@@ -0,0 +1,130 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+)
+
+func (s *ServerTestSuite) dashboardService(name string, stack string) swarm.Service {
+	swarmService := swarm.Service{ID: name + "-id"}
+	swarmService.Spec.Name = name
+	swarmService.Spec.Labels = map[string]string{service.StackNamespaceLabel: stack}
+	swarmService.Spec.TaskTemplate.ContainerSpec = &swarm.ContainerSpec{Image: "acme/" + name + ":1.0.0@sha256:87e5c74f"}
+
+	return swarmService
+}
+
+func (s *ServerTestSuite) dashboardTask(id string, image string, desired swarm.TaskState, state swarm.TaskState) swarm.Task {
+	task := swarm.Task{ID: id, DesiredState: desired}
+	task.Status.State = state
+	task.Spec.ContainerSpec = &swarm.ContainerSpec{Image: image}
+
+	return task
+}
+
+func (s *ServerTestSuite) serveDashboard(server *Server, target string) *httptest.ResponseRecorder {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", target, nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+
+	muxRouter.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *ServerTestSuite) Test_Dashboard_ListServices() {
+	replicas := uint64(2)
+	web := s.dashboardService("prod_web", "prod")
+	web.Spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+	billing := s.dashboardService("billing_api", "billing")
+	billing.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStateRollbackCompleted}
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{web, billing}, nil)
+	serviceMock.On("GetTask", filters.NewArgs(filters.Arg("service", "prod_web-id"))).Return([]swarm.Task{
+		s.dashboardTask("ka8a7cwzf0pq4opcscd1yf7rn", "acme/prod_web:1.0.0@sha256:87e5c74f", swarm.TaskStateRunning, swarm.TaskStateRunning),
+		s.dashboardTask("evv1jw9o7981mrp0p50j1gy5k", "acme/prod_web:1.0.0@sha256:87e5c74f", swarm.TaskStateShutdown, swarm.TaskStateFailed),
+		s.dashboardTask("xk9b2tsjbhkxc3wgba1xwpx2w", "acme/prod_web:0.9.0", swarm.TaskStateShutdown, swarm.TaskStateFailed),
+	}, nil)
+	serviceMock.On("GetTask", filters.NewArgs(filters.Arg("service", "billing_api-id"))).Return([]swarm.Task{}, nil)
+
+	rec := s.serveDashboard(&Server{Service: serviceMock}, "/v1/docker-swarm-service-status/dashboard")
+
+	s.Equal(200, rec.Code)
+	s.Equal("text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	s.Contains(rec.Body.String(), `<meta http-equiv="refresh" content="10">`)
+	s.Contains(rec.Body.String(), `<td><a href="/v1/docker-swarm-service-status/dashboard/services/prod_web">prod_web</a></td><td>prod</td><td>acme/prod_web:1.0.0</td><td>1/2 running, 1 failed</td><td>-</td><td><span class="verdict in-progress">in-progress</span></td>`)
+	s.Contains(rec.Body.String(), `<td>rollback_completed</td><td><span class="verdict rolled-back">rolled-back</span></td>`)
+	s.Less(strings.Index(rec.Body.String(), "billing_api"), strings.Index(rec.Body.String(), "prod_web"), "services are sorted by name")
+}
+
+func (s *ServerTestSuite) Test_Dashboard_OnlyListsServicesInScope() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{s.dashboardService("prod_web", "prod"), s.dashboardService("billing_api", "billing")}, nil)
+	serviceMock.On("GetTask", filters.NewArgs(filters.Arg("service", "prod_web-id"))).Return([]swarm.Task{}, nil)
+
+	server := &Server{
+		Service:       serviceMock,
+		Authenticator: NewTokenAuthenticator([]Credential{{"ci", "s3cr3t", []string{"stack:prod"}}}),
+	}
+
+	rec := s.serveDashboard(server, "/v1/docker-swarm-service-status/dashboard?refresh=0")
+
+	s.Equal(200, rec.Code)
+	s.NotContains(rec.Body.String(), "billing_api")
+	s.NotContains(rec.Body.String(), `http-equiv="refresh"`)
+	serviceMock.AssertNotCalled(s.T(), "GetTask", filters.NewArgs(filters.Arg("service", "billing_api-id")))
+}
+
+func (s *ServerTestSuite) Test_Dashboard_ReturnError() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{}, errors.New("Cannot connect to the Docker daemon."))
+
+	rec := s.serveDashboard(&Server{Service: serviceMock}, "/v1/docker-swarm-service-status/dashboard")
+
+	s.Equal(500, rec.Code)
+	s.Contains(rec.Body.String(), `<p class="error">Cannot connect to the Docker daemon.</p>`)
+}
+
+func (s *ServerTestSuite) Test_DashboardService_ListTasks() {
+	failed := s.dashboardTask("ka8a7cwzf0pq4opcscd1yf7rn", "acme/prod_web:1.0.0@sha256:87e5c74f", swarm.TaskStateShutdown, swarm.TaskStateFailed)
+	failed.Slot = 2
+	failed.NodeID = "x8mjy3ys2ntcq3ypbhvbs6jyf"
+	failed.Status.Err = "task: non-zero exit (1)"
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs(filters.Arg("name", "prod_web"))).Return([]swarm.Service{s.dashboardService("prod_web", "prod"), s.dashboardService("prod_web-admin", "prod")}, nil)
+	serviceMock.On("GetTask", filters.NewArgs(filters.Arg("service", "prod_web-id"))).Return([]swarm.Task{failed}, nil)
+
+	server := &Server{Service: new(ServiceMock), Clusters: map[string]service.Services{"production": serviceMock}}
+	rec := s.serveDashboard(server, "/v1/docker-swarm-service-status/dashboard/clusters/production/services/prod_web")
+
+	s.Equal(200, rec.Code)
+	s.Contains(rec.Body.String(), `<a href="/v1/docker-swarm-service-status/dashboard/clusters/production">&larr; All services</a>`)
+	s.Contains(rec.Body.String(), `<tr><th>ID</th><td>prod_web-id</td></tr>`)
+	s.Contains(rec.Body.String(), `<tr><th>Replicas</th><td>0 running, 1 failed</td></tr>`)
+	s.Contains(rec.Body.String(), `<td>ka8a7cwzf0pq4opcscd1yf7rn</td><td>2</td><td>x8mjy3ys2ntcq3ypbhvbs6jyf</td><td>failed</td><td>shutdown</td><td>acme/prod_web:1.0.0</td><td>-</td><td class="error">task: non-zero exit (1)</td>`)
+	s.Contains(rec.Body.String(), "production cluster")
+}
+
+func (s *ServerTestSuite) Test_DashboardService_NotFound() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs(filters.Arg("name", "prod_web"))).Return([]swarm.Service{s.dashboardService("prod_web-admin", "prod")}, nil)
+
+	rec := s.serveDashboard(&Server{Service: serviceMock}, "/v1/docker-swarm-service-status/dashboard/services/prod_web")
+
+	s.Equal(404, rec.Code)
+	s.Contains(rec.Body.String(), "The prod_web service was not found in the cluster.")
+	serviceMock.AssertNotCalled(s.T(), "GetTask", mock.Anything)
+}
@@ -0,0 +1,571 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/albertogviana/docker-swarm-service-status/statuspb"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultWatchInterval is the delay between two polls of WatchDeployment when the request has no interval
+const DefaultWatchInterval = 2 * time.Second
+
+// MinWatchInterval is the shortest interval a WatchDeployment request may ask for
+const MinWatchInterval = 500 * time.Millisecond
+
+// statusServer implements the gRPC Status service on top of the clusters of the Server
+type statusServer struct {
+	statuspb.UnimplementedStatusServer
+	server *Server
+}
+
+// GRPCServer returns a gRPC server exposing the Status service. It shares the clusters and the authenticator of
+// the HTTP handlers: the cluster is selected with the x-swarm-cluster metadata and the credentials are read from
+// the authorization and x-signature-timestamp metadata. HMAC signatures cover "POST", the full RPC method name,
+// e.g. /dockerswarmservicestatus.v1.Status/GetServiceStatus, the timestamp and the request message, signed as the
+// body of the HTTP requests with its protobuf encoding. Every call starts a server span
+// continuing the trace of the traceparent and tracestate metadata.
+func (s *Server) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(traceUnary, s.authenticateUnary), grpc.ChainStreamInterceptor(traceStream, s.authenticateStream))
+
+	grpcServer := grpc.NewServer(opts...)
+	statuspb.RegisterStatusServer(grpcServer, &statusServer{server: s})
+
+	return grpcServer
+}
+
+func (s *Server) authenticateUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	authenticated, err := s.authenticateRPC(ctx, info.FullMethod, req)
+	if err != nil {
+		s.auditRPC(ctx, info.FullMethod, req, err)
+		return nil, err
+	}
+
+	resp, err := handler(authenticated, req)
+	s.auditRPC(authenticated, info.FullMethod, req, err)
+
+	return resp, err
+}
+
+// authenticateStream authenticates the call once its request message is received, the server streaming handlers
+// receive it before calling the Status service
+func (s *Server) authenticateStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	authenticated := &authenticatedStream{ServerStream: stream, ctx: stream.Context(), server: s, method: info.FullMethod}
+	err := handler(srv, authenticated)
+	s.auditRPC(authenticated.ctx, info.FullMethod, authenticated.req, err)
+
+	return err
+}
+
+// authenticatedStream authenticates the call with its first request message, carries the identity of the caller
+// in the stream context and keeps the request message for the audit log
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	req    interface{}
+	server *Server
+	method string
+}
+
+func (a *authenticatedStream) Context() context.Context {
+	return a.ctx
+}
+
+func (a *authenticatedStream) RecvMsg(m interface{}) error {
+	if err := a.ServerStream.RecvMsg(m); err != nil || a.req != nil {
+		return err
+	}
+	a.req = m
+
+	ctx, err := a.server.authenticateRPC(a.ctx, a.method, m)
+	if err != nil {
+		return err
+	}
+	a.ctx = ctx
+
+	return nil
+}
+
+// authenticateRPC verifies the credentials of the call with the Authenticator of the HTTP handlers, which sees the
+// metadata as headers and the protobuf encoding of the request message as body, and applies the rate limit of the
+// HTTP handlers. Calls pass through unauthenticated when the server has no Authenticator.
+func (s *Server) authenticateRPC(ctx context.Context, method string, req interface{}) (context.Context, error) {
+	address := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		address = clientIP(p.Addr.String())
+	}
+
+	if s.Authenticator == nil {
+		return ctx, s.rateLimitedRPC("ip:" + address)
+	}
+
+	var body []byte
+	if message, ok := req.(proto.Message); ok {
+		encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		body = encoded
+	}
+
+	r, err := http.NewRequest("POST", method, bytes.NewReader(body))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+
+	identity, err := s.Authenticator.Authenticate(r)
+	if err != nil {
+		if err := s.rateLimitedRPC("ip:" + address); err != nil {
+			return nil, err
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.rateLimitedRPC("identity:" + identity.Name); err != nil {
+		return nil, err
+	}
+
+	return context.WithValue(ctx, identityKey{}, identity), nil
+}
+
+// rateLimitedRPC returns a ResourceExhausted error when the client ran out of tokens
+func (s *Server) rateLimitedRPC(client string) error {
+	if s.RateLimiter == nil {
+		return nil
+	}
+
+	if allowed, delay := s.RateLimiter.Allow(client); !allowed {
+		return status.Errorf(codes.ResourceExhausted, "Too many requests, retry in %d second(s).", int(math.Ceil(delay.Seconds())))
+	}
+
+	return nil
+}
+
+// cluster returns the cluster selected by the x-swarm-cluster metadata, falling back to Server.Service. The Docker
+// API calls of the cluster are made with the context of the call, so they are traced as children of the call span
+// and cancelled with the call.
+func (g *statusServer) cluster(ctx context.Context) (service.Services, error) {
+	name := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ClusterHeader); len(values) > 0 {
+			name = values[0]
+		}
+	}
+
+	if name == "" {
+		return withContext(ctx, g.server.Service), nil
+	}
+
+	if svc, ok := g.server.clusters()[name]; ok {
+		return withContext(ctx, svc), nil
+	}
+
+	return nil, status.Errorf(codes.NotFound, "The %s cluster is not configured.", name)
+}
+
+// GetServices implements statuspb.StatusServer
+func (g *statusServer) GetServices(ctx context.Context, req *statuspb.ListRequest) (*statuspb.ServiceList, error) {
+	svc, err := g.cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	swarmServices, err := svc.GetServices(filterArgs(req.Filters))
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	identity, authenticated := IdentityFromContext(ctx)
+
+	list := &statuspb.ServiceList{}
+	for _, swarmService := range swarmServices {
+		if !authenticated || identity.CanQueryService(swarmService.Spec.Name) {
+			list.Services = append(list.Services, serviceMessage(swarmService))
+		}
+	}
+
+	return list, nil
+}
+
+// GetTasks implements statuspb.StatusServer
+func (g *statusServer) GetTasks(ctx context.Context, req *statuspb.ListRequest) (*statuspb.TaskList, error) {
+	svc, err := g.cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := svc.GetTask(filterArgs(req.Filters))
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	identity, authenticated := IdentityFromContext(ctx)
+
+	names := map[string]string{}
+	if authenticated {
+		names, err = serviceNames(svc, filters.NewArgs())
+		if err != nil {
+			return nil, internalError(err)
+		}
+	}
+
+	list := &statuspb.TaskList{}
+	for _, task := range tasks {
+		if !authenticated || identity.CanQueryService(names[task.ServiceID]) {
+			list.Tasks = append(list.Tasks, taskMessage(task))
+		}
+	}
+
+	return list, nil
+}
+
+// GetNodes implements statuspb.StatusServer
+func (g *statusServer) GetNodes(ctx context.Context, req *statuspb.ListRequest) (*statuspb.NodeList, error) {
+	if identity, ok := IdentityFromContext(ctx); ok && !identity.CanQueryNodes() {
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to query the nodes.", identity.Name)
+	}
+
+	svc, err := g.cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := svc.GetNodes(filterArgs(req.Filters))
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	list := &statuspb.NodeList{}
+	for _, node := range nodes {
+		list.Nodes = append(list.Nodes, nodeMessage(node))
+	}
+
+	return list, nil
+}
+
+// GetServiceStatus implements statuspb.StatusServer
+func (g *statusServer) GetServiceStatus(ctx context.Context, req *statuspb.ServiceStatusRequest) (*statuspb.ServiceStatus, error) {
+	if err := canQueryService(ctx, req.Service); err != nil {
+		return nil, err
+	}
+
+	svc, err := g.cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceStatus, err := svc.GetServiceStatus(req.Service)
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	return serviceStatusMessage(serviceStatus), nil
+}
+
+// GetDeploymentStatus implements statuspb.StatusServer
+func (g *statusServer) GetDeploymentStatus(ctx context.Context, req *statuspb.DeploymentStatusRequest) (*statuspb.ServiceStatus, error) {
+	if err := canQueryService(ctx, req.Service); err != nil {
+		return nil, err
+	}
+
+	if req.Image == "" {
+		return nil, status.Error(codes.InvalidArgument, "The image must not be empty.")
+	}
+
+	svc, err := g.cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceStatus, err := svc.GetDeploymentStatus(req.Service, req.Image)
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	return serviceStatusMessage(serviceStatus), nil
+}
+
+// GetStackStatus implements statuspb.StatusServer
+func (g *statusServer) GetStackStatus(ctx context.Context, req *statuspb.StackStatusRequest) (*statuspb.StackStatus, error) {
+	if req.Stack == "" {
+		return nil, status.Error(codes.InvalidArgument, "The stack must not be empty.")
+	}
+
+	if identity, ok := IdentityFromContext(ctx); ok && !identity.CanQueryStack(req.Stack) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to query the %s stack.", identity.Name, req.Stack)
+	}
+
+	svc, err := g.cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stackStatus, err := svc.GetStackStatus(req.Stack)
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	message := &statuspb.StackStatus{Name: stackStatus.Name, Err: stackStatus.Err, Verdict: string(stackStatus.Verdict())}
+	for _, serviceStatus := range stackStatus.Services {
+		message.Services = append(message.Services, serviceStatusMessage(serviceStatus))
+	}
+
+	return message, nil
+}
+
+// GetReadiness implements statuspb.StatusServer
+func (g *statusServer) GetReadiness(ctx context.Context, req *statuspb.ReadinessRequest) (*statuspb.Readiness, error) {
+	svc, err := g.cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	readiness := svc.GetReadiness()
+
+	message := &statuspb.Readiness{Ready: readiness.Ready, ApiVersion: readiness.APIVersion, Latency: readiness.Latency}
+	for _, check := range readiness.Checks {
+		message.Checks = append(message.Checks, &statuspb.Check{Name: check.Name, Ok: check.OK, Message: check.Message})
+	}
+
+	return message, nil
+}
+
+// GetInfo implements statuspb.StatusServer
+func (g *statusServer) GetInfo(ctx context.Context, req *statuspb.InfoRequest) (*statuspb.Info, error) {
+	svc, err := g.cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := svc.GetInfo()
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	message := &statuspb.Info{
+		ApiVersion:          info.APIVersion,
+		ServerApiVersion:    info.ServerAPIVersion,
+		ServerMinApiVersion: info.ServerMinAPIVersion,
+		ServerVersion:       info.ServerVersion,
+	}
+	for _, feature := range info.Features {
+		message.Features = append(message.Features, &statuspb.Feature{Name: feature.Name, Description: feature.Description, MinApiVersion: feature.MinAPIVersion, Supported: feature.Supported})
+	}
+
+	return message, nil
+}
+
+// WatchDeployment implements statuspb.StatusServer, it polls the deployment status every interval and sends it
+// whenever it changed until the verdict is no longer in-progress or the client goes away
+func (g *statusServer) WatchDeployment(req *statuspb.WatchDeploymentRequest, stream statuspb.Status_WatchDeploymentServer) error {
+	ctx := stream.Context()
+
+	if err := canQueryService(ctx, req.Service); err != nil {
+		return err
+	}
+
+	if req.Image == "" {
+		return status.Error(codes.InvalidArgument, "The image must not be empty.")
+	}
+
+	interval := DefaultWatchInterval
+	if req.Interval != nil {
+		interval = req.Interval.AsDuration()
+		if interval < MinWatchInterval {
+			return status.Errorf(codes.InvalidArgument, "The interval must be at least %s.", MinWatchInterval)
+		}
+	}
+
+	svc, err := g.cluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *statuspb.ServiceStatus
+	for {
+		serviceStatus, err := svc.GetDeploymentStatus(req.Service, req.Image)
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		if err != nil {
+			return internalError(err)
+		}
+
+		message := serviceStatusMessage(serviceStatus)
+		if last == nil || !proto.Equal(last, message) {
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+			last = message
+		}
+
+		if serviceStatus.Verdict() != service.VerdictInProgress {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// canQueryService returns a PermissionDenied error when the caller may not query the service
+func canQueryService(ctx context.Context, serviceName string) error {
+	if serviceName == "" {
+		return status.Error(codes.InvalidArgument, "The service must not be empty.")
+	}
+
+	if identity, ok := IdentityFromContext(ctx); ok && !identity.CanQueryService(serviceName) {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to query the %s service.", identity.Name, serviceName)
+	}
+
+	return nil
+}
+
+func internalError(err error) error {
+	log.Println(err)
+	return status.Error(codes.Internal, err.Error())
+}
+
+func filterArgs(messages []*statuspb.Filter) filters.Args {
+	filter := filters.NewArgs()
+	for _, message := range messages {
+		filter.Add(message.Key, message.Value)
+	}
+
+	return filter
+}
+
+func serviceMessage(swarmService swarm.Service) *statuspb.Service {
+	message := &statuspb.Service{
+		Id:           swarmService.ID,
+		Name:         swarmService.Spec.Name,
+		Stack:        swarmService.Spec.Labels[service.StackNamespaceLabel],
+		Mode:         serviceMode(swarmService.Spec.Mode),
+		Image:        service.SpecImage(&swarmService.Spec),
+		Labels:       swarmService.Spec.Labels,
+		UpdateStatus: updateStatusMessage(swarmService.UpdateStatus),
+		CreatedAt:    timestamp(swarmService.CreatedAt),
+		UpdatedAt:    timestamp(swarmService.UpdatedAt),
+	}
+
+	if swarmService.Spec.Mode.Replicated != nil {
+		message.Replicas = swarmService.Spec.Mode.Replicated.Replicas
+	}
+
+	return message
+}
+
+func taskMessage(task swarm.Task) *statuspb.Task {
+	resource := newTaskResource(task, "")
+
+	return &statuspb.Task{
+		Id:           resource.ID,
+		ServiceId:    resource.ServiceID,
+		Slot:         int64(resource.Slot),
+		NodeId:       resource.NodeID,
+		State:        string(resource.State),
+		DesiredState: string(resource.DesiredState),
+		Message:      resource.Message,
+		Err:          resource.Err,
+		Image:        resource.Image,
+		CreatedAt:    timestamp(resource.CreatedAt),
+		UpdatedAt:    timestamp(resource.UpdatedAt),
+	}
+}
+
+func nodeMessage(node swarm.Node) *statuspb.Node {
+	resource := newNodeResource(node)
+
+	return &statuspb.Node{
+		Id:            resource.ID,
+		Hostname:      resource.Hostname,
+		Role:          string(resource.Role),
+		Availability:  string(resource.Availability),
+		State:         string(resource.State),
+		Addr:          resource.Addr,
+		Leader:        resource.Leader,
+		EngineVersion: resource.EngineVersion,
+		Labels:        resource.Labels,
+	}
+}
+
+func serviceStatusMessage(serviceStatus service.ServiceStatus) *statuspb.ServiceStatus {
+	message := &statuspb.ServiceStatus{
+		Id:              serviceStatus.ID,
+		Name:            serviceStatus.Name,
+		Err:             serviceStatus.Err,
+		Replicas:        serviceStatus.Replicas,
+		RunningReplicas: int64(serviceStatus.RunningReplicas),
+		FailedReplicas:  int64(serviceStatus.FailedReplicas),
+		UpdateStatus:    updateStatusMessage(serviceStatus.UpdateStatus),
+		Verdict:         string(serviceStatus.Verdict()),
+		Version:         serviceStatus.Version,
+	}
+
+	for _, task := range serviceStatus.TaskStatus {
+		message.TaskStatus = append(message.TaskStatus, &statuspb.TaskStatus{
+			TaskId:       task.TaskID,
+			Slot:         int64(task.Slot),
+			NodeId:       task.NodeID,
+			Timestamp:    timestamp(task.Timestamp),
+			DesiredState: string(task.DesiredState),
+			State:        string(task.State),
+			Message:      task.Message,
+			Err:          task.Err,
+			Image:        task.Image,
+		})
+	}
+
+	return message
+}
+
+func updateStatusMessage(updateStatus *swarm.UpdateStatus) *statuspb.UpdateStatus {
+	if updateStatus == nil {
+		return nil
+	}
+
+	message := &statuspb.UpdateStatus{State: string(updateStatus.State), Message: updateStatus.Message}
+	if updateStatus.StartedAt != nil {
+		message.StartedAt = timestamp(*updateStatus.StartedAt)
+	}
+	if updateStatus.CompletedAt != nil {
+		message.CompletedAt = timestamp(*updateStatus.CompletedAt)
+	}
+
+	return message
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
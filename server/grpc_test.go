@@ -0,0 +1,435 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/audit"
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/albertogviana/docker-swarm-service-status/statuspb"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type GRPCTestSuite struct {
+	suite.Suite
+	staging    *ServiceMock
+	production *ServiceMock
+	grpcServer *grpc.Server
+	conn       *grpc.ClientConn
+	client     statuspb.StatusClient
+}
+
+func TestGRPCTestSuite(t *testing.T) {
+	suite.Run(t, new(GRPCTestSuite))
+}
+
+func (s *GRPCTestSuite) SetupTest() {
+	s.staging = new(ServiceMock)
+	s.production = new(ServiceMock)
+
+	server := &Server{
+		Service:       s.production,
+		Clusters:      map[string]service.Services{"staging": s.staging, "production": s.production},
+		Authenticator: NewTokenAuthenticator([]Credential{{"admin", "s3cr3t", []string{"*"}}, {"ci", "t0k3n", []string{"stack:billing"}}}),
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	s.grpcServer = server.GRPCServer()
+	go s.grpcServer.Serve(listener)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	s.Require().NoError(err)
+
+	s.conn = conn
+	s.client = statuspb.NewStatusClient(conn)
+}
+
+func (s *GRPCTestSuite) TearDownTest() {
+	s.conn.Close()
+	s.grpcServer.Stop()
+}
+
+func (s *GRPCTestSuite) context(pairs ...string) context.Context {
+	return metadata.NewOutgoingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+// signed returns the context of a call signed with the s3cr3t key of ci for the request message
+func (s *GRPCTestSuite) signed(method string, req proto.Message) context.Context {
+	body, err := proto.Marshal(req)
+	s.Require().NoError(err)
+
+	timestamp := time.Now().Unix()
+	return s.context(
+		"authorization", fmt.Sprintf("%s keyId=ci,signature=%s", HMACScheme, SignRequest("s3cr3t", "POST", method, timestamp, body)),
+		HMACTimestampHeader, fmt.Sprint(timestamp),
+	)
+}
+
+func (s *GRPCTestSuite) Test_GetServiceStatus_ReturnSuccess() {
+	replicas := uint64(1)
+	s.production.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{
+		ID:              "tt3otdsnkd1kgh80u45bwmcb4",
+		Name:            "billing_api",
+		Replicas:        &replicas,
+		RunningReplicas: 1,
+		TaskStatus: []service.TaskStatus{{
+			TaskID:    "evv1jw9o7981mrp0p50j1gy5k",
+			Timestamp: time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC),
+			State:     "running",
+		}},
+	}, nil)
+
+	response, err := s.client.GetServiceStatus(s.context("authorization", "Bearer t0k3n"), &statuspb.ServiceStatusRequest{Service: "billing_api"})
+
+	s.Require().NoError(err)
+	s.Equal("tt3otdsnkd1kgh80u45bwmcb4", response.Id)
+	s.Equal(uint64(1), response.GetReplicas())
+	s.Equal(int64(1), response.RunningReplicas)
+	s.Equal("succeeded", response.Verdict)
+	s.Require().Len(response.TaskStatus, 1)
+	s.Equal("evv1jw9o7981mrp0p50j1gy5k", response.TaskStatus[0].TaskId)
+	s.Equal(int64(1511732855), response.TaskStatus[0].Timestamp.Seconds)
+}
+
+func (s *GRPCTestSuite) Test_GetServiceStatus_Unauthenticated() {
+	_, err := s.client.GetServiceStatus(context.Background(), &statuspb.ServiceStatusRequest{Service: "billing_api"})
+	s.Equal(codes.Unauthenticated, status.Code(err))
+
+	_, err = s.client.GetServiceStatus(s.context("authorization", "Bearer wrong"), &statuspb.ServiceStatusRequest{Service: "billing_api"})
+	s.Equal(codes.Unauthenticated, status.Code(err))
+}
+
+func (s *GRPCTestSuite) Test_GetServiceStatus_PermissionDenied() {
+	_, err := s.client.GetServiceStatus(s.context("authorization", "Bearer t0k3n"), &statuspb.ServiceStatusRequest{Service: "prod_web"})
+
+	s.Equal(codes.PermissionDenied, status.Code(err))
+	s.Equal("ci is not allowed to query the prod_web service.", status.Convert(err).Message())
+	s.production.AssertNotCalled(s.T(), "GetServiceStatus", "prod_web")
+}
+
+func (s *GRPCTestSuite) Test_GetServiceStatus_ReturnError() {
+	s.production.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{}, errors.New("Cannot connect to the Docker daemon"))
+
+	_, err := s.client.GetServiceStatus(s.context("authorization", "Bearer s3cr3t"), &statuspb.ServiceStatusRequest{Service: "billing_api"})
+
+	s.Equal(codes.Internal, status.Code(err))
+}
+
+func (s *GRPCTestSuite) Test_GetServiceStatus_RoutedByMetadata() {
+	s.staging.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api", Err: "staging"}, nil)
+
+	response, err := s.client.GetServiceStatus(s.context("authorization", "Bearer s3cr3t", ClusterHeader, "staging"), &statuspb.ServiceStatusRequest{Service: "billing_api"})
+
+	s.Require().NoError(err)
+	s.Equal("staging", response.Err)
+	s.production.AssertNotCalled(s.T(), "GetServiceStatus", "billing_api")
+}
+
+func (s *GRPCTestSuite) Test_GetServiceStatus_UnknownCluster() {
+	_, err := s.client.GetServiceStatus(s.context("authorization", "Bearer s3cr3t", ClusterHeader, "qa"), &statuspb.ServiceStatusRequest{Service: "billing_api"})
+
+	s.Equal(codes.NotFound, status.Code(err))
+	s.Equal("The qa cluster is not configured.", status.Convert(err).Message())
+}
+
+func (s *GRPCTestSuite) Test_GetDeploymentStatus_InvalidArgument() {
+	_, err := s.client.GetDeploymentStatus(s.context("authorization", "Bearer s3cr3t"), &statuspb.DeploymentStatusRequest{Service: "billing_api"})
+
+	s.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func (s *GRPCTestSuite) Test_GetNodes_RequiresEveryScope() {
+	s.production.On("GetNodes", mock.Anything).Return([]swarm.Node{{ID: "n1", Description: swarm.NodeDescription{Hostname: "manager-1"}}}, nil)
+
+	_, err := s.client.GetNodes(s.context("authorization", "Bearer t0k3n"), &statuspb.ListRequest{})
+	s.Equal(codes.PermissionDenied, status.Code(err))
+
+	response, err := s.client.GetNodes(s.context("authorization", "Bearer s3cr3t"), &statuspb.ListRequest{})
+	s.Require().NoError(err)
+	s.Require().Len(response.Nodes, 1)
+	s.Equal("manager-1", response.Nodes[0].Hostname)
+}
+
+func (s *GRPCTestSuite) Test_WatchDeployment_StreamsChangesUntilDone() {
+	replicas := uint64(2)
+	inProgress := service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "billing_api", Replicas: &replicas, RunningReplicas: 1}
+	succeeded := service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "billing_api", Replicas: &replicas, RunningReplicas: 2}
+
+	s.production.On("GetDeploymentStatus", "billing_api", "billing/api:1.0.0").Return(inProgress, nil).Twice()
+	s.production.On("GetDeploymentStatus", "billing_api", "billing/api:1.0.0").Return(succeeded, nil).Once()
+
+	stream, err := s.client.WatchDeployment(s.context("authorization", "Bearer t0k3n"), &statuspb.WatchDeploymentRequest{
+		Service:  "billing_api",
+		Image:    "billing/api:1.0.0",
+		Interval: durationpb.New(MinWatchInterval),
+	})
+	s.Require().NoError(err)
+
+	verdicts := []string{}
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		s.Require().NoError(err)
+		verdicts = append(verdicts, message.Verdict)
+	}
+
+	s.Equal([]string{"in-progress", "succeeded"}, verdicts)
+	s.production.AssertNumberOfCalls(s.T(), "GetDeploymentStatus", 3)
+}
+
+func (s *GRPCTestSuite) Test_WatchDeployment_CancelsDockerCallsWhenClientGoesAway() {
+	replicas := uint64(2)
+	inProgress := service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "billing_api", Replicas: &replicas, RunningReplicas: 1}
+	s.production.On("GetDeploymentStatus", "billing_api", "billing/api:1.0.0").Return(inProgress, nil)
+
+	svc := &contextualService{ServiceMock: s.production, contexts: make(chan context.Context, 1)}
+	client, stop := s.dial(&Server{Service: svc})
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.WatchDeployment(ctx, &statuspb.WatchDeploymentRequest{Service: "billing_api", Image: "billing/api:1.0.0"})
+	s.Require().NoError(err)
+
+	_, err = stream.Recv()
+	s.Require().NoError(err)
+	callContext := <-svc.contexts
+
+	cancel()
+
+	select {
+	case <-callContext.Done():
+	case <-time.After(time.Second):
+		s.Fail("The Docker calls were not cancelled with the call.")
+	}
+}
+
+func (s *GRPCTestSuite) Test_GetServices_TracesCallAndDockerCalls() {
+	recordedSpans("")
+
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer daemon.Close()
+
+	svc, err := service.NewServiceWithConfig(service.Config{Host: "tcp://" + strings.TrimPrefix(daemon.URL, "http://"), APIVersion: "v1.33"})
+	s.Require().NoError(err)
+
+	client, stop := s.dial(&Server{Service: svc})
+	defer stop()
+
+	traceID := "0af7651916cd43dd8448eb211c80319c"
+	_, err = client.GetServices(s.context("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01"), &statuspb.ListRequest{})
+	s.Require().NoError(err)
+
+	spans := recordedSpans(traceID)
+	s.Require().Len(spans, 2)
+
+	docker, call := spans[0], spans[1]
+	s.Equal("/dockerswarmservicestatus.v1.Status/GetServices", call.Name())
+	s.Equal("00f067aa0ba902b7", call.Parent().SpanID().String())
+	s.Contains(call.Attributes(), attribute.Int("rpc.grpc.status_code", 0))
+
+	s.Equal("docker.ServiceList", docker.Name())
+	s.Equal(call.SpanContext().SpanID(), docker.Parent().SpanID())
+}
+
+func (s *GRPCTestSuite) Test_Signature_CoversTheRequestMessage() {
+	s.production.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+	s.production.On("GetServiceStatus", "billing_db").Return(service.ServiceStatus{Name: "billing_db"}, nil)
+
+	client, stop := s.dial(&Server{Service: s.production, Authenticator: NewHMACAuthenticator([]Credential{{"ci", "s3cr3t", []string{"stack:billing"}}})})
+	defer stop()
+
+	method := "/dockerswarmservicestatus.v1.Status/GetServiceStatus"
+	ctx := s.signed(method, &statuspb.ServiceStatusRequest{Service: "billing_api"})
+
+	_, err := client.GetServiceStatus(ctx, &statuspb.ServiceStatusRequest{Service: "billing_api"})
+	s.NoError(err)
+
+	_, err = client.GetServiceStatus(ctx, &statuspb.ServiceStatusRequest{Service: "billing_db"})
+	s.Equal(codes.Unauthenticated, status.Code(err), "the signature can not be replayed with another request")
+	s.production.AssertNotCalled(s.T(), "GetServiceStatus", "billing_db")
+}
+
+func (s *GRPCTestSuite) Test_Signature_CoversTheStreamRequestMessage() {
+	replicas := uint64(1)
+	s.production.On("GetDeploymentStatus", "billing_api", "billing/api:1.0.0").Return(service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "billing_api", Replicas: &replicas, RunningReplicas: 1}, nil)
+
+	client, stop := s.dial(&Server{Service: s.production, Authenticator: NewHMACAuthenticator([]Credential{{"ci", "s3cr3t", []string{"stack:billing"}}})})
+	defer stop()
+
+	method := "/dockerswarmservicestatus.v1.Status/WatchDeployment"
+	ctx := s.signed(method, &statuspb.WatchDeploymentRequest{Service: "billing_api", Image: "billing/api:1.0.0"})
+
+	stream, err := client.WatchDeployment(ctx, &statuspb.WatchDeploymentRequest{Service: "billing_api", Image: "billing/api:1.0.0"})
+	s.Require().NoError(err)
+	_, err = stream.Recv()
+	s.NoError(err)
+
+	stream, err = client.WatchDeployment(ctx, &statuspb.WatchDeploymentRequest{Service: "billing_api", Image: "billing/api:0.9.0"})
+	s.Require().NoError(err)
+	_, err = stream.Recv()
+	s.Equal(codes.Unauthenticated, status.Code(err), "the signature can not be replayed with another request")
+}
+
+func (s *GRPCTestSuite) Test_WatchDeployment_IntervalTooShort() {
+	stream, err := s.client.WatchDeployment(s.context("authorization", "Bearer s3cr3t"), &statuspb.WatchDeploymentRequest{
+		Service:  "billing_api",
+		Image:    "billing/api:1.0.0",
+		Interval: durationpb.New(time.Millisecond),
+	})
+	s.Require().NoError(err)
+
+	_, err = stream.Recv()
+	s.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func (s *GRPCTestSuite) Test_RateLimit_ReturnResourceExhausted() {
+	s.production.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := &Server{
+		Service:       s.production,
+		Authenticator: NewTokenAuthenticator([]Credential{{"ci", "t0k3n", []string{"stack:billing"}}}),
+		RateLimiter:   NewRateLimiter(0.5, 1),
+	}
+	grpcServer := server.GRPCServer()
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	s.Require().NoError(err)
+	defer conn.Close()
+	client := statuspb.NewStatusClient(conn)
+
+	_, err = client.GetServiceStatus(s.context("authorization", "Bearer t0k3n"), &statuspb.ServiceStatusRequest{Service: "billing_api"})
+	s.NoError(err)
+
+	_, err = client.GetServiceStatus(s.context("authorization", "Bearer t0k3n"), &statuspb.ServiceStatusRequest{Service: "billing_api"})
+	s.Equal(codes.ResourceExhausted, status.Code(err))
+	s.Equal("Too many requests, retry in 2 second(s).", status.Convert(err).Message())
+}
+
+func (s *GRPCTestSuite) Test_Audit_RecordsCalls() {
+	s.production.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+
+	dir, err := ioutil.TempDir("", "audit")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+
+	log, err := audit.Open(filepath.Join(dir, "audit.log"))
+	s.Require().NoError(err)
+	defer log.Close()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := &Server{
+		Service:       s.production,
+		Authenticator: NewTokenAuthenticator([]Credential{{"ci", "t0k3n", []string{"stack:billing"}}}),
+		Audit:         log,
+	}
+	grpcServer := server.GRPCServer()
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	s.Require().NoError(err)
+	defer conn.Close()
+	client := statuspb.NewStatusClient(conn)
+
+	_, err = client.GetServiceStatus(s.context("authorization", "Bearer t0k3n"), &statuspb.ServiceStatusRequest{Service: "billing_api"})
+	s.NoError(err)
+	_, err = client.GetStackStatus(s.context("authorization", "Bearer t0k3n"), &statuspb.StackStatusRequest{Stack: "prod"})
+	s.Equal(codes.PermissionDenied, status.Code(err))
+	_, err = client.GetServiceStatus(s.context(), &statuspb.ServiceStatusRequest{Service: "billing_api"})
+	s.Equal(codes.Unauthenticated, status.Code(err))
+
+	entries := log.Recent(audit.Query{})
+	s.Require().Len(entries, 3)
+	s.Equal([]string{"", "gRPC", "/dockerswarmservicestatus.v1.Status/GetServiceStatus", "billing_api", "Unauthenticated", "unauthenticated"}, []string{entries[0].Identity, entries[0].Method, entries[0].Endpoint, entries[0].Service, entries[0].Code, entries[0].Outcome})
+	s.Equal([]string{"ci", "gRPC", "/dockerswarmservicestatus.v1.Status/GetStackStatus", "prod", "PermissionDenied", "denied"}, []string{entries[1].Identity, entries[1].Method, entries[1].Endpoint, entries[1].Stack, entries[1].Code, entries[1].Outcome})
+	s.Equal([]string{"ci", "gRPC", "/dockerswarmservicestatus.v1.Status/GetServiceStatus", "billing_api", "OK", "allowed"}, []string{entries[2].Identity, entries[2].Method, entries[2].Endpoint, entries[2].Service, entries[2].Code, entries[2].Outcome})
+}
+
+// dial serves the gRPC server of server on an in-memory listener and returns a client together with the function
+// stopping both
+func (s *GRPCTestSuite) dial(server *Server) (statuspb.StatusClient, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.GRPCServer()
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	s.Require().NoError(err)
+
+	return statuspb.NewStatusClient(conn), func() {
+		conn.Close()
+		grpcServer.Stop()
+	}
+}
+
+// contextualService records the contexts its Docker API calls are made with
+type contextualService struct {
+	*ServiceMock
+	contexts chan context.Context
+}
+
+func (c *contextualService) WithContext(ctx context.Context) service.Services {
+	select {
+	case c.contexts <- ctx:
+	default:
+	}
+
+	return c.ServiceMock
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the ID of a request, it is assigned by the server unless the client sent a valid one
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the longest request ID propagated from a client
+const maxRequestIDLength = 128
+
+// requestEntry is the JSON line logged for every request
+type requestEntry struct {
+	Time        string  `json:"time"`
+	RequestID   string  `json:"request_id"`
+	TraceID     string  `json:"trace_id,omitempty"`
+	Method      string  `json:"method"`
+	Path        string  `json:"path"`
+	Route       string  `json:"route,omitempty"`
+	Cluster     string  `json:"cluster,omitempty"`
+	Service     string  `json:"service,omitempty"`
+	Stack       string  `json:"stack,omitempty"`
+	Status      int     `json:"status"`
+	LatencyMS   float64 `json:"latency_ms"`
+	DockerCalls int64   `json:"docker_calls"`
+	Error       string  `json:"error,omitempty"`
+
+	calls service.CallCounter
+	mutex sync.Mutex
+}
+
+type requestEntryKey struct{}
+
+// statusRecorder remembers the status code written to the client
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// logRequests assigns every request an ID, or propagates the X-Request-ID sent by the client, returns it in the
+// X-Request-ID response header and writes one JSON line per request to the access log
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		entry := &requestEntry{
+			Time:      start.UTC().Format(time.RFC3339Nano),
+			RequestID: r.Header.Get(RequestIDHeader),
+			Method:    r.Method,
+			Path:      r.URL.Path,
+		}
+		if !validRequestID(entry.RequestID) {
+			entry.RequestID = newRequestID()
+		}
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(attribute.String("http.request.id", entry.RequestID))
+		if span.SpanContext().HasTraceID() {
+			entry.TraceID = span.SpanContext().TraceID().String()
+		}
+
+		w.Header().Set(RequestIDHeader, entry.RequestID)
+		recorder := &statusRecorder{ResponseWriter: w}
+		ctx := context.WithValue(r.Context(), requestEntryKey{}, entry)
+		ctx = service.ContextWithCallCounter(ctx, &entry.calls)
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		if s.AccessLog == nil {
+			return
+		}
+
+		entry.mutex.Lock()
+		defer entry.mutex.Unlock()
+
+		entry.Status = recorder.status
+		if entry.Status == 0 {
+			entry.Status = http.StatusOK
+		}
+		entry.LatencyMS = float64(time.Since(start)) / float64(time.Millisecond)
+		entry.DockerCalls = entry.calls.Calls()
+
+		line, err := json.Marshal(entry)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		s.AccessLog.Write(append(line, '\n'))
+	})
+}
+
+// recordRoute records the route matched by the request together with the cluster, service and stack it names in
+// the log entry and the span of the request
+func recordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		route := ""
+		if current := mux.CurrentRoute(r); current != nil {
+			route, _ = current.GetPathTemplate()
+		}
+		cluster := vars["cluster"]
+		if cluster == "" {
+			cluster = r.Header.Get(ClusterHeader)
+		}
+
+		if entry := requestEntryFromContext(r.Context()); entry != nil {
+			entry.mutex.Lock()
+			entry.Route = route
+			entry.Cluster = cluster
+			entry.Service = vars["service"]
+			entry.Stack = vars["stack"]
+			entry.mutex.Unlock()
+		}
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(attribute.String("http.route", route))
+		for key, value := range map[string]string{"swarm.cluster": cluster, "swarm.service": vars["service"], "swarm.stack": vars["stack"]} {
+			if value != "" {
+				span.SetAttributes(attribute.String(key, value))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func requestEntryFromContext(ctx context.Context) *requestEntry {
+	entry, _ := ctx.Value(requestEntryKey{}).(*requestEntry)
+	return entry
+}
+
+// requestID returns the X-Request-ID of the request, it is empty when the request was not logged
+func requestID(r *http.Request) string {
+	if entry := requestEntryFromContext(r.Context()); entry != nil {
+		return entry.RequestID
+	}
+
+	return ""
+}
+
+// logError records err in the log entry of the request, or logs it on its own when the request is not logged
+func logError(r *http.Request, err error) {
+	entry := requestEntryFromContext(r.Context())
+	if entry == nil {
+		log.Println(err)
+		return
+	}
+
+	entry.mutex.Lock()
+	entry.Error = err.Error()
+	entry.mutex.Unlock()
+}
+
+// withRequestContext returns a view of svc making its Docker API calls with the context of the request, so they
+// are traced as children of the request span and counted in its log entry
+func withRequestContext(r *http.Request, svc service.Services) service.Services {
+	return withContext(r.Context(), svc)
+}
+
+// withContext returns a view of svc making its Docker API calls with ctx
+func withContext(ctx context.Context, svc service.Services) service.Services {
+	if contextual, ok := svc.(interface {
+		WithContext(ctx context.Context) service.Services
+	}); ok {
+		return contextual.WithContext(ctx)
+	}
+
+	return svc
+}
+
+// validRequestID reports whether a request ID sent by a client is short and printable enough to be propagated
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newRequestID returns a random request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+)
+
+func (s *ServerTestSuite) serveLogged(server *Server, target string, requestID string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	headers := map[string]string{}
+	if requestID != "" {
+		headers[RequestIDHeader] = requestID
+	}
+
+	return s.serveLoggedWith(server, target, headers)
+}
+
+func (s *ServerTestSuite) serveLoggedWith(server *Server, target string, headers map[string]string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	accessLog := &bytes.Buffer{}
+	server.AccessLog = accessLog
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", target, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	server.Handler().ServeHTTP(rec, req)
+
+	s.Equal(1, strings.Count(accessLog.String(), "\n"), "one line is logged per request")
+	entry := map[string]interface{}{}
+	s.NoError(json.Unmarshal(accessLog.Bytes(), &entry))
+
+	return rec, entry
+}
+
+func (s *ServerTestSuite) Test_Handler_LogsRequests() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+
+	rec, entry := s.serveLogged(&Server{Service: serviceMock}, "/v1/docker-swarm-service-status/service-status/billing_api", "")
+
+	s.Equal(200, rec.Code)
+	s.Regexp("^[0-9a-f]{32}$", rec.Header().Get(RequestIDHeader))
+	s.Equal(rec.Header().Get(RequestIDHeader), entry["request_id"])
+	s.Equal("GET", entry["method"])
+	s.Equal("/v1/docker-swarm-service-status/service-status/billing_api", entry["path"])
+	s.Equal("/v1/docker-swarm-service-status/service-status/{service}", entry["route"])
+	s.Equal("billing_api", entry["service"])
+	s.Equal(float64(200), entry["status"])
+	s.Equal(float64(0), entry["docker_calls"])
+	s.Contains(entry, "latency_ms")
+	s.Contains(entry, "time")
+	s.NotContains(entry, "error")
+}
+
+func (s *ServerTestSuite) Test_Handler_PropagatesRequestID() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+
+	rec, entry := s.serveLogged(&Server{Service: serviceMock}, "/v1/docker-swarm-service-status/service-status/billing_api", "jenkins-1842-3")
+
+	s.Equal("jenkins-1842-3", rec.Header().Get(RequestIDHeader))
+	s.Equal("jenkins-1842-3", entry["request_id"])
+
+	for _, invalid := range []string{"with space", strings.Repeat("a", maxRequestIDLength+1)} {
+		rec, _ = s.serveLogged(&Server{Service: serviceMock}, "/v1/docker-swarm-service-status/service-status/billing_api", invalid)
+
+		s.Regexp("^[0-9a-f]{32}$", rec.Header().Get(RequestIDHeader), invalid)
+	}
+}
+
+func (s *ServerTestSuite) Test_Handler_ErrorsIncludeRequestID() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetStackStatus", "billing").Return(service.StackStatus{}, errors.New("Cannot connect to the Docker daemon"))
+
+	rec, entry := s.serveLogged(&Server{Service: serviceMock}, "/v1/docker-swarm-service-status/clusters/staging/info", "jenkins-1842-3")
+
+	s.Equal(404, rec.Code)
+	s.Equal(`{"error": "The staging cluster is not configured.", "request_id": "jenkins-1842-3"}`, rec.Body.String())
+	s.Equal("staging", entry["cluster"])
+	s.Equal(float64(404), entry["status"])
+
+	rec, entry = s.serveLogged(&Server{Service: serviceMock}, "/v1/docker-swarm-service-status/stack-status/billing?format=table", "jenkins-1842-4")
+
+	s.Equal(500, rec.Code)
+	s.Contains(rec.Body.String(), "REQUEST ID  jenkins-1842-4")
+	s.Equal("billing", entry["stack"])
+	s.Equal("Cannot connect to the Docker daemon", entry["error"])
+
+	rec, _ = s.serveLogged(&Server{Service: serviceMock}, V2Path+"/services?limit=0", "jenkins-1842-5")
+
+	s.Equal(400, rec.Code)
+	s.Contains(rec.Body.String(), `"RequestID":"jenkins-1842-5"`)
+}
+
+func (s *ServerTestSuite) Test_Handler_CountsDockerCalls() {
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer daemon.Close()
+
+	svc, err := service.NewServiceWithConfig(service.Config{Host: "tcp://" + strings.TrimPrefix(daemon.URL, "http://"), APIVersion: "v1.33"})
+	s.Require().NoError(err)
+
+	rec, entry := s.serveLogged(&Server{Service: svc}, "/v1/docker-swarm-service-status/stack-status/billing", "")
+
+	s.Equal(200, rec.Code)
+	s.Equal(float64(1), entry["docker_calls"])
+}
+
+func (s *ServerTestSuite) Test_Handler_WithoutAccessLog() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/service-status/billing_api", nil)
+	(&Server{Service: serviceMock}).Handler().ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.NotEmpty(rec.Header().Get(RequestIDHeader))
+}
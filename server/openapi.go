@@ -0,0 +1,3950 @@
+package server
+
+import "net/http"
+
+// OpenAPIPath is where the OpenAPI document is served
+const OpenAPIPath = "/v1/docker-swarm-service-status/openapi.json"
+
+// OpenAPIHandler returns the OpenAPI 3 document describing every route of the server
+func (s *Server) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(OpenAPIDocument))
+}
+
+// OpenAPIDocument describes the routes, parameters and schemas of the API. The server tests validate the
+// handler responses against it, update it together with the handlers.
+const OpenAPIDocument = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Docker Swarm Service Status",
+    "version": "1.0.0",
+    "description": "Reports the deployment state of Docker Swarm services.",
+    "license": {
+      "name": "MIT"
+    }
+  },
+  "servers": [
+    {
+      "url": "/"
+    }
+  ],
+  "security": [
+    {
+      "bearer": []
+    },
+    {
+      "hmac": []
+    },
+    {}
+  ],
+  "paths": {
+    "/v1/docker-swarm-service-status/audit": {
+      "get": {
+        "operationId": "listAuditEntries",
+        "summary": "Most recent audit entries",
+        "tags": [
+          "audit"
+        ],
+        "parameters": [
+          {
+            "name": "identity",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "string"
+            },
+            "description": "Only the entries of this credential."
+          },
+          {
+            "name": "service",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "string"
+            },
+            "description": "Only the entries of this service."
+          },
+          {
+            "name": "stack",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "string"
+            },
+            "description": "Only the entries of this stack."
+          },
+          {
+            "name": "outcome",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "string",
+              "enum": [
+                "allowed",
+                "unauthenticated",
+                "denied",
+                "rate-limited",
+                "failed"
+              ]
+            },
+            "description": "Only the entries with this outcome."
+          },
+          {
+            "name": "since",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "string",
+              "format": "date-time"
+            },
+            "description": "Only the entries recorded at or after this time."
+          },
+          {
+            "name": "limit",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "integer",
+              "minimum": 1,
+              "maximum": 1000,
+              "default": 100
+            },
+            "description": "Maximum number of entries."
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Who queried what, most recent first.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/AuditEntry"
+                  }
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "description": "The audit log is not configured.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Error"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/badge/service/{service}": {
+      "get": {
+        "operationId": "getServiceBadge",
+        "summary": "SVG badge of a service",
+        "tags": [
+          "badge"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/label"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The badge, colour-coded by verdict.",
+            "headers": {
+              "Cache-Control": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "content": {
+              "image/svg+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "304": {
+            "description": "The badge did not change since the If-None-Match ETag."
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "description": "The Docker daemon could not be queried, the badge reads unavailable.",
+            "content": {
+              "image/svg+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/badge/stack/{stack}": {
+      "get": {
+        "operationId": "getStackBadge",
+        "summary": "SVG badge of a stack",
+        "tags": [
+          "badge"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/stack"
+          },
+          {
+            "$ref": "#/components/parameters/label"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The badge, colour-coded by verdict.",
+            "headers": {
+              "Cache-Control": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "content": {
+              "image/svg+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "304": {
+            "description": "The badge did not change since the If-None-Match ETag."
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "description": "The Docker daemon could not be queried, the badge reads unavailable.",
+            "content": {
+              "image/svg+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/batch/deployment-status": {
+      "post": {
+        "operationId": "getBatchDeploymentStatus",
+        "summary": "State of several services and whether their images were deployed",
+        "tags": [
+          "status"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/BatchRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "The state of every deployment and the worst of their verdicts.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/BatchStatus"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/clusters": {
+      "get": {
+        "operationId": "listClusters",
+        "summary": "Names of the configured clusters",
+        "tags": [
+          "cluster"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The sorted cluster names.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "type": "string"
+                  }
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/clusters/{cluster}/badge/service/{service}": {
+      "get": {
+        "operationId": "getServiceBadgeInCluster",
+        "summary": "SVG badge of a service",
+        "tags": [
+          "badge"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/label"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The badge, colour-coded by verdict.",
+            "headers": {
+              "Cache-Control": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "content": {
+              "image/svg+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "304": {
+            "description": "The badge did not change since the If-None-Match ETag."
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "description": "The Docker daemon could not be queried, the badge reads unavailable.",
+            "content": {
+              "image/svg+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/clusters/{cluster}/badge/stack/{stack}": {
+      "get": {
+        "operationId": "getStackBadgeInCluster",
+        "summary": "SVG badge of a stack",
+        "tags": [
+          "badge"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster"
+          },
+          {
+            "$ref": "#/components/parameters/stack"
+          },
+          {
+            "$ref": "#/components/parameters/label"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The badge, colour-coded by verdict.",
+            "headers": {
+              "Cache-Control": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "content": {
+              "image/svg+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "304": {
+            "description": "The badge did not change since the If-None-Match ETag."
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "description": "The Docker daemon could not be queried, the badge reads unavailable.",
+            "content": {
+              "image/svg+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/clusters/{cluster}/batch/deployment-status": {
+      "post": {
+        "operationId": "getBatchDeploymentStatusInCluster",
+        "summary": "State of several services and whether their images were deployed",
+        "tags": [
+          "status"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/BatchRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "The state of every deployment and the worst of their verdicts.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/BatchStatus"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/clusters/{cluster}/deployment-status/{service}/{image}": {
+      "get": {
+        "operationId": "getDeploymentStatusInCluster",
+        "summary": "State of a service and whether the image was deployed",
+        "tags": [
+          "status"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/image"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          },
+          {
+            "$ref": "#/components/parameters/if-none-match"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The service state, Err is set when the image was not deployed.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ServiceStatus"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "application/junit+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "headers": {
+              "Cache-Control": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                },
+                "description": "Changes with the version index of the service and the state of its tasks."
+              }
+            }
+          },
+          "304": {
+            "description": "The status did not change since the If-None-Match ETag.",
+            "headers": {
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/clusters/{cluster}/info": {
+      "get": {
+        "operationId": "getInfoInCluster",
+        "summary": "Negotiated Docker API version and supported features",
+        "tags": [
+          "cluster"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The Docker API version and features.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Info"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/clusters/{cluster}/service-status/{service}": {
+      "get": {
+        "operationId": "getServiceStatusInCluster",
+        "summary": "Current state of a service",
+        "tags": [
+          "status"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          },
+          {
+            "$ref": "#/components/parameters/if-none-match"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The service state, Err is set when the service was not found.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ServiceStatus"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "application/junit+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "headers": {
+              "Cache-Control": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                },
+                "description": "Changes with the version index of the service and the state of its tasks."
+              }
+            }
+          },
+          "304": {
+            "description": "The status did not change since the If-None-Match ETag.",
+            "headers": {
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/clusters/{cluster}/stack-status/{stack}": {
+      "get": {
+        "operationId": "getStackStatusInCluster",
+        "summary": "Current state of every service of a stack",
+        "tags": [
+          "status"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster"
+          },
+          {
+            "$ref": "#/components/parameters/stack"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The state of the stack services.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/StackStatus"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "application/junit+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/cross-cluster/service-status/{service}": {
+      "get": {
+        "operationId": "getCrossClusterServiceStatus",
+        "summary": "State of a service in every cluster",
+        "tags": [
+          "status"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The state of the service in each cluster, sorted by cluster name.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/ClusterStatus"
+                  }
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/dashboard": {
+      "get": {
+        "operationId": "getDashboard",
+        "summary": "HTML page listing every service",
+        "tags": [
+          "dashboard"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/refresh"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The dashboard.",
+            "content": {
+              "text/html": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "description": "The Docker daemon could not be queried.",
+            "content": {
+              "text/html": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/dashboard/clusters/{cluster}": {
+      "get": {
+        "operationId": "getDashboardInCluster",
+        "summary": "HTML page listing every service",
+        "tags": [
+          "dashboard"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster"
+          },
+          {
+            "$ref": "#/components/parameters/refresh"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The dashboard.",
+            "content": {
+              "text/html": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "description": "The Docker daemon could not be queried.",
+            "content": {
+              "text/html": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/dashboard/clusters/{cluster}/services/{service}": {
+      "get": {
+        "operationId": "getDashboardServiceInCluster",
+        "summary": "HTML page with the tasks of a service",
+        "tags": [
+          "dashboard"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/refresh"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The service page.",
+            "content": {
+              "text/html": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "description": "The Docker daemon could not be queried.",
+            "content": {
+              "text/html": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/dashboard/services/{service}": {
+      "get": {
+        "operationId": "getDashboardService",
+        "summary": "HTML page with the tasks of a service",
+        "tags": [
+          "dashboard"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/refresh"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The service page.",
+            "content": {
+              "text/html": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "description": "The Docker daemon could not be queried.",
+            "content": {
+              "text/html": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/deployment-status/{service}/{image}": {
+      "get": {
+        "operationId": "getDeploymentStatus",
+        "summary": "State of a service and whether the image was deployed",
+        "tags": [
+          "status"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/image"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          },
+          {
+            "$ref": "#/components/parameters/if-none-match"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The service state, Err is set when the image was not deployed.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ServiceStatus"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "application/junit+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "headers": {
+              "Cache-Control": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                },
+                "description": "Changes with the version index of the service and the state of its tasks."
+              }
+            }
+          },
+          "304": {
+            "description": "The status did not change since the If-None-Match ETag.",
+            "headers": {
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/health": {
+      "get": {
+        "operationId": "getHealth",
+        "summary": "Health check",
+        "tags": [
+          "probe"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The server is running.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Health"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          }
+        },
+        "security": []
+      }
+    },
+    "/v1/docker-swarm-service-status/info": {
+      "get": {
+        "operationId": "getInfo",
+        "summary": "Negotiated Docker API version and supported features",
+        "tags": [
+          "cluster"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The Docker API version and features.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Info"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/openapi.json": {
+      "get": {
+        "operationId": "getOpenAPI",
+        "summary": "This document",
+        "tags": [
+          "meta"
+        ],
+        "parameters": [],
+        "responses": {
+          "200": {
+            "description": "The OpenAPI document.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object"
+                }
+              }
+            }
+          }
+        },
+        "security": []
+      }
+    },
+    "/v1/docker-swarm-service-status/ready": {
+      "get": {
+        "operationId": "getReadiness",
+        "summary": "Readiness check",
+        "tags": [
+          "probe"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The Docker daemon is reachable and the node is an active swarm manager.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Readiness"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "503": {
+            "description": "The Docker daemon is unreachable or the node is not an active swarm manager.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Readiness"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          }
+        },
+        "security": []
+      }
+    },
+    "/v1/docker-swarm-service-status/service-status/{service}": {
+      "get": {
+        "operationId": "getServiceStatus",
+        "summary": "Current state of a service",
+        "tags": [
+          "status"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          },
+          {
+            "$ref": "#/components/parameters/if-none-match"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The service state, Err is set when the service was not found.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ServiceStatus"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "application/junit+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "headers": {
+              "Cache-Control": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                },
+                "description": "Changes with the version index of the service and the state of its tasks."
+              }
+            }
+          },
+          "304": {
+            "description": "The status did not change since the If-None-Match ETag.",
+            "headers": {
+              "ETag": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/stack-status/{stack}": {
+      "get": {
+        "operationId": "getStackStatus",
+        "summary": "Current state of every service of a stack",
+        "tags": [
+          "status"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/stack"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The state of the stack services.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/StackStatus"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "application/junit+xml": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/ClusterNotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      }
+    },
+    "/v1/docker-swarm-service-status/webhooks/deliveries": {
+      "get": {
+        "operationId": "listWebhookDeliveries",
+        "summary": "Most recent webhook deliveries",
+        "tags": [
+          "webhook"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The deliveries of the services the caller may query, most recent first.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/Delivery"
+                  }
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/Forbidden"
+          },
+          "406": {
+            "$ref": "#/components/responses/NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/TooManyRequests"
+          }
+        }
+      }
+    },
+    "/v2/docker-swarm-service-status/deployments/{service}": {
+      "get": {
+        "operationId": "getDeployment",
+        "summary": "Rollout of an image to a service",
+        "tags": [
+          "v2"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/v2-image"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The deployment, Err is set when the image was not deployed.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/DeploymentResource"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/V2BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/V2Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/V2Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/V2NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/V2NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/V2TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/V2InternalError"
+          }
+        }
+      }
+    },
+    "/v2/docker-swarm-service-status/nodes": {
+      "get": {
+        "operationId": "listNodes",
+        "summary": "Page of the swarm nodes, requires the * scope",
+        "tags": [
+          "v2"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/v2-limit"
+          },
+          {
+            "$ref": "#/components/parameters/v2-offset"
+          },
+          {
+            "$ref": "#/components/parameters/v2-role"
+          },
+          {
+            "$ref": "#/components/parameters/v2-availability"
+          },
+          {
+            "$ref": "#/components/parameters/v2-node-state"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The nodes sorted by hostname.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/NodeList"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/V2BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/V2Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/V2Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/V2NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/V2NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/V2TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/V2InternalError"
+          }
+        }
+      }
+    },
+    "/v2/docker-swarm-service-status/nodes/{node}": {
+      "get": {
+        "operationId": "getNode",
+        "summary": "A swarm node by ID or hostname, requires the * scope",
+        "tags": [
+          "v2"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/v2-node"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The node.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/NodeResource"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/V2BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/V2Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/V2Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/V2NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/V2NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/V2TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/V2InternalError"
+          }
+        }
+      }
+    },
+    "/v2/docker-swarm-service-status/services": {
+      "get": {
+        "operationId": "listServices",
+        "summary": "Page of services",
+        "tags": [
+          "v2"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/v2-limit"
+          },
+          {
+            "$ref": "#/components/parameters/v2-offset"
+          },
+          {
+            "$ref": "#/components/parameters/v2-name"
+          },
+          {
+            "$ref": "#/components/parameters/v2-stack-filter"
+          },
+          {
+            "$ref": "#/components/parameters/v2-label-filter"
+          },
+          {
+            "$ref": "#/components/parameters/v2-verdict"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The services sorted by name.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ServiceList"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/V2BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/V2Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/V2Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/V2NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/V2NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/V2TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/V2InternalError"
+          }
+        }
+      }
+    },
+    "/v2/docker-swarm-service-status/services/{service}": {
+      "get": {
+        "operationId": "getService",
+        "summary": "A service with its replicas and verdict",
+        "tags": [
+          "v2"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The service.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ServiceResource"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/V2BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/V2Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/V2Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/V2NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/V2NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/V2TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/V2InternalError"
+          }
+        }
+      }
+    },
+    "/v2/docker-swarm-service-status/services/{service}/tasks": {
+      "get": {
+        "operationId": "listServiceTasks",
+        "summary": "Page of the tasks of a service",
+        "tags": [
+          "v2"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/service"
+          },
+          {
+            "$ref": "#/components/parameters/v2-limit"
+          },
+          {
+            "$ref": "#/components/parameters/v2-offset"
+          },
+          {
+            "$ref": "#/components/parameters/v2-state"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The tasks sorted by slot, the most recent first.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TaskList"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/V2BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/V2Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/V2Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/V2NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/V2NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/V2TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/V2InternalError"
+          }
+        }
+      }
+    },
+    "/v2/docker-swarm-service-status/stacks": {
+      "get": {
+        "operationId": "listStacks",
+        "summary": "Page of the stacks deployed with docker stack deploy",
+        "tags": [
+          "v2"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/v2-limit"
+          },
+          {
+            "$ref": "#/components/parameters/v2-offset"
+          },
+          {
+            "$ref": "#/components/parameters/v2-name"
+          },
+          {
+            "$ref": "#/components/parameters/v2-verdict"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The stacks sorted by name.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/StackList"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/V2BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/V2Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/V2Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/V2NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/V2NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/V2TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/V2InternalError"
+          }
+        }
+      }
+    },
+    "/v2/docker-swarm-service-status/stacks/{stack}": {
+      "get": {
+        "operationId": "getStack",
+        "summary": "A stack with its services and verdict",
+        "tags": [
+          "v2"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/stack"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The stack.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/StackResource"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/V2BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/V2Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/V2Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/V2NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/V2NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/V2TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/V2InternalError"
+          }
+        }
+      }
+    },
+    "/v2/docker-swarm-service-status/tasks": {
+      "get": {
+        "operationId": "listTasks",
+        "summary": "Page of tasks",
+        "tags": [
+          "v2"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/v2-limit"
+          },
+          {
+            "$ref": "#/components/parameters/v2-offset"
+          },
+          {
+            "$ref": "#/components/parameters/v2-service-filter"
+          },
+          {
+            "$ref": "#/components/parameters/v2-node-filter"
+          },
+          {
+            "$ref": "#/components/parameters/v2-state"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The tasks sorted by service and slot, the most recent first.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TaskList"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/V2BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/V2Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/V2Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/V2NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/V2NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/V2TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/V2InternalError"
+          }
+        }
+      }
+    },
+    "/v2/docker-swarm-service-status/tasks/{task}": {
+      "get": {
+        "operationId": "getTask",
+        "summary": "A task",
+        "tags": [
+          "v2"
+        ],
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/cluster-header"
+          },
+          {
+            "$ref": "#/components/parameters/v2-task"
+          },
+          {
+            "$ref": "#/components/parameters/format"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The task.",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TaskResource"
+                }
+              },
+              "application/yaml": {
+                "schema": {
+                  "type": "string"
+                }
+              },
+              "text/plain": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/V2BadRequest"
+          },
+          "401": {
+            "$ref": "#/components/responses/V2Unauthorized"
+          },
+          "403": {
+            "$ref": "#/components/responses/V2Forbidden"
+          },
+          "404": {
+            "$ref": "#/components/responses/V2NotFound"
+          },
+          "406": {
+            "$ref": "#/components/responses/V2NotAcceptable"
+          },
+          "429": {
+            "$ref": "#/components/responses/V2TooManyRequests"
+          },
+          "500": {
+            "$ref": "#/components/responses/V2InternalError"
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "service": {
+        "name": "service",
+        "in": "path",
+        "required": true,
+        "schema": {
+          "type": "string"
+        },
+        "description": "Name of the service."
+      },
+      "image": {
+        "name": "image",
+        "in": "path",
+        "required": true,
+        "schema": {
+          "type": "string",
+          "format": "byte"
+        },
+        "description": "URL-safe base64 encoded image, e.g. acme/web:1.0.0."
+      },
+      "stack": {
+        "name": "stack",
+        "in": "path",
+        "required": true,
+        "schema": {
+          "type": "string"
+        },
+        "description": "Name of the stack given to docker stack deploy."
+      },
+      "cluster": {
+        "name": "cluster",
+        "in": "path",
+        "required": true,
+        "schema": {
+          "type": "string"
+        },
+        "description": "Name of a configured cluster."
+      },
+      "cluster-header": {
+        "name": "X-Swarm-Cluster",
+        "in": "header",
+        "required": false,
+        "schema": {
+          "type": "string"
+        },
+        "description": "Name of a configured cluster, the default cluster when absent."
+      },
+      "format": {
+        "name": "format",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "json",
+            "yaml",
+            "table",
+            "junit"
+          ]
+        },
+        "description": "Response format, overrides the Accept header."
+      },
+      "if-none-match": {
+        "name": "If-None-Match",
+        "in": "header",
+        "required": false,
+        "schema": {
+          "type": "string"
+        },
+        "description": "ETag of a previous response, answered with 304 when the status did not change."
+      },
+      "label": {
+        "name": "label",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        },
+        "description": "Replaces the left part of the badge."
+      },
+      "v2-limit": {
+        "name": "limit",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "integer",
+          "minimum": 1,
+          "maximum": 500,
+          "default": 50
+        },
+        "description": "Page size."
+      },
+      "v2-offset": {
+        "name": "offset",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "integer",
+          "minimum": 0,
+          "default": 0
+        },
+        "description": "Number of items skipped."
+      },
+      "v2-name": {
+        "name": "name",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        },
+        "description": "Glob matched against the name, e.g. billing-*."
+      },
+      "v2-stack-filter": {
+        "name": "stack",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        },
+        "description": "Only the services of this stack."
+      },
+      "v2-label-filter": {
+        "name": "label",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "explode": true,
+        "description": "Only the services with this label, as key or key=value. Repeat to require several labels."
+      },
+      "v2-verdict": {
+        "name": "verdict",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "succeeded",
+            "in-progress",
+            "failed",
+            "rolled-back",
+            "not-found",
+            "unknown"
+          ]
+        },
+        "description": "Only the items with this verdict."
+      },
+      "v2-state": {
+        "name": "state",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "$ref": "#/components/schemas/TaskState"
+        },
+        "description": "Only the tasks in this state."
+      },
+      "v2-service-filter": {
+        "name": "service",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        },
+        "description": "Only the tasks of this service, by name or ID."
+      },
+      "v2-node-filter": {
+        "name": "node",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        },
+        "description": "Only the tasks of this node, by ID or hostname."
+      },
+      "v2-role": {
+        "name": "role",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "manager",
+            "worker"
+          ]
+        },
+        "description": "Only the nodes with this role."
+      },
+      "v2-availability": {
+        "name": "availability",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "active",
+            "pause",
+            "drain"
+          ]
+        },
+        "description": "Only the nodes with this availability."
+      },
+      "v2-node-state": {
+        "name": "state",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string",
+          "enum": [
+            "unknown",
+            "down",
+            "ready",
+            "disconnected"
+          ]
+        },
+        "description": "Only the nodes in this state."
+      },
+      "v2-image": {
+        "name": "image",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "string"
+        },
+        "description": "Image expected to be deployed, defaults to the image of the service spec."
+      },
+      "v2-task": {
+        "name": "task",
+        "in": "path",
+        "required": true,
+        "schema": {
+          "type": "string"
+        },
+        "description": "ID of the task."
+      },
+      "v2-node": {
+        "name": "node",
+        "in": "path",
+        "required": true,
+        "schema": {
+          "type": "string"
+        },
+        "description": "ID or hostname of the node."
+      },
+      "refresh": {
+        "name": "refresh",
+        "in": "query",
+        "required": false,
+        "schema": {
+          "type": "integer",
+          "minimum": 0,
+          "default": 10
+        },
+        "description": "Seconds between two reloads of the page, 0 disables the reload."
+      }
+    },
+    "responses": {
+      "BadRequest": {
+        "description": "The request parameters are invalid.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/Error"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "Unauthorized": {
+        "description": "The request carries no valid credentials.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/Error"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "Forbidden": {
+        "description": "The credentials may not query the service or stack.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/Error"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "NotAcceptable": {
+        "description": "The requested format is not supported.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/Error"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "ClusterNotFound": {
+        "description": "The cluster is not configured.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/Error"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "TooManyRequests": {
+        "description": "The client exceeded its rate limit.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/Error"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        },
+        "headers": {
+          "Retry-After": {
+            "schema": {
+              "type": "integer"
+            },
+            "description": "Seconds until the client may send a request again."
+          }
+        }
+      },
+      "InternalError": {
+        "description": "The Docker daemon could not be queried.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/Error"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "V2BadRequest": {
+        "description": "The request parameters are invalid.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/ErrorEnvelope"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "V2Unauthorized": {
+        "description": "The request carries no valid credentials.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/ErrorEnvelope"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "V2Forbidden": {
+        "description": "The credentials may not query the resource.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/ErrorEnvelope"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "V2NotFound": {
+        "description": "The resource or the cluster was not found.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/ErrorEnvelope"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "V2NotAcceptable": {
+        "description": "The requested format is not supported.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/ErrorEnvelope"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "V2TooManyRequests": {
+        "description": "The client exceeded its rate limit.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/ErrorEnvelope"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        },
+        "headers": {
+          "Retry-After": {
+            "schema": {
+              "type": "integer"
+            },
+            "description": "Seconds until the client may send a request again."
+          }
+        }
+      },
+      "V2InternalError": {
+        "description": "The Docker daemon could not be queried.",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/ErrorEnvelope"
+            }
+          },
+          "application/yaml": {
+            "schema": {
+              "type": "string"
+            }
+          },
+          "text/plain": {
+            "schema": {
+              "type": "string"
+            }
+          }
+        }
+      }
+    },
+    "schemas": {
+      "ServiceStatus": {
+        "type": "object",
+        "required": [
+          "Name"
+        ],
+        "properties": {
+          "ID": {
+            "type": "string",
+            "description": "Swarm ID of the service, absent when the service was not found."
+          },
+          "Name": {
+            "type": "string"
+          },
+          "Err": {
+            "type": "string",
+            "description": "Why the service is not healthy or was not found."
+          },
+          "TaskStatus": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/TaskStatus"
+            }
+          },
+          "Replicas": {
+            "type": "integer",
+            "minimum": 0,
+            "description": "Desired replicas, absent for global services."
+          },
+          "RunningReplicas": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "FailedReplicas": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "UpdateStatus": {
+            "$ref": "#/components/schemas/UpdateStatus"
+          },
+          "Version": {
+            "type": "integer",
+            "minimum": 0,
+            "description": "Version index of the service, bumped on every update."
+          },
+          "Image": {
+            "type": "string",
+            "description": "Image of the service spec without its digest."
+          }
+        },
+        "additionalProperties": false
+      },
+      "TaskStatus": {
+        "type": "object",
+        "properties": {
+          "TaskID": {
+            "type": "string"
+          },
+          "Slot": {
+            "type": "integer",
+            "minimum": 0,
+            "description": "Slot of replicated tasks, absent for global services."
+          },
+          "NodeID": {
+            "type": "string"
+          },
+          "Timestamp": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "DesiredState": {
+            "$ref": "#/components/schemas/TaskState"
+          },
+          "State": {
+            "$ref": "#/components/schemas/TaskState"
+          },
+          "Message": {
+            "type": "string"
+          },
+          "Err": {
+            "type": "string"
+          },
+          "Image": {
+            "type": "string"
+          }
+        },
+        "additionalProperties": false
+      },
+      "TaskState": {
+        "type": "string",
+        "enum": [
+          "new",
+          "allocated",
+          "pending",
+          "assigned",
+          "accepted",
+          "preparing",
+          "ready",
+          "starting",
+          "running",
+          "complete",
+          "shutdown",
+          "failed",
+          "rejected",
+          "remove",
+          "orphaned"
+        ]
+      },
+      "UpdateStatus": {
+        "type": "object",
+        "properties": {
+          "State": {
+            "type": "string",
+            "enum": [
+              "updating",
+              "paused",
+              "completed",
+              "rollback_started",
+              "rollback_paused",
+              "rollback_completed"
+            ]
+          },
+          "StartedAt": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "CompletedAt": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "Message": {
+            "type": "string"
+          }
+        },
+        "additionalProperties": false
+      },
+      "StackStatus": {
+        "type": "object",
+        "required": [
+          "Name"
+        ],
+        "properties": {
+          "Name": {
+            "type": "string"
+          },
+          "Err": {
+            "type": "string"
+          },
+          "Services": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/ServiceStatus"
+            }
+          }
+        },
+        "additionalProperties": false
+      },
+      "BatchRequest": {
+        "type": "object",
+        "required": [
+          "Deployments"
+        ],
+        "properties": {
+          "Deployments": {
+            "type": "array",
+            "minItems": 1,
+            "maxItems": 100,
+            "items": {
+              "$ref": "#/components/schemas/DeploymentCheck"
+            }
+          }
+        },
+        "additionalProperties": false
+      },
+      "DeploymentCheck": {
+        "type": "object",
+        "required": [
+          "Service",
+          "Image"
+        ],
+        "properties": {
+          "Service": {
+            "type": "string"
+          },
+          "Image": {
+            "type": "string",
+            "description": "Image expected to be deployed, e.g. acme/web:1.0.0."
+          }
+        },
+        "additionalProperties": false
+      },
+      "BatchStatus": {
+        "type": "object",
+        "required": [
+          "Verdict",
+          "Deployments"
+        ],
+        "properties": {
+          "Verdict": {
+            "$ref": "#/components/schemas/Verdict"
+          },
+          "Deployments": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/DeploymentResult"
+            }
+          }
+        },
+        "additionalProperties": false
+      },
+      "DeploymentResult": {
+        "type": "object",
+        "required": [
+          "Service",
+          "Image",
+          "Verdict"
+        ],
+        "properties": {
+          "Service": {
+            "type": "string"
+          },
+          "Image": {
+            "type": "string"
+          },
+          "Verdict": {
+            "$ref": "#/components/schemas/Verdict"
+          },
+          "Status": {
+            "$ref": "#/components/schemas/ServiceStatus"
+          },
+          "Err": {
+            "type": "string",
+            "description": "Why the status could not be read, the verdict of the deployment is then unknown."
+          }
+        },
+        "additionalProperties": false
+      },
+      "ClusterStatus": {
+        "type": "object",
+        "required": [
+          "Cluster"
+        ],
+        "properties": {
+          "Cluster": {
+            "type": "string"
+          },
+          "Status": {
+            "$ref": "#/components/schemas/ServiceStatus"
+          },
+          "Err": {
+            "type": "string",
+            "description": "Why the cluster could not be queried."
+          }
+        },
+        "additionalProperties": false
+      },
+      "Info": {
+        "type": "object",
+        "required": [
+          "APIVersion",
+          "Features"
+        ],
+        "properties": {
+          "APIVersion": {
+            "type": "string"
+          },
+          "ServerAPIVersion": {
+            "type": "string"
+          },
+          "ServerMinAPIVersion": {
+            "type": "string"
+          },
+          "ServerVersion": {
+            "type": "string"
+          },
+          "Features": {
+            "type": "array",
+            "nullable": true,
+            "items": {
+              "$ref": "#/components/schemas/Feature"
+            }
+          }
+        },
+        "additionalProperties": false
+      },
+      "Feature": {
+        "type": "object",
+        "required": [
+          "Name",
+          "Description",
+          "MinAPIVersion",
+          "Supported"
+        ],
+        "properties": {
+          "Name": {
+            "type": "string"
+          },
+          "Description": {
+            "type": "string"
+          },
+          "MinAPIVersion": {
+            "type": "string"
+          },
+          "Supported": {
+            "type": "boolean"
+          }
+        },
+        "additionalProperties": false
+      },
+      "Readiness": {
+        "type": "object",
+        "required": [
+          "Ready",
+          "Checks"
+        ],
+        "properties": {
+          "Ready": {
+            "type": "boolean"
+          },
+          "APIVersion": {
+            "type": "string"
+          },
+          "Latency": {
+            "type": "string"
+          },
+          "Checks": {
+            "type": "array",
+            "nullable": true,
+            "items": {
+              "$ref": "#/components/schemas/Check"
+            }
+          }
+        },
+        "additionalProperties": false
+      },
+      "Check": {
+        "type": "object",
+        "required": [
+          "Name",
+          "OK"
+        ],
+        "properties": {
+          "Name": {
+            "type": "string"
+          },
+          "OK": {
+            "type": "boolean"
+          },
+          "Message": {
+            "type": "string"
+          }
+        },
+        "additionalProperties": false
+      },
+      "Health": {
+        "type": "object",
+        "required": [
+          "Status"
+        ],
+        "properties": {
+          "Status": {
+            "type": "string"
+          }
+        },
+        "additionalProperties": false
+      },
+      "AuditEntry": {
+        "type": "object",
+        "required": [
+          "Timestamp",
+          "Method",
+          "Endpoint",
+          "Outcome"
+        ],
+        "properties": {
+          "Timestamp": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "Identity": {
+            "type": "string",
+            "description": "Name of the credential, absent when the request was not authenticated."
+          },
+          "ClientIP": {
+            "type": "string"
+          },
+          "RequestID": {
+            "type": "string"
+          },
+          "Method": {
+            "type": "string",
+            "description": "HTTP method, or gRPC for the calls of the gRPC API."
+          },
+          "Endpoint": {
+            "type": "string",
+            "description": "Route of the request or full name of the gRPC method."
+          },
+          "Cluster": {
+            "type": "string"
+          },
+          "Service": {
+            "type": "string",
+            "description": "Queried service, the services of a batch request are comma separated."
+          },
+          "Stack": {
+            "type": "string"
+          },
+          "Status": {
+            "type": "integer",
+            "description": "HTTP status code of the response."
+          },
+          "Code": {
+            "type": "string",
+            "description": "Status code of a gRPC call."
+          },
+          "Outcome": {
+            "type": "string",
+            "enum": [
+              "allowed",
+              "unauthenticated",
+              "denied",
+              "rate-limited",
+              "failed"
+            ]
+          }
+        },
+        "additionalProperties": false
+      },
+      "Delivery": {
+        "type": "object",
+        "required": [
+          "ID",
+          "Type",
+          "Cluster",
+          "Service",
+          "URL",
+          "Delivered",
+          "Attempts",
+          "Timestamp",
+          "Duration"
+        ],
+        "properties": {
+          "ID": {
+            "type": "string"
+          },
+          "Type": {
+            "type": "string",
+            "enum": [
+              "deployment.succeeded",
+              "deployment.in-progress",
+              "deployment.failed",
+              "deployment.rolled-back",
+              "deployment.not-found",
+              "deployment.unknown"
+            ]
+          },
+          "Cluster": {
+            "type": "string"
+          },
+          "Service": {
+            "type": "string"
+          },
+          "URL": {
+            "type": "string"
+          },
+          "Delivered": {
+            "type": "boolean"
+          },
+          "Attempts": {
+            "type": "integer",
+            "minimum": 1
+          },
+          "StatusCode": {
+            "type": "integer"
+          },
+          "Err": {
+            "type": "string"
+          },
+          "Timestamp": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "Duration": {
+            "type": "string"
+          }
+        },
+        "additionalProperties": false
+      },
+      "ServiceResource": {
+        "type": "object",
+        "required": [
+          "ID",
+          "Name",
+          "Mode",
+          "RunningReplicas",
+          "FailedReplicas",
+          "Verdict",
+          "CreatedAt",
+          "UpdatedAt"
+        ],
+        "properties": {
+          "ID": {
+            "type": "string"
+          },
+          "Name": {
+            "type": "string"
+          },
+          "Stack": {
+            "type": "string"
+          },
+          "Mode": {
+            "type": "string",
+            "enum": [
+              "replicated",
+              "global"
+            ]
+          },
+          "Image": {
+            "type": "string"
+          },
+          "Labels": {
+            "type": "object"
+          },
+          "Replicas": {
+            "type": "integer",
+            "minimum": 0,
+            "description": "Desired replicas, absent for global services."
+          },
+          "RunningReplicas": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "FailedReplicas": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "UpdateStatus": {
+            "$ref": "#/components/schemas/UpdateStatus"
+          },
+          "Verdict": {
+            "$ref": "#/components/schemas/Verdict"
+          },
+          "Err": {
+            "type": "string"
+          },
+          "CreatedAt": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "UpdatedAt": {
+            "type": "string",
+            "format": "date-time"
+          }
+        },
+        "additionalProperties": false
+      },
+      "TaskResource": {
+        "type": "object",
+        "required": [
+          "ID",
+          "ServiceID",
+          "State",
+          "DesiredState",
+          "CreatedAt",
+          "UpdatedAt"
+        ],
+        "properties": {
+          "ID": {
+            "type": "string"
+          },
+          "ServiceID": {
+            "type": "string"
+          },
+          "ServiceName": {
+            "type": "string"
+          },
+          "Slot": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "NodeID": {
+            "type": "string"
+          },
+          "State": {
+            "$ref": "#/components/schemas/TaskState"
+          },
+          "DesiredState": {
+            "$ref": "#/components/schemas/TaskState"
+          },
+          "Message": {
+            "type": "string"
+          },
+          "Err": {
+            "type": "string"
+          },
+          "Image": {
+            "type": "string"
+          },
+          "CreatedAt": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "UpdatedAt": {
+            "type": "string",
+            "format": "date-time"
+          }
+        },
+        "additionalProperties": false
+      },
+      "NodeResource": {
+        "type": "object",
+        "required": [
+          "ID",
+          "Hostname",
+          "Role",
+          "Availability",
+          "State",
+          "CreatedAt",
+          "UpdatedAt"
+        ],
+        "properties": {
+          "ID": {
+            "type": "string"
+          },
+          "Hostname": {
+            "type": "string"
+          },
+          "Role": {
+            "type": "string",
+            "enum": [
+              "manager",
+              "worker"
+            ]
+          },
+          "Availability": {
+            "type": "string",
+            "enum": [
+              "active",
+              "pause",
+              "drain"
+            ]
+          },
+          "State": {
+            "type": "string",
+            "enum": [
+              "unknown",
+              "down",
+              "ready",
+              "disconnected"
+            ]
+          },
+          "Addr": {
+            "type": "string"
+          },
+          "Leader": {
+            "type": "boolean"
+          },
+          "Reachability": {
+            "type": "string",
+            "enum": [
+              "unknown",
+              "unreachable",
+              "reachable"
+            ]
+          },
+          "EngineVersion": {
+            "type": "string"
+          },
+          "Labels": {
+            "type": "object"
+          },
+          "CreatedAt": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "UpdatedAt": {
+            "type": "string",
+            "format": "date-time"
+          }
+        },
+        "additionalProperties": false
+      },
+      "StackResource": {
+        "type": "object",
+        "required": [
+          "Name",
+          "Verdict",
+          "Services"
+        ],
+        "properties": {
+          "Name": {
+            "type": "string"
+          },
+          "Verdict": {
+            "$ref": "#/components/schemas/Verdict"
+          },
+          "Services": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          }
+        },
+        "additionalProperties": false
+      },
+      "DeploymentResource": {
+        "type": "object",
+        "required": [
+          "Service",
+          "Image",
+          "Verdict",
+          "RunningReplicas",
+          "FailedReplicas",
+          "Tasks"
+        ],
+        "properties": {
+          "Service": {
+            "type": "string"
+          },
+          "Image": {
+            "type": "string"
+          },
+          "Verdict": {
+            "$ref": "#/components/schemas/Verdict"
+          },
+          "Replicas": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "RunningReplicas": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "FailedReplicas": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "UpdateStatus": {
+            "$ref": "#/components/schemas/UpdateStatus"
+          },
+          "Err": {
+            "type": "string"
+          },
+          "Tasks": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/TaskStatus"
+            }
+          }
+        },
+        "additionalProperties": false
+      },
+      "ServiceList": {
+        "type": "object",
+        "required": [
+          "Items",
+          "Total",
+          "Limit",
+          "Offset"
+        ],
+        "properties": {
+          "Items": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/ServiceResource"
+            }
+          },
+          "Total": {
+            "type": "integer",
+            "minimum": 0,
+            "description": "Number of items matching the filters."
+          },
+          "Limit": {
+            "type": "integer",
+            "minimum": 1
+          },
+          "Offset": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "Next": {
+            "type": "string",
+            "description": "URL of the next page, absent on the last page."
+          }
+        },
+        "additionalProperties": false
+      },
+      "TaskList": {
+        "type": "object",
+        "required": [
+          "Items",
+          "Total",
+          "Limit",
+          "Offset"
+        ],
+        "properties": {
+          "Items": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/TaskResource"
+            }
+          },
+          "Total": {
+            "type": "integer",
+            "minimum": 0,
+            "description": "Number of items matching the filters."
+          },
+          "Limit": {
+            "type": "integer",
+            "minimum": 1
+          },
+          "Offset": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "Next": {
+            "type": "string",
+            "description": "URL of the next page, absent on the last page."
+          }
+        },
+        "additionalProperties": false
+      },
+      "NodeList": {
+        "type": "object",
+        "required": [
+          "Items",
+          "Total",
+          "Limit",
+          "Offset"
+        ],
+        "properties": {
+          "Items": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/NodeResource"
+            }
+          },
+          "Total": {
+            "type": "integer",
+            "minimum": 0,
+            "description": "Number of items matching the filters."
+          },
+          "Limit": {
+            "type": "integer",
+            "minimum": 1
+          },
+          "Offset": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "Next": {
+            "type": "string",
+            "description": "URL of the next page, absent on the last page."
+          }
+        },
+        "additionalProperties": false
+      },
+      "StackList": {
+        "type": "object",
+        "required": [
+          "Items",
+          "Total",
+          "Limit",
+          "Offset"
+        ],
+        "properties": {
+          "Items": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/StackResource"
+            }
+          },
+          "Total": {
+            "type": "integer",
+            "minimum": 0,
+            "description": "Number of items matching the filters."
+          },
+          "Limit": {
+            "type": "integer",
+            "minimum": 1
+          },
+          "Offset": {
+            "type": "integer",
+            "minimum": 0
+          },
+          "Next": {
+            "type": "string",
+            "description": "URL of the next page, absent on the last page."
+          }
+        },
+        "additionalProperties": false
+      },
+      "Verdict": {
+        "type": "string",
+        "enum": [
+          "succeeded",
+          "in-progress",
+          "failed",
+          "rolled-back",
+          "not-found",
+          "unknown"
+        ]
+      },
+      "ErrorEnvelope": {
+        "type": "object",
+        "required": [
+          "Error"
+        ],
+        "properties": {
+          "Error": {
+            "type": "object",
+            "required": [
+              "Status",
+              "Code",
+              "Message"
+            ],
+            "properties": {
+              "Status": {
+                "type": "integer"
+              },
+              "Code": {
+                "type": "string",
+                "description": "HTTP status text without spaces, e.g. NotFound."
+              },
+              "Message": {
+                "type": "string"
+              },
+              "RequestID": {
+                "type": "string",
+                "description": "X-Request-ID of the request, to find it in the server logs."
+              }
+            },
+            "additionalProperties": false
+          }
+        },
+        "additionalProperties": false
+      },
+      "Error": {
+        "type": "object",
+        "required": [
+          "error"
+        ],
+        "properties": {
+          "error": {
+            "type": "string"
+          },
+          "request_id": {
+            "type": "string",
+            "description": "X-Request-ID of the request, to find it in the server logs."
+          }
+        },
+        "additionalProperties": false
+      }
+    },
+    "securitySchemes": {
+      "bearer": {
+        "type": "http",
+        "scheme": "bearer"
+      },
+      "hmac": {
+        "type": "apiKey",
+        "in": "header",
+        "name": "Authorization",
+        "description": "HMAC-SHA256 keyId=<name>,signature=<hex> together with the X-Signature-Timestamp header."
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,364 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/albertogviana/docker-swarm-service-status/webhook"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+)
+
+// openAPI is the parsed OpenAPI document
+type openAPI map[string]interface{}
+
+func (s *ServerTestSuite) openAPI() openAPI {
+	document := openAPI{}
+	s.Require().NoError(json.Unmarshal([]byte(OpenAPIDocument), &document))
+
+	return document
+}
+
+// lookup follows a "/" separated path in the document
+func (o openAPI) lookup(path ...string) map[string]interface{} {
+	var node interface{} = map[string]interface{}(o)
+	for _, key := range path {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = object[key]
+	}
+
+	object, _ := node.(map[string]interface{})
+	return object
+}
+
+// resolve follows $ref until it reaches a definition
+func (o openAPI) resolve(node map[string]interface{}) map[string]interface{} {
+	for node != nil {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node
+		}
+		node = o.lookup(strings.Split(strings.TrimPrefix(ref, "#/"), "/")...)
+	}
+
+	return nil
+}
+
+// validate returns the violations of the value against the JSON schema subset used by the document
+func (o openAPI) validate(schema map[string]interface{}, value interface{}, at string) []string {
+	schema = o.resolve(schema)
+	if schema == nil {
+		return []string{fmt.Sprintf("%s: unresolved schema", at)}
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: null is not allowed", at)}
+	}
+
+	violations := []string{}
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %T", at, value)}
+		}
+
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: missing required property %s", at, name))
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, property := range object {
+			definition, ok := properties[name].(map[string]interface{})
+			if !ok {
+				if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+					violations = append(violations, fmt.Sprintf("%s: unexpected property %s", at, name))
+				}
+				continue
+			}
+			violations = append(violations, o.validate(definition, property, at+"."+name)...)
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array, got %T", at, value)}
+		}
+
+		items, _ := schema["items"].(map[string]interface{})
+		for i, item := range array {
+			violations = append(violations, o.validate(items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected a string, got %T", at, value)}
+		}
+
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+				violations = append(violations, fmt.Sprintf("%s: %s is not a date-time", at, text))
+			}
+		}
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected a number, got %T", at, value)}
+		}
+
+		if schema["type"] == "integer" && number != float64(int64(number)) {
+			violations = append(violations, fmt.Sprintf("%s: %v is not an integer", at, number))
+		}
+		if minimum, ok := schema["minimum"].(float64); ok && number < minimum {
+			violations = append(violations, fmt.Sprintf("%s: %v is below %v", at, number, minimum))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected a boolean, got %T", at, value)}
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if allowed == value {
+				found = true
+			}
+		}
+		if !found {
+			violations = append(violations, fmt.Sprintf("%s: %v is not one of %v", at, value, enum))
+		}
+	}
+
+	return violations
+}
+
+// assertConforms verifies that the response is documented for the method and route and matches its schema
+func (s *ServerTestSuite) assertConforms(document openAPI, method string, route string, rec *httptest.ResponseRecorder) {
+	operation := document.lookup("paths", route, strings.ToLower(method))
+	s.Require().NotNil(operation, "%s %s is not documented", method, route)
+
+	response := document.resolve(document.lookup("paths", route, strings.ToLower(method), "responses", fmt.Sprintf("%d", rec.Code)))
+	s.Require().NotNil(response, "%s does not document the %d response", route, rec.Code)
+
+	if rec.Code == http.StatusNotModified {
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	s.Require().NoError(err)
+
+	content, _ := response["content"].(map[string]interface{})
+	definition, ok := content[mediaType].(map[string]interface{})
+	s.Require().True(ok, "%s does not document %s for the %d response", route, mediaType, rec.Code)
+
+	if mediaType != "application/json" {
+		return
+	}
+
+	var body interface{}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+
+	schema, _ := definition["schema"].(map[string]interface{})
+	s.Empty(document.validate(schema, body, "body"), "%s %d: %s", route, rec.Code, rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_OpenAPI_DocumentsEveryRoute() {
+	document := s.openAPI()
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: new(ServiceMock)})
+
+	routes := []string{}
+	muxRouter.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		routes = append(routes, template)
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			s.NotNil(document.lookup("paths", template, strings.ToLower(method)), "%s %s is not documented", method, template)
+		}
+		return nil
+	})
+
+	documented := []string{}
+	for path := range document.lookup("paths") {
+		documented = append(documented, path)
+	}
+
+	sort.Strings(routes)
+	sort.Strings(documented)
+	s.Equal(routes, documented)
+}
+
+func (s *ServerTestSuite) Test_OpenAPI_ResolvesEveryReference() {
+	document := s.openAPI()
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok {
+				s.NotNil(document.resolve(value), "%s does not resolve", ref)
+			}
+			for _, child := range value {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		}
+	}
+
+	walk(map[string]interface{}(document))
+}
+
+func (s *ServerTestSuite) Test_OpenAPI_ServesDocument() {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: new(ServiceMock), Authenticator: NewTokenAuthenticator(nil)})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", OpenAPIPath, nil)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.Equal("application/json", rec.Header().Get("Content-Type"))
+	s.JSONEq(OpenAPIDocument, rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_OpenAPI_ResponsesConform() {
+	document := s.openAPI()
+
+	replicas := uint64(2)
+	started := time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC)
+	status := service.ServiceStatus{
+		ID:              "tt3otdsnkd1kgh80u45bwmcb4",
+		Name:            "prod_web",
+		Replicas:        &replicas,
+		RunningReplicas: 1,
+		FailedReplicas:  1,
+		UpdateStatus:    &swarm.UpdateStatus{State: swarm.UpdateStateRollbackCompleted, StartedAt: &started, CompletedAt: &started, Message: "rollback completed"},
+		TaskStatus: []service.TaskStatus{
+			{TaskID: "evv1jw9o7981mrp0p50j1gy5k", Slot: 1, NodeID: "x8mjy3ys2ntcq3ypbhvbs6jyf", Timestamp: started, DesiredState: swarm.TaskStateRunning, State: swarm.TaskStateRunning, Message: "started", Image: "acme/web:1.0.0"},
+			{TaskID: "ka8a7cwzf0pq4opcscd1yf7rn", Slot: 2, NodeID: "x8mjy3ys2ntcq3ypbhvbs6jyf", Timestamp: started, DesiredState: swarm.TaskStateShutdown, State: swarm.TaskStateFailed, Err: "task: non-zero exit (1)", Image: "acme/web:1.1.0"},
+		},
+	}
+	notFound := service.ServiceStatus{Name: "prod_api", Err: "The prod_api service was not found in the cluster."}
+
+	swarmService := swarm.Service{ID: "tt3otdsnkd1kgh80u45bwmcb4"}
+	swarmService.Spec.Name = "prod_web"
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "prod_web").Return(status, nil)
+	serviceMock.On("GetServiceStatus", "prod_api").Return(notFound, nil)
+	serviceMock.On("GetServiceStatus", "prod_db").Return(service.ServiceStatus{}, errors.New("Cannot connect to the Docker daemon."))
+	serviceMock.On("GetDeploymentStatus", "prod_web", "acme/web:1.1.0").Return(status, nil)
+	serviceMock.On("GetStackStatus", "prod").Return(service.StackStatus{Name: "prod", Services: []service.ServiceStatus{status}}, nil)
+	serviceMock.On("GetInfo").Return(service.Info{APIVersion: "1.41", ServerAPIVersion: "1.43", ServerMinAPIVersion: "1.12", ServerVersion: "24.0.7", Features: []service.Feature{{Name: "rollback", Description: "UpdateStatus reports rollbacks.", MinAPIVersion: "1.28", Supported: true}}}, nil)
+	serviceMock.On("GetReadiness").Return(service.Readiness{Ready: false, Latency: "2ms", Checks: []service.Check{{Name: "ping", OK: false, Message: "Cannot connect to the Docker daemon."}}})
+	serviceMock.On("GetServices", mock.Anything).Return([]swarm.Service{swarmService}, nil)
+	serviceMock.On("GetService", mock.Anything).Return(swarmService, nil)
+	serviceMock.On("GetTask", mock.Anything).Return([]swarm.Task{}, nil)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+	dispatcher := webhook.NewDispatcher([]webhook.Webhook{{URL: receiver.URL}})
+	dispatcher.Notify(webhook.NewEvent("default", status, nil, service.VerdictInProgress))
+
+	server := &Server{
+		Service:       serviceMock,
+		Clusters:      map[string]service.Services{"default": serviceMock, "staging": serviceMock},
+		Authenticator: NewTokenAuthenticator([]Credential{{"admin", "s3cr3t", []string{"*"}}, {"ci", "t0k3n", []string{"stack:billing"}}}),
+		Webhooks:      dispatcher,
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	image := base64.URLEncoding.EncodeToString([]byte("acme/web:1.1.0"))
+	prefix := "/v1/docker-swarm-service-status"
+
+	requests := []struct {
+		route  string
+		target string
+		token  string
+		accept string
+		code   int
+	}{
+		{"/service-status/{service}", "/service-status/prod_web", "s3cr3t", "", 200},
+		{"/service-status/{service}", "/service-status/prod_api", "s3cr3t", "", 200},
+		{"/service-status/{service}", "/service-status/prod_db", "s3cr3t", "", 500},
+		{"/service-status/{service}", "/service-status/prod_web", "", "", 401},
+		{"/service-status/{service}", "/service-status/prod_web", "t0k3n", "", 403},
+		{"/service-status/{service}", "/service-status/prod_web?format=csv", "s3cr3t", "", 400},
+		{"/service-status/{service}", "/service-status/prod_web", "s3cr3t", "text/html", 406},
+		{"/service-status/{service}", "/service-status/prod_web", "s3cr3t", "text/plain", 200},
+		{"/service-status/{service}", "/service-status/prod_web", "s3cr3t", "application/yaml", 200},
+		{"/deployment-status/{service}/{image}", "/deployment-status/prod_web/" + image, "s3cr3t", "", 200},
+		{"/deployment-status/{service}/{image}", "/deployment-status/prod_web/" + image, "s3cr3t", "application/junit+xml", 200},
+		{"/deployment-status/{service}/{image}", "/deployment-status/prod_web/!!!", "s3cr3t", "", 400},
+		{"/stack-status/{stack}", "/stack-status/prod", "s3cr3t", "", 200},
+		{"/info", "/info", "s3cr3t", "", 200},
+		{"/clusters", "/clusters", "s3cr3t", "", 200},
+		{"/clusters/{cluster}/service-status/{service}", "/clusters/staging/service-status/prod_web", "s3cr3t", "", 200},
+		{"/clusters/{cluster}/service-status/{service}", "/clusters/production/service-status/prod_web", "s3cr3t", "", 404},
+		{"/clusters/{cluster}/deployment-status/{service}/{image}", "/clusters/staging/deployment-status/prod_web/" + image, "s3cr3t", "", 200},
+		{"/clusters/{cluster}/stack-status/{stack}", "/clusters/staging/stack-status/prod", "s3cr3t", "", 200},
+		{"/clusters/{cluster}/info", "/clusters/staging/info", "s3cr3t", "", 200},
+		{"/cross-cluster/service-status/{service}", "/cross-cluster/service-status/prod_web", "s3cr3t", "", 200},
+		{"/badge/service/{service}", "/badge/service/prod_web", "s3cr3t", "", 200},
+		{"/badge/stack/{stack}", "/badge/stack/prod", "s3cr3t", "", 200},
+		{"/clusters/{cluster}/badge/service/{service}", "/clusters/staging/badge/service/prod_db", "s3cr3t", "", 500},
+		{"/clusters/{cluster}/badge/stack/{stack}", "/clusters/staging/badge/stack/prod", "s3cr3t", "", 200},
+		{"/dashboard", "/dashboard", "s3cr3t", "", 200},
+		{"/dashboard/services/{service}", "/dashboard/services/prod_web", "s3cr3t", "", 200},
+		{"/dashboard/clusters/{cluster}", "/dashboard/clusters/staging", "s3cr3t", "", 200},
+		{"/dashboard/clusters/{cluster}/services/{service}", "/dashboard/clusters/staging/services/prod_web", "s3cr3t", "", 200},
+		{"/webhooks/deliveries", "/webhooks/deliveries", "s3cr3t", "", 200},
+		{"/health", "/health", "", "", 200},
+		{"/ready", "/ready", "", "", 503},
+		{"/openapi.json", "/openapi.json", "", "", 200},
+	}
+
+	for _, request := range requests {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", prefix+request.target, nil)
+		if request.token != "" {
+			req.Header.Set("Authorization", "Bearer "+request.token)
+		}
+		if request.accept != "" {
+			req.Header.Set("Accept", request.accept)
+		}
+
+		muxRouter.ServeHTTP(rec, req)
+
+		s.Equal(request.code, rec.Code, request.target)
+		s.assertConforms(document, "GET", prefix+request.route, rec)
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultRateBurst is how many requests a client may send at once when the rate limit is enabled
+const DefaultRateBurst = 10
+
+// RateLimiter gives every client a token bucket refilled at a fixed rate. Clients are identified by the name of
+// their credential when authenticated and by their IP address otherwise.
+type RateLimiter struct {
+	limit   rate.Limit
+	burst   int
+	mutex   sync.Mutex
+	clients map[string]*clientBucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+type clientBucket struct {
+	limiter *rate.Limiter
+	seen    time.Time
+}
+
+// NewRateLimiter returns a limiter allowing requestsPerSecond requests per client on average and burst at once
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		limit:   rate.Limit(requestsPerSecond),
+		burst:   burst,
+		clients: map[string]*clientBucket{},
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of the client. When the bucket is empty it returns false together with the
+// time until the next token.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.clients[client]
+	if !ok {
+		bucket = &clientBucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[client] = bucket
+	}
+	bucket.seen = now
+
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+
+	return true, 0
+}
+
+// sweep forgets the clients whose bucket refilled completely, at most once a minute
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+
+	refill := time.Duration(float64(l.burst) / float64(l.limit) * float64(time.Second))
+	for client, bucket := range l.clients {
+		if now.Sub(bucket.seen) >= refill {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// rateLimited answers with 429 Too Many Requests and returns true when the client ran out of tokens
+func (s *Server) rateLimited(w http.ResponseWriter, r *http.Request, client string) bool {
+	if s.RateLimiter == nil {
+		return false
+	}
+
+	allowed, delay := s.RateLimiter.Allow(client)
+	if allowed {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(delay.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	renderError(w, r, http.StatusTooManyRequests, fmt.Sprintf("Too many requests, retry in %d second(s).", retryAfter))
+	return true
+}
+
+// clientIP returns the IP address the request came from
+func clientIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+
+	return host
+}
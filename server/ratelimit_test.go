@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/gorilla/mux"
+)
+
+func (s *ServerTestSuite) Test_RateLimiter_RefillsTheBucket() {
+	now := time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC)
+	limiter := NewRateLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		allowed, _ := limiter.Allow("identity:ci")
+		s.True(allowed)
+	}
+
+	allowed, delay := limiter.Allow("identity:ci")
+	s.False(allowed)
+	s.Equal(time.Second, delay)
+
+	allowed, _ = limiter.Allow("identity:admin")
+	s.True(allowed, "every client has its own bucket")
+
+	now = now.Add(500 * time.Millisecond)
+	allowed, delay = limiter.Allow("identity:ci")
+	s.False(allowed)
+	s.Equal(500*time.Millisecond, delay)
+
+	now = now.Add(500 * time.Millisecond)
+	allowed, _ = limiter.Allow("identity:ci")
+	s.True(allowed)
+}
+
+func (s *ServerTestSuite) Test_RateLimiter_ForgetsIdleClients() {
+	now := time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC)
+	limiter := NewRateLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("identity:ci")
+	limiter.Allow("identity:admin")
+	s.Len(limiter.clients, 2)
+
+	now = now.Add(time.Minute)
+	limiter.Allow("identity:admin")
+	s.Len(limiter.clients, 1)
+}
+
+func (s *ServerTestSuite) Test_RateLimit_ReturnTooManyRequests() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+	server := &Server{
+		Service:       serviceMock,
+		Authenticator: NewTokenAuthenticator([]Credential{{"admin", "s3cr3t", []string{"*"}}, {"ci", "t0k3n", []string{"stack:billing"}}}),
+		RateLimiter:   NewRateLimiter(0.5, 1),
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	get := func(token string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/service-status/billing_api", nil)
+		req.RemoteAddr = "10.0.0.1:51234"
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		muxRouter.ServeHTTP(rec, req)
+		return rec
+	}
+
+	s.Equal(200, get("t0k3n").Code)
+
+	rec := get("t0k3n")
+	s.Equal(429, rec.Code)
+	s.Equal("2", rec.Header().Get("Retry-After"))
+	s.Equal(`{"error": "Too many requests, retry in 2 second(s)."}`, rec.Body.String())
+
+	s.Equal(200, get("s3cr3t").Code, "the limit applies per credential")
+
+	s.Equal(401, get("wrong").Code)
+	s.Equal(429, get("wrong").Code, "invalid credentials are limited per IP address")
+
+	serviceMock.AssertNumberOfCalls(s.T(), "GetServiceStatus", 2)
+}
+
+func (s *ServerTestSuite) Test_RateLimit_UsesIPAddressWithoutAuthenticator() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+	server := &Server{Service: serviceMock, RateLimiter: NewRateLimiter(1, 1)}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	codes := []int{}
+	for _, remoteAddr := range []string{"10.0.0.1:51234", "10.0.0.1:51235", "10.0.0.2:51234"} {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/service-status/billing_api", nil)
+		req.RemoteAddr = remoteAddr
+		muxRouter.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+
+	s.Equal([]int{200, 429, 200}, codes)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/health", nil)
+	req.RemoteAddr = "10.0.0.1:51234"
+	muxRouter.ServeHTTP(rec, req)
+	s.Equal(200, rec.Code, "probes are not rate limited")
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/albertogviana/docker-swarm-service-status/report"
+)
+
+// mediaTypes maps the media types of the Accept header to the format they select
+var mediaTypes = map[string]report.Format{
+	"*/*":                   report.FormatJSON,
+	"application/*":         report.FormatJSON,
+	"application/json":      report.FormatJSON,
+	"application/yaml":      report.FormatYAML,
+	"application/x-yaml":    report.FormatYAML,
+	"text/yaml":             report.FormatYAML,
+	"text/x-yaml":           report.FormatYAML,
+	"text/*":                report.FormatTable,
+	"text/plain":            report.FormatTable,
+	report.JUnitContentType: report.FormatJUnit,
+}
+
+// ErrorResponse is the body of every error returned by the v1 API
+type ErrorResponse struct {
+	Error string `json:"error"`
+	// RequestID is the X-Request-ID of the request, it is empty when the request was not logged
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Rows implements report.Tabular
+func (e ErrorResponse) Rows() [][]string {
+	rows := [][]string{{"ERROR", e.Error}}
+	if e.RequestID != "" {
+		rows = append(rows, []string{"REQUEST ID", e.RequestID})
+	}
+
+	return rows
+}
+
+// Rows implements report.Tabular
+func (r Response) Rows() [][]string {
+	return [][]string{{"STATUS", r.Status}}
+}
+
+// negotiate returns the format requested with the ?format= parameter or, when absent, the most preferred media type
+// of the Accept header v can be rendered in. Requests accepting nothing the server can produce for v are answered
+// with 406 Not Acceptable.
+func negotiate(r *http.Request, v interface{}) (report.Format, int, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		format, err := report.ParseFormat(name)
+		if err != nil {
+			return "", http.StatusBadRequest, err
+		}
+		return format, http.StatusOK, nil
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return report.FormatJSON, http.StatusOK, nil
+	}
+
+	for _, mediaType := range acceptedMediaTypes(accept) {
+		if format, ok := mediaTypes[mediaType]; ok && format.Supports(v) {
+			return format, http.StatusOK, nil
+		}
+	}
+
+	return "", http.StatusNotAcceptable, fmt.Errorf("None of the %s media types is supported, use application/json, application/yaml, text/plain or application/junit+xml.", accept)
+}
+
+// acceptedMediaTypes returns the media types of the Accept header from the most to the least preferred
+func acceptedMediaTypes(accept string) []string {
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+
+	ranges := []mediaRange{}
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		accepted := mediaRange{strings.ToLower(strings.TrimSpace(params[0])), 1}
+
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && kv[0] == "q" {
+				if quality, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					accepted.quality = quality
+				}
+			}
+		}
+
+		if accepted.mediaType != "" && accepted.quality > 0 {
+			ranges = append(ranges, accepted)
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	mediaTypes := make([]string, len(ranges))
+	for i, accepted := range ranges {
+		mediaTypes[i] = accepted.mediaType
+	}
+
+	return mediaTypes
+}
+
+// varyAccept adds Accept to the Vary header of the response unless it is already listed
+func varyAccept(w http.ResponseWriter) {
+	for _, value := range w.Header()["Vary"] {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), "Accept") {
+				return
+			}
+		}
+	}
+
+	w.Header().Add("Vary", "Accept")
+}
+
+// render writes v with the given status code in the format negotiated with the client
+func render(w http.ResponseWriter, r *http.Request, code int, v interface{}) {
+	varyAccept(w)
+
+	format, status, err := negotiate(r, v)
+	if err != nil {
+		writeJSONError(w, r, status, err.Error())
+		return
+	}
+
+	if !format.Supports(v) {
+		writeJSONError(w, r, http.StatusNotAcceptable, fmt.Sprintf("The %s format is not supported for this resource.", format))
+		return
+	}
+
+	write(w, r, format, code, v)
+}
+
+// renderError writes an error message in the format negotiated with the client, falling back to JSON
+func renderError(w http.ResponseWriter, r *http.Request, code int, message string) {
+	varyAccept(w)
+
+	body := errorBody(r, code, message)
+	format, _, err := negotiate(r, body)
+	if err != nil || format == report.FormatJSON || !format.Supports(body) {
+		writeJSONError(w, r, code, message)
+		return
+	}
+
+	write(w, r, format, code, body)
+}
+
+func write(w http.ResponseWriter, r *http.Request, format report.Format, code int, v interface{}) {
+	buffer := &bytes.Buffer{}
+	if err := report.Render(buffer, format, v); err != nil {
+		logError(r, err)
+		writeJSONError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.WriteHeader(code)
+	w.Write(buffer.Bytes())
+}
+
+func writeJSONError(w http.ResponseWriter, r *http.Request, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if isV2(r) {
+		js, _ := json.Marshal(errorBody(r, code, message))
+		w.WriteHeader(code)
+		w.Write(js)
+		return
+	}
+
+	js, _ := json.Marshal(message)
+	w.WriteHeader(code)
+	if id := requestID(r); id != "" {
+		jsID, _ := json.Marshal(id)
+		fmt.Fprintf(w, `{"error": %s, "request_id": %s}`, js, jsID)
+		return
+	}
+	fmt.Fprintf(w, `{"error": %s}`, js)
+}
+
+// errorBody returns the error envelope of the API version requested
+func errorBody(r *http.Request, code int, message string) interface{} {
+	if isV2(r) {
+		return ErrorEnvelope{APIError{Status: code, Code: strings.Replace(http.StatusText(code), " ", "", -1), Message: message, RequestID: requestID(r)}}
+	}
+
+	return ErrorResponse{message, requestID(r)}
+}
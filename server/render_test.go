@@ -0,0 +1,157 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/gorilla/mux"
+)
+
+func (s *ServerTestSuite) serveServiceStatus(target string, accept string) *httptest.ResponseRecorder {
+	serviceMock := new(ServiceMock)
+
+	replicas := uint64(1)
+	serviceMock.On("GetServiceStatus", "docker-routing-mesh").Return(service.ServiceStatus{
+		ID:              "tt3otdsnkd1kgh80u45bwmcb4",
+		Name:            "docker-routing-mesh",
+		Replicas:        &replicas,
+		RunningReplicas: 1,
+		TaskStatus: []service.TaskStatus{
+			{TaskID: "evv1jw9o7981mrp0p50j1gy5k", Slot: 1, NodeID: "x8mjy3ys2ntcq3ypbhvbs6jyf", State: swarm.TaskStateRunning, Image: "albertogviana/docker-routing-mesh:1.0.0"},
+		},
+	}, nil)
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: serviceMock})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	muxRouter.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *ServerTestSuite) Test_Render_AcceptYAML() {
+	rec := s.serveServiceStatus("/v1/docker-swarm-service-status/service-status/docker-routing-mesh", "application/yaml")
+
+	s.Equal(200, rec.Code)
+	s.Equal("application/yaml", rec.Header().Get("Content-Type"))
+	s.Equal("Accept", rec.Header().Get("Vary"))
+	s.Contains(rec.Body.String(), "Name: docker-routing-mesh\n")
+}
+
+func (s *ServerTestSuite) Test_Render_AcceptTextTable() {
+	rec := s.serveServiceStatus("/v1/docker-swarm-service-status/service-status/docker-routing-mesh", "text/plain")
+
+	s.Equal(200, rec.Code)
+	s.Equal("text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	s.Contains(rec.Body.String(), "ID                         SLOT  NODE                       STATE    IMAGE                                    ERROR\n")
+	s.Contains(rec.Body.String(), "evv1jw9o7981mrp0p50j1gy5k  1     x8mjy3ys2ntcq3ypbhvbs6jyf  running  albertogviana/docker-routing-mesh:1.0.0  -\n")
+}
+
+func (s *ServerTestSuite) Test_Render_AcceptQuality() {
+	rec := s.serveServiceStatus("/v1/docker-swarm-service-status/service-status/docker-routing-mesh", "application/json;q=0.5, text/yaml, */*;q=0.1")
+
+	s.Equal(200, rec.Code)
+	s.Equal("application/yaml", rec.Header().Get("Content-Type"))
+}
+
+func (s *ServerTestSuite) Test_Render_FormatParameterOverridesAccept() {
+	rec := s.serveServiceStatus("/v1/docker-swarm-service-status/service-status/docker-routing-mesh?format=table", "application/json")
+
+	s.Equal(200, rec.Code)
+	s.Equal("text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	s.Contains(rec.Body.String(), "VERDICT   succeeded\n")
+}
+
+func (s *ServerTestSuite) Test_Render_InvalidFormatParameter() {
+	rec := s.serveServiceStatus("/v1/docker-swarm-service-status/service-status/docker-routing-mesh?format=csv", "")
+
+	s.Equal(400, rec.Code)
+	s.Equal("application/json", rec.Header().Get("Content-Type"))
+	s.Equal(`{"error": "The csv format is not supported, use one of json, yaml, table or junit."}`, rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_Render_NotAcceptable() {
+	rec := s.serveServiceStatus("/v1/docker-swarm-service-status/service-status/docker-routing-mesh", "text/html")
+
+	s.Equal(406, rec.Code)
+	s.Equal(`{"error": "None of the text/html media types is supported, use application/json, application/yaml, text/plain or application/junit+xml."}`, rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_Render_BrowserAcceptReturnJSON() {
+	browser := "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+	rec := s.serveServiceStatus("/v1/docker-swarm-service-status/service-status/docker-routing-mesh", browser)
+
+	s.Equal(200, rec.Code)
+	s.Equal("application/json", rec.Header().Get("Content-Type"))
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: new(ServiceMock)})
+
+	rec = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/health", nil)
+	req.Header.Set("Accept", browser)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.Equal("application/json", rec.Header().Get("Content-Type"))
+}
+
+func (s *ServerTestSuite) Test_Render_SkipFormatNotSupportedByResource() {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: new(ServiceMock)})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/health", nil)
+	req.Header.Set("Accept", "application/junit+xml, text/yaml;q=0.5")
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.Equal("application/yaml", rec.Header().Get("Content-Type"))
+}
+
+func (s *ServerTestSuite) Test_Render_XMLNotAcceptable() {
+	rec := s.serveServiceStatus("/v1/docker-swarm-service-status/service-status/docker-routing-mesh", "application/xml")
+
+	s.Equal(406, rec.Code)
+}
+
+func (s *ServerTestSuite) Test_Render_FormatNotSupportedByResource() {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: new(ServiceMock)})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/health?format=junit", nil)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(406, rec.Code)
+	s.Equal(`{"error": "The junit format is not supported for this resource."}`, rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_Render_ErrorAsTable() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "docker-routing-mesh").Return(service.ServiceStatus{}, fmt.Errorf("Cannot connect to the Docker daemon."))
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: serviceMock})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/service-status/docker-routing-mesh?format=table", nil)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(500, rec.Code)
+	s.Equal("ERROR  Cannot connect to the Docker daemon.\n", rec.Body.String())
+}
@@ -0,0 +1,381 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// ServiceResource is a swarm service as exposed by the v2 API
+type ServiceResource struct {
+	ID    string
+	Name  string
+	Stack string `json:",omitempty"`
+	// Mode is replicated or global
+	Mode            string
+	Image           string            `json:",omitempty"`
+	Labels          map[string]string `json:",omitempty"`
+	Replicas        *uint64           `json:",omitempty"`
+	RunningReplicas int
+	FailedReplicas  int
+	UpdateStatus    *swarm.UpdateStatus `json:",omitempty"`
+	Verdict         service.Verdict
+	Err             string `json:",omitempty"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// TaskResource is a swarm task as exposed by the v2 API
+type TaskResource struct {
+	ID           string
+	ServiceID    string
+	ServiceName  string `json:",omitempty"`
+	Slot         int    `json:",omitempty"`
+	NodeID       string `json:",omitempty"`
+	State        swarm.TaskState
+	DesiredState swarm.TaskState
+	Message      string `json:",omitempty"`
+	Err          string `json:",omitempty"`
+	Image        string `json:",omitempty"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// NodeResource is a swarm node as exposed by the v2 API
+type NodeResource struct {
+	ID            string
+	Hostname      string
+	Role          swarm.NodeRole
+	Availability  swarm.NodeAvailability
+	State         swarm.NodeState
+	Addr          string            `json:",omitempty"`
+	Leader        bool              `json:",omitempty"`
+	Reachability  string            `json:",omitempty"`
+	EngineVersion string            `json:",omitempty"`
+	Labels        map[string]string `json:",omitempty"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// StackResource is a stack deployed with "docker stack deploy" as exposed by the v2 API
+type StackResource struct {
+	Name     string
+	Verdict  service.Verdict
+	Services []string
+}
+
+// DeploymentResource is the rollout of an image to a service as exposed by the v2 API
+type DeploymentResource struct {
+	Service         string
+	Image           string
+	Verdict         service.Verdict
+	Replicas        *uint64 `json:",omitempty"`
+	RunningReplicas int
+	FailedReplicas  int
+	UpdateStatus    *swarm.UpdateStatus `json:",omitempty"`
+	Err             string              `json:",omitempty"`
+	Tasks           []service.TaskStatus
+}
+
+// List is the envelope of every v2 collection
+type List struct {
+	Items  interface{}
+	Total  int
+	Limit  int
+	Offset int
+	// Next is the URL of the next page, absent on the last page
+	Next string `json:",omitempty"`
+}
+
+// ErrorEnvelope is the body of every v2 error
+type ErrorEnvelope struct {
+	Error APIError
+}
+
+// APIError describes a v2 error
+type APIError struct {
+	Status int
+	// Code is the HTTP status text without spaces, e.g. NotFound
+	Code    string
+	Message string
+	// RequestID is the X-Request-ID of the request, it is empty when the request was not logged
+	RequestID string `json:",omitempty"`
+}
+
+// Rows implements report.Tabular
+func (e ErrorEnvelope) Rows() [][]string {
+	rows := [][]string{{"ERROR", e.Error.Code, e.Error.Message}}
+	if e.Error.RequestID != "" {
+		rows = append(rows, []string{"REQUEST ID", e.Error.RequestID})
+	}
+
+	return rows
+}
+
+// Rows implements report.Tabular, the items are rendered when they are tabular
+func (l List) Rows() [][]string {
+	rows := [][]string{}
+	if items, ok := l.Items.(interface {
+		Rows() [][]string
+	}); ok {
+		rows = items.Rows()
+	}
+
+	return append(rows, []string{}, []string{"TOTAL", fmt.Sprintf("%d", l.Total), "OFFSET", fmt.Sprintf("%d", l.Offset), "LIMIT", fmt.Sprintf("%d", l.Limit)})
+}
+
+// ServiceResources is a page of services
+type ServiceResources []ServiceResource
+
+// Rows implements report.Tabular
+func (r ServiceResources) Rows() [][]string {
+	rows := [][]string{{"NAME", "STACK", "MODE", "IMAGE", "REPLICAS", "VERDICT"}}
+	for _, resource := range r {
+		rows = append(rows, []string{resource.Name, dash(resource.Stack), resource.Mode, dash(resource.Image), resourceReplicas(resource.Replicas, resource.RunningReplicas), string(resource.Verdict)})
+	}
+
+	return rows
+}
+
+// Rows implements report.Tabular
+func (r ServiceResource) Rows() [][]string {
+	return [][]string{
+		{"NAME", r.Name},
+		{"ID", r.ID},
+		{"STACK", dash(r.Stack)},
+		{"MODE", r.Mode},
+		{"IMAGE", dash(r.Image)},
+		{"REPLICAS", resourceReplicas(r.Replicas, r.RunningReplicas)},
+		{"FAILED", fmt.Sprintf("%d", r.FailedReplicas)},
+		{"VERDICT", string(r.Verdict)},
+	}
+}
+
+// TaskResources is a page of tasks
+type TaskResources []TaskResource
+
+// Rows implements report.Tabular
+func (r TaskResources) Rows() [][]string {
+	rows := [][]string{{"ID", "SERVICE", "SLOT", "NODE", "STATE", "IMAGE", "ERROR"}}
+	for _, resource := range r {
+		slot := "-"
+		if resource.Slot > 0 {
+			slot = fmt.Sprintf("%d", resource.Slot)
+		}
+		rows = append(rows, []string{resource.ID, dash(resource.ServiceName), slot, dash(resource.NodeID), string(resource.State), dash(resource.Image), dash(resource.Err)})
+	}
+
+	return rows
+}
+
+// Rows implements report.Tabular
+func (r TaskResource) Rows() [][]string {
+	return TaskResources{r}.Rows()
+}
+
+// NodeResources is a page of nodes
+type NodeResources []NodeResource
+
+// Rows implements report.Tabular
+func (r NodeResources) Rows() [][]string {
+	rows := [][]string{{"ID", "HOSTNAME", "ROLE", "AVAILABILITY", "STATE", "LEADER", "ENGINE"}}
+	for _, resource := range r {
+		rows = append(rows, []string{resource.ID, resource.Hostname, string(resource.Role), string(resource.Availability), string(resource.State), fmt.Sprintf("%t", resource.Leader), dash(resource.EngineVersion)})
+	}
+
+	return rows
+}
+
+// Rows implements report.Tabular
+func (r NodeResource) Rows() [][]string {
+	return NodeResources{r}.Rows()
+}
+
+// StackResources is a page of stacks
+type StackResources []StackResource
+
+// Rows implements report.Tabular
+func (r StackResources) Rows() [][]string {
+	rows := [][]string{{"NAME", "SERVICES", "VERDICT"}}
+	for _, resource := range r {
+		rows = append(rows, []string{resource.Name, fmt.Sprintf("%d", len(resource.Services)), string(resource.Verdict)})
+	}
+
+	return rows
+}
+
+// Rows implements report.Tabular
+func (r StackResource) Rows() [][]string {
+	return StackResources{r}.Rows()
+}
+
+// Rows implements report.Tabular
+func (r DeploymentResource) Rows() [][]string {
+	rows := [][]string{
+		{"SERVICE", r.Service},
+		{"IMAGE", dash(r.Image)},
+		{"VERDICT", string(r.Verdict)},
+		{"REPLICAS", resourceReplicas(r.Replicas, r.RunningReplicas)},
+	}
+	if r.Err != "" {
+		rows = append(rows, []string{"ERROR", r.Err})
+	}
+
+	return rows
+}
+
+// newServiceResource combines the swarm service with its status
+func newServiceResource(swarmService swarm.Service, status service.ServiceStatus) ServiceResource {
+	return ServiceResource{
+		ID:              swarmService.ID,
+		Name:            swarmService.Spec.Name,
+		Stack:           swarmService.Spec.Labels[service.StackNamespaceLabel],
+		Mode:            serviceMode(swarmService.Spec.Mode),
+		Image:           service.SpecImage(&swarmService.Spec),
+		Labels:          swarmService.Spec.Labels,
+		Replicas:        status.Replicas,
+		RunningReplicas: status.RunningReplicas,
+		FailedReplicas:  status.FailedReplicas,
+		UpdateStatus:    status.UpdateStatus,
+		Verdict:         status.Verdict(),
+		Err:             status.Err,
+		CreatedAt:       swarmService.CreatedAt,
+		UpdatedAt:       swarmService.UpdatedAt,
+	}
+}
+
+func newTaskResource(task swarm.Task, serviceName string) TaskResource {
+	resource := TaskResource{
+		ID:           task.ID,
+		ServiceID:    task.ServiceID,
+		ServiceName:  serviceName,
+		Slot:         task.Slot,
+		NodeID:       task.NodeID,
+		State:        task.Status.State,
+		DesiredState: task.DesiredState,
+		Message:      task.Status.Message,
+		Err:          task.Status.Err,
+		CreatedAt:    task.CreatedAt,
+		UpdatedAt:    task.Status.Timestamp,
+	}
+
+	if task.Spec.ContainerSpec != nil {
+		resource.Image = strings.SplitN(task.Spec.ContainerSpec.Image, "@", 2)[0]
+	}
+
+	return resource
+}
+
+func newNodeResource(node swarm.Node) NodeResource {
+	resource := NodeResource{
+		ID:            node.ID,
+		Hostname:      node.Description.Hostname,
+		Role:          node.Spec.Role,
+		Availability:  node.Spec.Availability,
+		State:         node.Status.State,
+		Addr:          node.Status.Addr,
+		EngineVersion: node.Description.Engine.EngineVersion,
+		Labels:        node.Spec.Labels,
+		CreatedAt:     node.CreatedAt,
+		UpdatedAt:     node.UpdatedAt,
+	}
+
+	if node.ManagerStatus != nil {
+		resource.Leader = node.ManagerStatus.Leader
+		resource.Reachability = string(node.ManagerStatus.Reachability)
+	}
+
+	return resource
+}
+
+func newDeploymentResource(image string, status service.ServiceStatus) DeploymentResource {
+	tasks := status.TaskStatus
+	if tasks == nil {
+		tasks = []service.TaskStatus{}
+	}
+
+	return DeploymentResource{
+		Service:         status.Name,
+		Image:           image,
+		Verdict:         status.Verdict(),
+		Replicas:        status.Replicas,
+		RunningReplicas: status.RunningReplicas,
+		FailedReplicas:  status.FailedReplicas,
+		UpdateStatus:    status.UpdateStatus,
+		Err:             status.Err,
+		Tasks:           tasks,
+	}
+}
+
+// newStackResources groups the services by the stack they were deployed with
+func newStackResources(services []ServiceResource) []StackResource {
+	stacks := map[string]*service.StackStatus{}
+	for _, resource := range services {
+		if resource.Stack == "" {
+			continue
+		}
+
+		stack, ok := stacks[resource.Stack]
+		if !ok {
+			stack = &service.StackStatus{Name: resource.Stack}
+			stacks[resource.Stack] = stack
+		}
+		stack.Services = append(stack.Services, resourceStatus(resource))
+	}
+
+	resources := []StackResource{}
+	for name, stack := range stacks {
+		resource := StackResource{Name: name, Verdict: stack.Verdict(), Services: []string{}}
+		for _, serviceStatus := range stack.Services {
+			resource.Services = append(resource.Services, serviceStatus.Name)
+		}
+		resources = append(resources, resource)
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Name < resources[j].Name
+	})
+
+	return resources
+}
+
+// resourceStatus converts the resource back to the status the verdict is computed from
+func resourceStatus(resource ServiceResource) service.ServiceStatus {
+	return service.ServiceStatus{
+		ID:              resource.ID,
+		Name:            resource.Name,
+		Err:             resource.Err,
+		Replicas:        resource.Replicas,
+		RunningReplicas: resource.RunningReplicas,
+		FailedReplicas:  resource.FailedReplicas,
+		UpdateStatus:    resource.UpdateStatus,
+	}
+}
+
+func serviceMode(mode swarm.ServiceMode) string {
+	if mode.Global != nil {
+		return "global"
+	}
+
+	return "replicated"
+}
+
+func resourceReplicas(replicas *uint64, running int) string {
+	if replicas == nil {
+		return fmt.Sprintf("%d", running)
+	}
+
+	return fmt.Sprintf("%d/%d", running, *replicas)
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
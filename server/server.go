@@ -1,20 +1,47 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/base64"
-	"encoding/json"
-	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/albertogviana/docker-swarm-service-status/audit"
 	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/albertogviana/docker-swarm-service-status/webhook"
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Server defined structure
 type Server struct {
+	// Service is the cluster used when a request does not name one
 	Service service.Services
+	// Clusters holds the named clusters, requests select one with the cluster path segment or the X-Swarm-Cluster header
+	Clusters map[string]service.Services
+	// Authenticator protects every route except the health check, it is disabled when nil
+	Authenticator Authenticator
+	// Webhooks delivers the deployment events, its delivery log is served when not nil
+	Webhooks *webhook.Dispatcher
+	// Cache keeps the service and deployment statuses for a short time, it is disabled when nil
+	Cache *Cache
+	// RateLimiter limits the requests of every client, it is disabled when nil
+	RateLimiter *RateLimiter
+	// AccessLog receives one JSON line per request, requests are not logged when nil
+	AccessLog io.Writer
+	// CORS lets browsers on other origins call the API, it is disabled when nil
+	CORS *CORS
+	// Audit records who queried what, it is disabled when nil
+	Audit *audit.Log
+	// PublicBadges serves the badges without credentials, so they can be embedded in READMEs and wikis
+	PublicBadges bool
 }
 
 //Response message
@@ -25,30 +52,196 @@ type Response struct {
 // NewServer returns a new instance of the Server structure
 func NewServer(service service.Services) *Server {
 	return &Server{
-		service,
+		Service: service,
 	}
 }
 
-// Run bootstrap the server
-func (s *Server) Run() {
+// Run bootstrap the server and blocks until it receives SIGINT or SIGTERM,
+// then waits up to config.ShutdownTimeout for in-flight requests to finish
+func (s *Server) Run(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return err
+	}
+
+	var grpcListener net.Listener
+	if config.GRPCAddress != "" {
+		grpcListener, err = net.Listen("tcp", config.GRPCAddress)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	return s.serve(listener, grpcListener, config, stop)
+}
+
+// serve serves HTTP on listener and, when grpcListener is not nil, the gRPC API on grpcListener
+func (s *Server) serve(listener net.Listener, grpcListener net.Listener, config Config, stop <-chan os.Signal) error {
 	log.Println("Docker Service Status Starting")
 
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		listener.Close()
+		if grpcListener != nil {
+			grpcListener.Close()
+		}
+		return err
+	}
+
+	httpServer := &http.Server{
+		Handler:      s.Handler(),
+		TLSConfig:    tlsConfig,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		IdleTimeout:  config.IdleTimeout,
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		if config.TLSEnabled() {
+			errs <- httpServer.ServeTLS(listener, config.TLSCertFile, config.TLSKeyFile)
+			return
+		}
+		errs <- httpServer.Serve(listener)
+	}()
+
+	log.Printf("Docker Service Status Started on %s", listener.Addr())
+
+	var grpcServer *grpc.Server
+	if grpcListener != nil {
+		grpcServer, err = s.grpcServer(config, tlsConfig)
+		if err != nil {
+			httpServer.Close()
+			grpcListener.Close()
+			return err
+		}
+
+		go func() {
+			errs <- grpcServer.Serve(grpcListener)
+		}()
+
+		log.Printf("Docker Service Status gRPC API Started on %s", grpcListener.Addr())
+	}
+
+	select {
+	case err := <-errs:
+		httpServer.Close()
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		return err
+	case sig := <-stop:
+		log.Printf("Received %s, draining connections for up to %s", sig, config.ShutdownTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	if grpcServer != nil {
+		go stopGRPC(ctx, grpcServer)
+	}
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		// The timeout is reached, the connections still open are closed so the server stops in time
+		httpServer.Close()
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		return err
+	}
+
+	log.Println("Docker Service Status Stopped")
+	return nil
+}
+
+// grpcServer returns the gRPC server, sharing the TLS settings of the HTTP server
+func (s *Server) grpcServer(config Config, tlsConfig *tls.Config) (*grpc.Server, error) {
+	if tlsConfig == nil {
+		return s.GRPCServer(), nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.Certificates = []tls.Certificate{certificate}
+
+	return s.GRPCServer(grpc.Creds(credentials.NewTLS(tlsConfig))), nil
+}
+
+// stopGRPC waits for the running calls to finish, such as WatchDeployment streams, until ctx is done
+func stopGRPC(ctx context.Context, grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+}
+
+// Handler returns the HTTP handler serving every route of the server, every request is traced, gets an
+// X-Request-ID and is written to the access log
+func (s *Server) Handler() http.Handler {
 	r := mux.NewRouter().StrictSlash(true).UseEncodedPath()
 	router(r, s)
+	r.Use(recordRoute)
 
-	log.Println("Docker Service Status Started")
-	log.Fatal(http.ListenAndServe("0.0.0.0:8080", r))
+	return traceRequests(s.logRequests(r))
 }
 
 func router(r *mux.Router, s *Server) {
-	r.HandleFunc("/v1/docker-swarm-service-status/service-status/{service}", s.ServiceStatusHandler).Methods("GET")
-	r.HandleFunc("/v1/docker-swarm-service-status/deployment-status/{service}/{image}", s.DeploymentStatusHandler).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/service-status/{service}", s.authenticate(s.ServiceStatusHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/deployment-status/{service}/{image}", s.authenticate(s.DeploymentStatusHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/stack-status/{stack}", s.authenticate(s.StackStatusHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/info", s.authenticate(s.InfoHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/clusters", s.authenticate(s.ClustersHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/clusters/{cluster}/service-status/{service}", s.authenticate(s.ServiceStatusHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/clusters/{cluster}/deployment-status/{service}/{image}", s.authenticate(s.DeploymentStatusHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/clusters/{cluster}/stack-status/{stack}", s.authenticate(s.StackStatusHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/clusters/{cluster}/info", s.authenticate(s.InfoHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/batch/deployment-status", s.authenticate(s.BatchDeploymentStatusHandler)).Methods("POST")
+	r.HandleFunc("/v1/docker-swarm-service-status/clusters/{cluster}/batch/deployment-status", s.authenticate(s.BatchDeploymentStatusHandler)).Methods("POST")
+	r.HandleFunc("/v1/docker-swarm-service-status/cross-cluster/service-status/{service}", s.authenticate(s.CrossClusterServiceStatusHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/badge/service/{service}", s.badge(s.ServiceBadgeHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/badge/stack/{stack}", s.badge(s.StackBadgeHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/clusters/{cluster}/badge/service/{service}", s.badge(s.ServiceBadgeHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/clusters/{cluster}/badge/stack/{stack}", s.badge(s.StackBadgeHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/dashboard", s.authenticate(s.DashboardHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/dashboard/services/{service}", s.authenticate(s.DashboardServiceHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/dashboard/clusters/{cluster}", s.authenticate(s.DashboardHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/dashboard/clusters/{cluster}/services/{service}", s.authenticate(s.DashboardServiceHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/webhooks/deliveries", s.authenticate(s.WebhookDeliveriesHandler)).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/audit", s.authenticate(s.AuditHandler)).Methods("GET")
 	r.HandleFunc("/v1/docker-swarm-service-status/health", s.HealthHandler).Methods("GET")
+	r.HandleFunc("/v1/docker-swarm-service-status/ready", s.ReadinessHandler).Methods("GET")
+	r.HandleFunc(OpenAPIPath, s.OpenAPIHandler).Methods("GET")
+
+	routerV2(r, s)
+
+	if s.CORS != nil {
+		if err := s.routePreflights(r); err != nil {
+			log.Println(err)
+		}
+		r.Use(s.cors)
+	}
 }
 
 // DeploymentStatusHandler returns the current state of the service
 func (s *Server) DeploymentStatusHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
 
 	serviceName := vars["service"]
@@ -56,49 +249,131 @@ func (s *Server) DeploymentStatusHandler(w http.ResponseWriter, r *http.Request)
 
 	imageByte, err := base64.URLEncoding.DecodeString(image)
 	if err != nil {
-		log.Println(err)
-		w.WriteHeader(http.StatusBadRequest)
-		io.WriteString(w, `{"error": "Invalid base64 encode for the image parameter."}`)
+		logError(r, err)
+		renderError(w, r, http.StatusBadRequest, "Invalid base64 encode for the image parameter.")
 		return
 	}
 
-	status, err := s.Service.GetDeploymentStatus(serviceName, string(imageByte))
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := s.Cache.ServiceStatus(cacheKey(r), func() (service.ServiceStatus, error) {
+		return svc.GetDeploymentStatus(serviceName, string(imageByte))
+	})
 	if err != nil {
-		log.Println(err)
-		w.WriteHeader(http.StatusInternalServerError)
-		io.WriteString(w, fmt.Sprintf(`{"error": "%s"}`, err.Error()))
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if s.notModified(w, r, status) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	js, _ := json.Marshal(status)
-	w.Write(js)
+	render(w, r, http.StatusOK, status)
 }
 
 // ServiceStatusHandler returns the current state of the service
 func (s *Server) ServiceStatusHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
 
 	serviceName := vars["service"]
 
-	status, err := s.Service.GetServiceStatus(serviceName)
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := s.Cache.ServiceStatus(cacheKey(r), func() (service.ServiceStatus, error) {
+		return svc.GetServiceStatus(serviceName)
+	})
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if s.notModified(w, r, status) {
+		return
+	}
+
+	render(w, r, http.StatusOK, status)
+}
+
+// StackStatusHandler returns the current state of every service of the stack
+func (s *Server) StackStatusHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	stackName := vars["stack"]
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := svc.GetStackStatus(stackName)
 	if err != nil {
-		log.Println(err)
-		w.WriteHeader(http.StatusInternalServerError)
-		io.WriteString(w, fmt.Sprintf(`{"error": "%s"}`, err.Error()))
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	render(w, r, http.StatusOK, status)
+}
+
+// InfoHandler returns the negotiated Docker API version and the features supported by the daemon
+func (s *Server) InfoHandler(w http.ResponseWriter, r *http.Request) {
+	svc, ok := s.cluster(w, r)
+	if !ok {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	js, _ := json.Marshal(status)
-	w.Write(js)
+	info, err := svc.GetInfo()
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	render(w, r, http.StatusOK, info)
+}
+
+// WebhookDeliveriesHandler returns the most recent webhook deliveries of the services the caller may query
+func (s *Server) WebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	deliveries := webhook.Deliveries{}
+	if s.Webhooks != nil {
+		deliveries = s.Webhooks.Deliveries()
+	}
+
+	if identity, ok := IdentityFromContext(r.Context()); ok {
+		allowed := webhook.Deliveries{}
+		for _, delivery := range deliveries {
+			if identity.CanQueryService(delivery.Service) {
+				allowed = append(allowed, delivery)
+			}
+		}
+		deliveries = allowed
+	}
+
+	render(w, r, http.StatusOK, deliveries)
 }
 
 // HealthHandler is used for health checks
-func (s *Server) HealthHandler(w http.ResponseWriter, req *http.Request) {
-	js, _ := json.Marshal(Response{Status: "OK"})
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(js)
+func (s *Server) HealthHandler(w http.ResponseWriter, r *http.Request) {
+	render(w, r, http.StatusOK, Response{Status: "OK"})
+}
+
+// ReadinessHandler is used for readiness checks, it returns 503 when the Docker daemon is unreachable
+// or the node is not an active swarm manager
+func (s *Server) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	readiness := s.Service.GetReadiness()
+
+	if !readiness.Ready {
+		render(w, r, http.StatusServiceUnavailable, readiness)
+		return
+	}
+
+	render(w, r, http.StatusOK, readiness)
 }
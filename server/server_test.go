@@ -1,12 +1,17 @@
 package server
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"syscall"
 	"testing"
 	"time"
 
@@ -43,13 +48,12 @@ func (s *ServerTestSuite) Test_DeploymentStatus_ReturnSuccess() {
 	taskStatus := []service.TaskStatus{}
 
 	ts := service.TaskStatus{
-		"evv1jw9o7981mrp0p50j1gy5k",
-		time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC),
-		"running",
-		"running",
-		"started",
-		"",
-		"albertogviana/docker-routing-mesh:1.0.0@sha256:87e5c74f8042848893440b24a33ea0e3494b9da475987b0e704f0d3262bce3cd",
+		TaskID:       "evv1jw9o7981mrp0p50j1gy5k",
+		Timestamp:    time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC),
+		DesiredState: "running",
+		State:        "running",
+		Message:      "started",
+		Image:        "albertogviana/docker-routing-mesh:1.0.0@sha256:87e5c74f8042848893440b24a33ea0e3494b9da475987b0e704f0d3262bce3cd",
 	}
 
 	taskStatus = append(taskStatus, ts)
@@ -57,14 +61,11 @@ func (s *ServerTestSuite) Test_DeploymentStatus_ReturnSuccess() {
 	replicas := uint64(1)
 
 	deploymentStatusMock := service.ServiceStatus{
-		"tt3otdsnkd1kgh80u45bwmcb4",
-		"docker-routing-mesh",
-		"",
-		taskStatus,
-		&replicas,
-		1,
-		0,
-		nil,
+		ID:              "tt3otdsnkd1kgh80u45bwmcb4",
+		Name:            "docker-routing-mesh",
+		TaskStatus:      taskStatus,
+		Replicas:        &replicas,
+		RunningReplicas: 1,
 	}
 
 	data, _ := json.Marshal(deploymentStatusMock)
@@ -74,7 +75,7 @@ func (s *ServerTestSuite) Test_DeploymentStatus_ReturnSuccess() {
 
 	serviceMock.On("GetDeploymentStatus", serviceName, image).Return(deploymentStatusMock, nil)
 	server := &Server{
-		serviceMock,
+		Service: serviceMock,
 	}
 
 	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
@@ -92,6 +93,34 @@ func (s *ServerTestSuite) Test_DeploymentStatus_ReturnSuccess() {
 	s.Equal(string(data), rec.Body.String())
 }
 
+func (s *ServerTestSuite) Test_DeploymentStatus_ReturnJUnit() {
+	serviceMock := new(ServiceMock)
+
+	serviceName := "docker-routing-mesh"
+	image := "albertogviana/docker-routing-mesh:1.0.0"
+
+	serviceMock.On("GetDeploymentStatus", serviceName, image).Return(service.ServiceStatus{Name: serviceName, Err: "The docker-routing-mesh service was not found in the cluster."}, nil)
+	server := &Server{
+		Service: serviceMock,
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	imageByte := base64.URLEncoding.EncodeToString([]byte(image))
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/docker-swarm-service-status/deployment-status/%s/%s", serviceName, imageByte), nil)
+	req.Header.Set("Accept", "application/junit+xml")
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.Equal("application/junit+xml", rec.Header().Get("Content-Type"))
+	s.Contains(rec.Body.String(), `<testsuites name="docker-swarm-service-status" tests="1" failures="1">`)
+	s.Contains(rec.Body.String(), `<failure message="The docker-routing-mesh service was not found in the cluster." type="not-found">`)
+}
+
 func (s *ServerTestSuite) Test_DeploymentStatus_InvalidBase64Parameter() {
 	serviceMock := new(ServiceMock)
 
@@ -99,7 +128,7 @@ func (s *ServerTestSuite) Test_DeploymentStatus_InvalidBase64Parameter() {
 	image := "docker-routing-mesh:1.0.0"
 
 	server := &Server{
-		serviceMock,
+		Service: serviceMock,
 	}
 
 	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
@@ -123,7 +152,7 @@ func (s *ServerTestSuite) Test_DeploymentStatus_ReturnError() {
 
 	serviceMock.On("GetDeploymentStatus", serviceName, image).Return(service.ServiceStatus{}, errors.New("Not able to connect on unix:///var/run/docker.sock"))
 	server := &Server{
-		serviceMock,
+		Service: serviceMock,
 	}
 
 	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
@@ -148,7 +177,7 @@ func (s *ServerTestSuite) Test_ServiceStatus_ReturnError() {
 
 	serviceMock.On("GetServiceStatus", serviceName).Return(service.ServiceStatus{}, errors.New("Not able to connect on unix:///var/run/docker.sock"))
 	server := &Server{
-		serviceMock,
+		Service: serviceMock,
 	}
 
 	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
@@ -170,13 +199,12 @@ func (s *ServerTestSuite) Test_ServiceStatus_ReturnSuccess() {
 	taskStatus := []service.TaskStatus{}
 
 	ts := service.TaskStatus{
-		"evv1jw9o7981mrp0p50j1gy5k",
-		time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC),
-		"running",
-		"running",
-		"started",
-		"",
-		"albertogviana/docker-routing-mesh:1.0.0@sha256:87e5c74f8042848893440b24a33ea0e3494b9da475987b0e704f0d3262bce3cd",
+		TaskID:       "evv1jw9o7981mrp0p50j1gy5k",
+		Timestamp:    time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC),
+		DesiredState: "running",
+		State:        "running",
+		Message:      "started",
+		Image:        "albertogviana/docker-routing-mesh:1.0.0@sha256:87e5c74f8042848893440b24a33ea0e3494b9da475987b0e704f0d3262bce3cd",
 	}
 
 	taskStatus = append(taskStatus, ts)
@@ -184,14 +212,11 @@ func (s *ServerTestSuite) Test_ServiceStatus_ReturnSuccess() {
 	replicas := uint64(1)
 
 	deploymentStatusMock := service.ServiceStatus{
-		"tt3otdsnkd1kgh80u45bwmcb4",
-		"docker-routing-mesh",
-		"",
-		taskStatus,
-		&replicas,
-		1,
-		0,
-		nil,
+		ID:              "tt3otdsnkd1kgh80u45bwmcb4",
+		Name:            "docker-routing-mesh",
+		TaskStatus:      taskStatus,
+		Replicas:        &replicas,
+		RunningReplicas: 1,
 	}
 
 	data, _ := json.Marshal(deploymentStatusMock)
@@ -200,7 +225,7 @@ func (s *ServerTestSuite) Test_ServiceStatus_ReturnSuccess() {
 
 	serviceMock.On("GetServiceStatus", serviceName).Return(deploymentStatusMock, nil)
 	server := &Server{
-		serviceMock,
+		Service: serviceMock,
 	}
 
 	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
@@ -216,6 +241,232 @@ func (s *ServerTestSuite) Test_ServiceStatus_ReturnSuccess() {
 	s.Equal(string(data), rec.Body.String())
 }
 
+func (s *ServerTestSuite) Test_StackStatus_ReturnSuccess() {
+	serviceMock := new(ServiceMock)
+
+	replicas := uint64(1)
+	stackStatus := service.StackStatus{
+		Name:     "prod",
+		Services: []service.ServiceStatus{{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web", Replicas: &replicas, RunningReplicas: 1}},
+	}
+	data, _ := json.Marshal(stackStatus)
+
+	serviceMock.On("GetStackStatus", "prod").Return(stackStatus, nil)
+	server := &Server{
+		Service: serviceMock,
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/stack-status/prod", nil)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.Equal(string(data), rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_StackStatus_ReturnError() {
+	serviceMock := new(ServiceMock)
+
+	serviceMock.On("GetStackStatus", "prod").Return(service.StackStatus{}, errors.New("Not able to connect on unix:///var/run/docker.sock"))
+	server := &Server{
+		Service: serviceMock,
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/stack-status/prod", nil)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(500, rec.Code)
+	s.Equal("{\"error\": \"Not able to connect on unix:///var/run/docker.sock\"}", rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_Info_ReturnSuccess() {
+	serviceMock := new(ServiceMock)
+
+	info := service.Info{
+		APIVersion:       "1.41",
+		ServerAPIVersion: "1.43",
+		ServerVersion:    "24.0.7",
+		Features:         []service.Feature{{Name: "job-modes", Description: "Replicated and global job services are listed.", MinAPIVersion: "1.41", Supported: true}},
+	}
+	data, _ := json.Marshal(info)
+
+	serviceMock.On("GetInfo").Return(info, nil)
+	server := &Server{
+		Service: serviceMock,
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/info", nil)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.Equal(string(data), rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_Info_ReturnError() {
+	serviceMock := new(ServiceMock)
+
+	serviceMock.On("GetInfo").Return(service.Info{}, errors.New("Not able to connect on unix:///var/run/docker.sock"))
+	server := &Server{
+		Service: serviceMock,
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/info", nil)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(500, rec.Code)
+	s.Equal("{\"error\": \"Not able to connect on unix:///var/run/docker.sock\"}", rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_Readiness_ReturnSuccess() {
+	serviceMock := new(ServiceMock)
+
+	readiness := service.Readiness{
+		Ready:      true,
+		APIVersion: "1.33",
+		Latency:    "1.2ms",
+		Checks:     []service.Check{{Name: "docker", OK: true}, {Name: "swarm", OK: true}, {Name: "manager", OK: true}},
+	}
+	data, _ := json.Marshal(readiness)
+
+	serviceMock.On("GetReadiness").Return(readiness)
+	server := &Server{
+		Service: serviceMock,
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/ready", nil)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.Equal(string(data), rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_Readiness_ReturnServiceUnavailable() {
+	serviceMock := new(ServiceMock)
+
+	readiness := service.Readiness{
+		APIVersion: "1.33",
+		Latency:    "1.2ms",
+		Checks:     []service.Check{{Name: "docker", OK: true}, {Name: "swarm", OK: true}, {Name: "manager", OK: false, Message: "The node is not a swarm manager."}},
+	}
+	data, _ := json.Marshal(readiness)
+
+	serviceMock.On("GetReadiness").Return(readiness)
+	server := &Server{
+		Service: serviceMock,
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/ready", nil)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(503, rec.Code)
+	s.Equal(string(data), rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_Serve_DrainsInFlightRequestsOnShutdown() {
+	serviceMock := new(ServiceMock)
+	serviceName := "docker-routing-mesh"
+
+	serviceMock.On("GetServiceStatus", serviceName).After(200*time.Millisecond).Return(service.ServiceStatus{Name: serviceName}, nil)
+	server := NewServer(serviceMock)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+
+	stop := make(chan os.Signal, 1)
+	served := make(chan error, 1)
+	go func() {
+		served <- server.serve(listener, nil, DefaultConfig(), stop)
+	}()
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get(fmt.Sprintf("http://%s/v1/docker-swarm-service-status/service-status/%s", listener.Addr(), serviceName))
+		s.NoError(err)
+		responses <- resp
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	stop <- syscall.SIGTERM
+
+	resp := <-responses
+	s.Require().NotNil(resp)
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	s.Equal(200, resp.StatusCode)
+	s.Equal(`{"Name":"docker-routing-mesh"}`, string(body))
+	s.NoError(<-served)
+}
+
+func (s *ServerTestSuite) Test_Serve_ClosesConnectionsWhenTheShutdownTimesOut() {
+	serviceMock := new(ServiceMock)
+	serviceName := "docker-routing-mesh"
+
+	serviceMock.On("GetServiceStatus", serviceName).After(2*time.Second).Return(service.ServiceStatus{Name: serviceName}, nil)
+	server := NewServer(serviceMock)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+
+	config := DefaultConfig()
+	config.ShutdownTimeout = 100 * time.Millisecond
+
+	stop := make(chan os.Signal, 1)
+	served := make(chan error, 1)
+	go func() {
+		served <- server.serve(listener, nil, config, stop)
+	}()
+
+	errs := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(fmt.Sprintf("http://%s/v1/docker-swarm-service-status/service-status/%s", listener.Addr(), serviceName))
+		if err == nil {
+			resp.Body.Close()
+		}
+		errs <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	stop <- syscall.SIGTERM
+
+	select {
+	case err := <-served:
+		s.Equal(context.DeadlineExceeded, err)
+	case <-time.After(time.Second):
+		s.Fail("The server did not stop within the shutdown timeout")
+	}
+	s.Error(<-errs)
+}
+
 type ServiceMock struct {
 	mock.Mock
 }
@@ -230,6 +481,26 @@ func (s *ServiceMock) GetServiceStatus(serviceName string) (service.ServiceStatu
 	return args.Get(0).(service.ServiceStatus), args.Error(1)
 }
 
+func (s *ServiceMock) GetReadiness() service.Readiness {
+	args := s.Called()
+	return args.Get(0).(service.Readiness)
+}
+
+func (s *ServiceMock) GetInfo() (service.Info, error) {
+	args := s.Called()
+	return args.Get(0).(service.Info), args.Error(1)
+}
+
+func (s *ServiceMock) GetStackStatus(stackName string) (service.StackStatus, error) {
+	args := s.Called(stackName)
+	return args.Get(0).(service.StackStatus), args.Error(1)
+}
+
+func (s *ServiceMock) GetServices(filter filters.Args) ([]swarm.Service, error) {
+	args := s.Called(filter)
+	return args.Get(0).([]swarm.Service), args.Error(1)
+}
+
 func (s *ServiceMock) GetService(filter filters.Args) (swarm.Service, error) {
 	args := s.Called(filter)
 	return args.Get(0).(swarm.Service), args.Error(1)
@@ -239,3 +510,8 @@ func (s *ServiceMock) GetTask(filter filters.Args) ([]swarm.Task, error) {
 	args := s.Called(filter)
 	return args.Get(0).([]swarm.Task), args.Error(1)
 }
+
+func (s *ServiceMock) GetNodes(filter filters.Args) ([]swarm.Node, error) {
+	args := s.Called(filter)
+	return args.Get(0).([]swarm.Node), args.Error(1)
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TracerName is the instrumentation name of the spans of the HTTP requests
+const TracerName = "github.com/albertogviana/docker-swarm-service-status/server"
+
+var tracer = otel.Tracer(TracerName)
+
+// traceContext reads the W3C traceparent and tracestate headers of the requests
+var traceContext = propagation.TraceContext{}
+
+// traceRequests starts a server span for every request, continuing the trace of the W3C trace context headers sent
+// by the client. The span is named after the matched route by recordRoute.
+func traceRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := traceContext.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("http.request.method", r.Method), attribute.String("url.path", r.URL.Path)))
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("%d %s", status, http.StatusText(status)))
+		}
+	})
+}
+
+// traceUnary starts a server span for every unary call, continuing the trace of the W3C trace context metadata sent
+// by the client
+func traceUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, end := startRPC(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	end(err)
+
+	return resp, err
+}
+
+// traceStream starts a server span for every streaming call, continuing the trace of the W3C trace context metadata
+// sent by the client
+func traceStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, end := startRPC(stream.Context(), info.FullMethod)
+	err := handler(srv, &tracedStream{ServerStream: stream, ctx: ctx})
+	end(err)
+
+	return err
+}
+
+// startRPC starts the server span of a call and returns the function ending it with the status of the call
+func startRPC(ctx context.Context, method string) (context.Context, func(err error)) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = traceContext.Extract(ctx, metadataCarrier(md))
+	ctx, span := tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("rpc.system", "grpc"), attribute.String("rpc.method", method)))
+
+	return ctx, func(err error) {
+		code := status.Code(err)
+		span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+		if err != nil {
+			span.SetStatus(codes.Error, code.String())
+		}
+		span.End()
+	}
+}
+
+// tracedStream carries the span of the call in the stream context
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (t *tracedStream) Context() context.Context {
+	return t.ctx
+}
+
+// metadataCarrier adapts the gRPC metadata to a propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+// Get implements propagation.TextMapCarrier
+func (m metadataCarrier) Get(key string) string {
+	if values := metadata.MD(m).Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// Set implements propagation.TextMapCarrier
+func (m metadataCarrier) Set(key string, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+// Keys implements propagation.TextMapCarrier
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	spanRecorder     = tracetest.NewSpanRecorder()
+	spanRecorderOnce sync.Once
+)
+
+// recordedSpans returns the spans ended so far with the trace ID, the global tracer provider can only be set once
+func recordedSpans(traceID string) []sdktrace.ReadOnlySpan {
+	spanRecorderOnce.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	})
+
+	spans := []sdktrace.ReadOnlySpan{}
+	for _, span := range spanRecorder.Ended() {
+		if span.SpanContext().TraceID().String() == traceID {
+			spans = append(spans, span)
+		}
+	}
+
+	return spans
+}
+
+func (s *ServerTestSuite) Test_Handler_TracesRequestsAndDockerCalls() {
+	recordedSpans("")
+
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer daemon.Close()
+
+	svc, err := service.NewServiceWithConfig(service.Config{Host: "tcp://" + strings.TrimPrefix(daemon.URL, "http://"), APIVersion: "v1.33"})
+	s.Require().NoError(err)
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	rec, entry := s.serveLoggedWith(&Server{Service: svc}, "/v1/docker-swarm-service-status/stack-status/billing", map[string]string{
+		"traceparent": "00-" + traceID + "-00f067aa0ba902b7-01",
+	})
+
+	s.Equal(200, rec.Code)
+	s.Equal(traceID, entry["trace_id"])
+
+	spans := recordedSpans(traceID)
+	s.Require().Len(spans, 2)
+
+	docker, request := spans[0], spans[1]
+	s.Equal("GET /v1/docker-swarm-service-status/stack-status/{stack}", request.Name())
+	s.Equal("00f067aa0ba902b7", request.Parent().SpanID().String())
+	s.Contains(request.Attributes(), attribute.String("swarm.stack", "billing"))
+	s.Contains(request.Attributes(), attribute.Int("http.response.status_code", 200))
+
+	s.Equal("docker.ServiceList", docker.Name())
+	s.Equal(request.SpanContext().SpanID(), docker.Parent().SpanID())
+	s.Contains(docker.Attributes(), attribute.String("docker.operation", "ServiceList"))
+}
+
+func (s *ServerTestSuite) Test_Handler_StartsTraceWithoutTraceContext() {
+	recordedSpans("")
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServiceStatus", "billing_api").Return(service.ServiceStatus{Name: "billing_api"}, nil)
+
+	rec, entry := s.serveLogged(&Server{Service: serviceMock}, "/v1/docker-swarm-service-status/service-status/billing_api", "")
+
+	s.Equal(200, rec.Code)
+	traceID, _ := entry["trace_id"].(string)
+	s.Regexp("^[0-9a-f]{32}$", traceID)
+
+	spans := recordedSpans(traceID)
+	s.Require().Len(spans, 1)
+	s.False(spans[0].Parent().IsValid())
+	s.Contains(spans[0].Attributes(), attribute.String("http.request.id", rec.Header().Get(RequestIDHeader)))
+}
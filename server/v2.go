@@ -0,0 +1,724 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/gorilla/mux"
+)
+
+// V2Path is the prefix of the resource-oriented v2 API, requests select a cluster with the X-Swarm-Cluster header
+const V2Path = "/v2/docker-swarm-service-status"
+
+const (
+	// DefaultPageLimit is the page size of the v2 collections when ?limit= is absent
+	DefaultPageLimit = 50
+	// MaxPageLimit is the largest page size a client may request
+	MaxPageLimit = 500
+)
+
+func routerV2(r *mux.Router, s *Server) {
+	r.HandleFunc(V2Path+"/services", s.authenticate(s.ListServicesHandler)).Methods("GET")
+	r.HandleFunc(V2Path+"/services/{service}", s.authenticate(s.GetServiceHandler)).Methods("GET")
+	r.HandleFunc(V2Path+"/services/{service}/tasks", s.authenticate(s.ListServiceTasksHandler)).Methods("GET")
+	r.HandleFunc(V2Path+"/tasks", s.authenticate(s.ListTasksHandler)).Methods("GET")
+	r.HandleFunc(V2Path+"/tasks/{task}", s.authenticate(s.GetTaskHandler)).Methods("GET")
+	r.HandleFunc(V2Path+"/nodes", s.authenticate(s.ListNodesHandler)).Methods("GET")
+	r.HandleFunc(V2Path+"/nodes/{node}", s.authenticate(s.GetNodeHandler)).Methods("GET")
+	r.HandleFunc(V2Path+"/stacks", s.authenticate(s.ListStacksHandler)).Methods("GET")
+	r.HandleFunc(V2Path+"/stacks/{stack}", s.authenticate(s.GetStackHandler)).Methods("GET")
+	r.HandleFunc(V2Path+"/deployments/{service}", s.authenticate(s.GetDeploymentHandler)).Methods("GET")
+}
+
+// isV2 reports whether the request targets the v2 API, which answers errors with an ErrorEnvelope
+func isV2(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, V2Path+"/")
+}
+
+// ListServicesHandler returns a page of services, filtered by ?name= (a glob), ?stack=, ?label= and ?verdict=
+func (s *Server) ListServicesHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	p, err := parsePage(r)
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	verdict, err := parseVerdict(query.Get("verdict"))
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	filter := filters.NewArgs()
+	if stackName := query.Get("stack"); stackName != "" {
+		filter.Add("label", fmt.Sprintf("%s=%s", service.StackNamespaceLabel, stackName))
+	}
+	for _, label := range query["label"] {
+		filter.Add("label", label)
+	}
+
+	swarmServices, err := svc.GetServices(filter)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	swarmServices, err = allowedServices(r, swarmServices, query.Get("name"))
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// without a verdict filter only the statuses of the requested page are computed
+	if verdict == "" {
+		start, end := p.bounds(len(swarmServices))
+		resources, err := serviceResources(svc, swarmServices[start:end])
+		if err != nil {
+			logError(r, err)
+			renderError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		render(w, r, http.StatusOK, p.list(r, ServiceResources(resources), len(swarmServices)))
+		return
+	}
+
+	resources, err := serviceResources(svc, swarmServices)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	matching := ServiceResources{}
+	for _, resource := range resources {
+		if resource.Verdict == verdict {
+			matching = append(matching, resource)
+		}
+	}
+
+	start, end := p.bounds(len(matching))
+	render(w, r, http.StatusOK, p.list(r, matching[start:end], len(matching)))
+}
+
+// GetServiceHandler returns a service with its replicas and verdict
+func (s *Server) GetServiceHandler(w http.ResponseWriter, r *http.Request) {
+	serviceName := mux.Vars(r)["service"]
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	swarmService, found, err := findService(svc, serviceName)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !found {
+		renderError(w, r, http.StatusNotFound, fmt.Sprintf("The %s service was not found in the cluster.", serviceName))
+		return
+	}
+
+	status, err := service.StatusOf(svc, swarmService)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	render(w, r, http.StatusOK, newServiceResource(swarmService, status))
+}
+
+// ListServiceTasksHandler returns a page of the tasks of a service, filtered by ?state=
+func (s *Server) ListServiceTasksHandler(w http.ResponseWriter, r *http.Request) {
+	serviceName := mux.Vars(r)["service"]
+
+	p, err := parsePage(r)
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	swarmService, found, err := findService(svc, serviceName)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !found {
+		renderError(w, r, http.StatusNotFound, fmt.Sprintf("The %s service was not found in the cluster.", serviceName))
+		return
+	}
+
+	filter := filters.NewArgs()
+	filter.Add("service", swarmService.ID)
+
+	s.listTasks(w, r, svc, p, filter, map[string]string{swarmService.ID: serviceName})
+}
+
+// ListTasksHandler returns a page of tasks, filtered by ?service=, ?node= and ?state=
+func (s *Server) ListTasksHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	p, err := parsePage(r)
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	names, err := serviceNames(svc, filters.NewArgs())
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	filter := filters.NewArgs()
+	if serviceName := query.Get("service"); serviceName != "" {
+		filter.Add("service", serviceName)
+	}
+	if node := query.Get("node"); node != "" {
+		filter.Add("node", node)
+	}
+
+	s.listTasks(w, r, svc, p, filter, names)
+}
+
+// listTasks renders the page of the tasks matching the filter and the ?state= parameter. Tasks of services
+// missing from names or the caller may not query are left out.
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request, svc service.Services, p page, filter filters.Args, names map[string]string) {
+	state := swarm.TaskState(r.URL.Query().Get("state"))
+
+	tasks, err := svc.GetTask(filter)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	identity, authenticated := IdentityFromContext(r.Context())
+
+	resources := TaskResources{}
+	for _, task := range tasks {
+		serviceName, ok := names[task.ServiceID]
+		if !ok || (authenticated && !identity.CanQueryService(serviceName)) {
+			continue
+		}
+
+		if state != "" && task.Status.State != state {
+			continue
+		}
+
+		resources = append(resources, newTaskResource(task, serviceName))
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].ServiceName != resources[j].ServiceName {
+			return resources[i].ServiceName < resources[j].ServiceName
+		}
+		if resources[i].Slot != resources[j].Slot {
+			return resources[i].Slot < resources[j].Slot
+		}
+		return resources[i].CreatedAt.After(resources[j].CreatedAt)
+	})
+
+	start, end := p.bounds(len(resources))
+	render(w, r, http.StatusOK, p.list(r, resources[start:end], len(resources)))
+}
+
+// GetTaskHandler returns a task by its ID
+func (s *Server) GetTaskHandler(w http.ResponseWriter, r *http.Request) {
+	taskID := mux.Vars(r)["task"]
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	filter := filters.NewArgs()
+	filter.Add("id", taskID)
+	tasks, err := svc.GetTask(filter)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var task *swarm.Task
+	for i := range tasks {
+		if tasks[i].ID == taskID {
+			task = &tasks[i]
+		}
+	}
+
+	if task == nil {
+		renderError(w, r, http.StatusNotFound, fmt.Sprintf("The %s task was not found in the cluster.", taskID))
+		return
+	}
+
+	filter = filters.NewArgs()
+	filter.Add("id", task.ServiceID)
+	names, err := serviceNames(svc, filter)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	serviceName := names[task.ServiceID]
+	if identity, ok := IdentityFromContext(r.Context()); ok && !identity.CanQueryService(serviceName) {
+		renderError(w, r, http.StatusForbidden, fmt.Sprintf("%s is not allowed to query the %s service.", identity.Name, serviceName))
+		return
+	}
+
+	render(w, r, http.StatusOK, newTaskResource(*task, serviceName))
+}
+
+// ListNodesHandler returns a page of the swarm nodes, filtered by ?role=, ?availability= and ?state=
+func (s *Server) ListNodesHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if !canQueryNodes(w, r) {
+		return
+	}
+
+	p, err := parsePage(r)
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := filters.NewArgs()
+	switch role := query.Get("role"); role {
+	case "":
+	case string(swarm.NodeRoleManager), string(swarm.NodeRoleWorker):
+		filter.Add("role", role)
+	default:
+		renderError(w, r, http.StatusBadRequest, fmt.Sprintf("The %s role is not supported, use manager or worker.", role))
+		return
+	}
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	nodes, err := svc.GetNodes(filter)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	availability := swarm.NodeAvailability(query.Get("availability"))
+	state := swarm.NodeState(query.Get("state"))
+
+	resources := NodeResources{}
+	for _, node := range nodes {
+		if availability != "" && node.Spec.Availability != availability {
+			continue
+		}
+
+		if state != "" && node.Status.State != state {
+			continue
+		}
+
+		resources = append(resources, newNodeResource(node))
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Hostname != resources[j].Hostname {
+			return resources[i].Hostname < resources[j].Hostname
+		}
+		return resources[i].ID < resources[j].ID
+	})
+
+	start, end := p.bounds(len(resources))
+	render(w, r, http.StatusOK, p.list(r, resources[start:end], len(resources)))
+}
+
+// GetNodeHandler returns a swarm node by its ID or hostname
+func (s *Server) GetNodeHandler(w http.ResponseWriter, r *http.Request) {
+	nodeID := mux.Vars(r)["node"]
+
+	if !canQueryNodes(w, r) {
+		return
+	}
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	nodes, err := svc.GetNodes(filters.NewArgs())
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, node := range nodes {
+		if node.ID == nodeID || node.Description.Hostname == nodeID {
+			render(w, r, http.StatusOK, newNodeResource(node))
+			return
+		}
+	}
+
+	renderError(w, r, http.StatusNotFound, fmt.Sprintf("The %s node was not found in the cluster.", nodeID))
+}
+
+// ListStacksHandler returns a page of the stacks deployed with "docker stack deploy", filtered by ?name= (a glob)
+// and ?verdict=
+func (s *Server) ListStacksHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	p, err := parsePage(r)
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	verdict, err := parseVerdict(query.Get("verdict"))
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	filter := filters.NewArgs()
+	filter.Add("label", service.StackNamespaceLabel)
+	swarmServices, err := svc.GetServices(filter)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	identity, authenticated := IdentityFromContext(r.Context())
+	pattern := query.Get("name")
+
+	allowed := []swarm.Service{}
+	for _, swarmService := range swarmServices {
+		stackName := swarmService.Spec.Labels[service.StackNamespaceLabel]
+		if authenticated && !identity.CanQueryStack(stackName) {
+			continue
+		}
+
+		matched, err := matchName(pattern, stackName)
+		if err != nil {
+			renderError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if matched {
+			allowed = append(allowed, swarmService)
+		}
+	}
+
+	resources, err := serviceResources(svc, allowed)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	stacks := StackResources{}
+	for _, stack := range newStackResources(resources) {
+		if verdict == "" || stack.Verdict == verdict {
+			stacks = append(stacks, stack)
+		}
+	}
+
+	start, end := p.bounds(len(stacks))
+	render(w, r, http.StatusOK, p.list(r, stacks[start:end], len(stacks)))
+}
+
+// GetStackHandler returns a stack with the names of its services and its verdict
+func (s *Server) GetStackHandler(w http.ResponseWriter, r *http.Request) {
+	stackName := mux.Vars(r)["stack"]
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	filter := filters.NewArgs()
+	filter.Add("label", fmt.Sprintf("%s=%s", service.StackNamespaceLabel, stackName))
+	swarmServices, err := svc.GetServices(filter)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if len(swarmServices) == 0 {
+		renderError(w, r, http.StatusNotFound, fmt.Sprintf("The %s stack was not found in the cluster.", stackName))
+		return
+	}
+
+	resources, err := serviceResources(svc, swarmServices)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	render(w, r, http.StatusOK, newStackResources(resources)[0])
+}
+
+// GetDeploymentHandler returns the rollout of ?image= to a service, it defaults to the image of the service spec
+func (s *Server) GetDeploymentHandler(w http.ResponseWriter, r *http.Request) {
+	serviceName := mux.Vars(r)["service"]
+
+	svc, ok := s.cluster(w, r)
+	if !ok {
+		return
+	}
+
+	swarmService, found, err := findService(svc, serviceName)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !found {
+		renderError(w, r, http.StatusNotFound, fmt.Sprintf("The %s service was not found in the cluster.", serviceName))
+		return
+	}
+
+	image := r.URL.Query().Get("image")
+	if image == "" {
+		image = service.SpecImage(&swarmService.Spec)
+	}
+
+	status, err := svc.GetDeploymentStatus(serviceName, image)
+	if err != nil {
+		logError(r, err)
+		renderError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	render(w, r, http.StatusOK, newDeploymentResource(image, status))
+}
+
+// canQueryNodes writes a 403 and returns false when the caller is not allowed to query the nodes
+func canQueryNodes(w http.ResponseWriter, r *http.Request) bool {
+	if identity, ok := IdentityFromContext(r.Context()); ok && !identity.CanQueryNodes() {
+		renderError(w, r, http.StatusForbidden, fmt.Sprintf("%s is not allowed to query the nodes.", identity.Name))
+		return false
+	}
+
+	return true
+}
+
+// findService returns the service named exactly serviceName, Docker matches the name filter as a prefix
+func findService(svc service.Services, serviceName string) (swarm.Service, bool, error) {
+	filter := filters.NewArgs()
+	filter.Add("name", serviceName)
+
+	swarmServices, err := svc.GetServices(filter)
+	if err != nil {
+		return swarm.Service{}, false, err
+	}
+
+	for _, swarmService := range swarmServices {
+		if swarmService.Spec.Name == serviceName {
+			return swarmService, true, nil
+		}
+	}
+
+	return swarm.Service{}, false, nil
+}
+
+// serviceNames maps the ID of the services matching the filter to their name
+func serviceNames(svc service.Services, filter filters.Args) (map[string]string, error) {
+	swarmServices, err := svc.GetServices(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	for _, swarmService := range swarmServices {
+		names[swarmService.ID] = swarmService.Spec.Name
+	}
+
+	return names, nil
+}
+
+// allowedServices returns the services matching the name pattern the caller may query, sorted by name
+func allowedServices(r *http.Request, swarmServices []swarm.Service, pattern string) ([]swarm.Service, error) {
+	identity, authenticated := IdentityFromContext(r.Context())
+
+	allowed := []swarm.Service{}
+	for _, swarmService := range swarmServices {
+		if authenticated && !identity.CanQueryService(swarmService.Spec.Name) {
+			continue
+		}
+
+		matched, err := matchName(pattern, swarmService.Spec.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if matched {
+			allowed = append(allowed, swarmService)
+		}
+	}
+
+	sort.Slice(allowed, func(i, j int) bool {
+		return allowed[i].Spec.Name < allowed[j].Spec.Name
+	})
+
+	return allowed, nil
+}
+
+// serviceResources fetches the status of every service concurrently, at most StatusConcurrency at a time
+func serviceResources(svc service.Services, swarmServices []swarm.Service) ([]ServiceResource, error) {
+	resources := make([]ServiceResource, len(swarmServices))
+	errs := make([]error, len(swarmServices))
+	slots := make(chan struct{}, StatusConcurrency)
+
+	var wg sync.WaitGroup
+	for i, swarmService := range swarmServices {
+		wg.Add(1)
+		go func(i int, swarmService swarm.Service) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			status, err := service.StatusOf(svc, swarmService)
+			resources[i], errs[i] = newServiceResource(swarmService, status), err
+		}(i, swarmService)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resources, nil
+}
+
+func matchName(pattern, name string) (bool, error) {
+	if pattern == "" {
+		return true, nil
+	}
+
+	matched, err := path.Match(pattern, name)
+	if err != nil {
+		return false, fmt.Errorf("The %s name pattern is malformed.", pattern)
+	}
+
+	return matched, nil
+}
+
+func parseVerdict(value string) (service.Verdict, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	for _, verdict := range service.Verdicts {
+		if string(verdict) == value {
+			return verdict, nil
+		}
+	}
+
+	return "", fmt.Errorf("The %s verdict is not supported, use succeeded, in-progress, unknown, not-found, failed or rolled-back.", value)
+}
+
+// page is the window of a collection selected with ?limit= and ?offset=
+type page struct {
+	Limit  int
+	Offset int
+}
+
+func parsePage(r *http.Request) (page, error) {
+	query := r.URL.Query()
+	p := page{Limit: DefaultPageLimit}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxPageLimit {
+			return p, fmt.Errorf("The limit parameter must be a number between 1 and %d.", MaxPageLimit)
+		}
+		p.Limit = limit
+	}
+
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return p, errors.New("The offset parameter must be a number greater than or equal to 0.")
+		}
+		p.Offset = offset
+	}
+
+	return p, nil
+}
+
+// bounds returns the indexes of the page in a collection of total items
+func (p page) bounds(total int) (int, int) {
+	start := p.Offset
+	if start > total {
+		start = total
+	}
+
+	end := start + p.Limit
+	if end > total {
+		end = total
+	}
+
+	return start, end
+}
+
+// list wraps the items of the page in the List envelope, linking the next page when there is one
+func (p page) list(r *http.Request, items interface{}, total int) List {
+	list := List{Items: items, Total: total, Limit: p.Limit, Offset: p.Offset}
+
+	if p.Offset+p.Limit < total {
+		query := r.URL.Query()
+		query.Set("limit", strconv.Itoa(p.Limit))
+		query.Set("offset", strconv.Itoa(p.Offset+p.Limit))
+		list.Next = r.URL.EscapedPath() + "?" + query.Encode()
+	}
+
+	return list
+}
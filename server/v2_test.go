@@ -0,0 +1,436 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+)
+
+func (s *ServerTestSuite) serveV2(server *Server, target string, token string) *httptest.ResponseRecorder {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", V2Path+target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	muxRouter.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (s *ServerTestSuite) v2Server(serviceMock *ServiceMock) *Server {
+	return &Server{
+		Service:       serviceMock,
+		Authenticator: NewTokenAuthenticator([]Credential{{"admin", "s3cr3t", []string{"*"}}, {"ci", "t0k3n", []string{"stack:billing"}}}),
+	}
+}
+
+func (s *ServerTestSuite) v2Task(id, serviceID string, slot int, state swarm.TaskState) swarm.Task {
+	task := swarm.Task{ID: id, ServiceID: serviceID, Slot: slot, NodeID: "x8mjy3ys2ntcq3ypbhvbs6jyf", DesiredState: swarm.TaskStateRunning}
+	task.Status.State = state
+	task.Spec.ContainerSpec = &swarm.ContainerSpec{Image: "acme/web:1.0.0@sha256:87e5c74f"}
+
+	return task
+}
+
+// onTasks mocks the tasks of the service with the given ID
+func (s *ServerTestSuite) onTasks(serviceMock *ServiceMock, serviceID string, tasks ...swarm.Task) {
+	serviceMock.On("GetTask", filters.NewArgs(filters.Arg("service", serviceID))).Return(append([]swarm.Task{}, tasks...), nil)
+}
+
+// runningTask returns a running task of the spec image of the dashboardService with the given name
+func (s *ServerTestSuite) runningTask(name string) swarm.Task {
+	return s.dashboardTask(name+".1", "acme/"+name+":1.0.0@sha256:87e5c74f", swarm.TaskStateRunning, swarm.TaskStateRunning)
+}
+
+func (s *ServerTestSuite) Test_V2_ListServices_Paginates() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{s.dashboardService("prod_web", "prod"), s.dashboardService("billing_api", "billing"), s.dashboardService("prod_db", "prod")}, nil)
+	s.onTasks(serviceMock, "billing_api-id", s.runningTask("billing_api"))
+	s.onTasks(serviceMock, "prod_db-id")
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/services?limit=2", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+
+	list := struct {
+		List
+		Items []ServiceResource
+	}{}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &list))
+	s.Equal(3, list.Total)
+	s.Equal(2, list.Limit)
+	s.Equal(0, list.Offset)
+	s.Equal(V2Path+"/services?limit=2&offset=2", list.Next)
+	s.Require().Len(list.Items, 2)
+	s.Equal("billing_api", list.Items[0].Name)
+	s.Equal("billing", list.Items[0].Stack)
+	s.Equal("acme/billing_api:1.0.0", list.Items[0].Image)
+	s.Equal(service.VerdictSucceeded, list.Items[0].Verdict)
+	s.Equal("prod_db", list.Items[1].Name)
+	serviceMock.AssertNotCalled(s.T(), "GetTask", filters.NewArgs(filters.Arg("service", "prod_web-id")))
+
+	s.onTasks(serviceMock, "prod_web-id", s.runningTask("prod_web"))
+	rec = s.serveV2(s.v2Server(serviceMock), "/services?limit=2&offset=2", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+	s.NotContains(rec.Body.String(), `"Next"`)
+}
+
+func (s *ServerTestSuite) Test_V2_ListServices_Filters() {
+	serviceMock := new(ServiceMock)
+	stackFilter := func(filter filters.Args) bool {
+		return filter.ExactMatch("label", service.StackNamespaceLabel+"=prod") && filter.ExactMatch("label", "tier=web")
+	}
+	web := s.dashboardService("prod_web", "prod")
+	web.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStatePaused}
+	serviceMock.On("GetServices", mock.MatchedBy(stackFilter)).Return([]swarm.Service{web, s.dashboardService("prod_worker", "prod"), s.dashboardService("prod_api", "prod")}, nil)
+	s.onTasks(serviceMock, "prod_web-id", s.runningTask("prod_web"))
+	s.onTasks(serviceMock, "prod_worker-id")
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/services?stack=prod&label=tier=web&name=prod_w*&verdict=failed", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+
+	list := struct {
+		List
+		Items []ServiceResource
+	}{}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &list))
+	s.Equal(1, list.Total)
+	s.Require().Len(list.Items, 1)
+	s.Equal("prod_web", list.Items[0].Name)
+	s.Equal(service.VerdictFailed, list.Items[0].Verdict)
+	serviceMock.AssertNotCalled(s.T(), "GetTask", filters.NewArgs(filters.Arg("service", "prod_api-id")))
+}
+
+func (s *ServerTestSuite) Test_V2_ListServices_OnlyListsServicesInScope() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{s.dashboardService("prod_web", "prod"), s.dashboardService("billing_api", "billing")}, nil)
+	s.onTasks(serviceMock, "billing_api-id")
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/services", "t0k3n")
+
+	s.Equal(200, rec.Code)
+	s.Contains(rec.Body.String(), `"Total":1`)
+	s.Contains(rec.Body.String(), "billing_api")
+	s.NotContains(rec.Body.String(), "prod_web")
+}
+
+func (s *ServerTestSuite) Test_V2_ListServices_InvalidParameters() {
+	serviceMock := new(ServiceMock)
+
+	for target, message := range map[string]string{
+		"/services?limit=0":        "The limit parameter must be a number between 1 and 500.",
+		"/services?limit=501":      "The limit parameter must be a number between 1 and 500.",
+		"/services?offset=-1":      "The offset parameter must be a number greater than or equal to 0.",
+		"/services?verdict=broken": "The broken verdict is not supported, use succeeded, in-progress, unknown, not-found, failed or rolled-back.",
+	} {
+		rec := s.serveV2(s.v2Server(serviceMock), target, "s3cr3t")
+
+		s.Equal(400, rec.Code, target)
+		s.Equal("application/json", rec.Header().Get("Content-Type"))
+		s.JSONEq(`{"Error": {"Status": 400, "Code": "BadRequest", "Message": "`+message+`"}}`, rec.Body.String(), target)
+	}
+}
+
+func (s *ServerTestSuite) Test_V2_ErrorEnvelope() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", mock.Anything).Return([]swarm.Service{}, nil)
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/services/prod_web", "s3cr3t")
+
+	s.Equal(404, rec.Code)
+	s.JSONEq(`{"Error": {"Status": 404, "Code": "NotFound", "Message": "The prod_web service was not found in the cluster."}}`, rec.Body.String())
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/services/prod_web", "")
+
+	s.Equal(401, rec.Code)
+	s.JSONEq(`{"Error": {"Status": 401, "Code": "Unauthorized", "Message": "Missing or unsupported credentials."}}`, rec.Body.String())
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/services/prod_web", "t0k3n")
+
+	s.Equal(403, rec.Code)
+	s.JSONEq(`{"Error": {"Status": 403, "Code": "Forbidden", "Message": "ci is not allowed to query the prod_web service."}}`, rec.Body.String())
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/services/prod_web?format=table", "s3cr3t")
+
+	s.Equal(404, rec.Code)
+	s.Equal("ERROR  NotFound  The prod_web service was not found in the cluster.\n", rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_V2_GetService() {
+	serviceMock := new(ServiceMock)
+	replicas := uint64(2)
+	web := s.dashboardService("prod_web", "prod")
+	web.Spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+	// Docker matches the name filter as a prefix
+	serviceMock.On("GetServices", mock.Anything).Return([]swarm.Service{s.dashboardService("prod_web_canary", "prod"), web}, nil)
+	s.onTasks(serviceMock, "prod_web-id", s.dashboardTask("evv1jw9o7981mrp0p50j1gy5k", "acme/prod_web:1.0.0@sha256:87e5c74f", swarm.TaskStateRunning, swarm.TaskStateRunning))
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/services/prod_web", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+
+	resource := ServiceResource{}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &resource))
+	s.Equal("prod_web-id", resource.ID)
+	s.Equal("replicated", resource.Mode)
+	s.Equal(uint64(2), *resource.Replicas)
+	s.Equal(1, resource.RunningReplicas)
+	s.Equal(service.VerdictInProgress, resource.Verdict)
+	serviceMock.AssertNotCalled(s.T(), "GetTask", filters.NewArgs(filters.Arg("service", "prod_web_canary-id")))
+}
+
+func (s *ServerTestSuite) Test_V2_ListServiceTasks() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", mock.Anything).Return([]swarm.Service{s.dashboardService("prod_web", "prod")}, nil)
+	serviceFilter := func(filter filters.Args) bool {
+		return filter.ExactMatch("service", "prod_web-id")
+	}
+	serviceMock.On("GetTask", mock.MatchedBy(serviceFilter)).Return([]swarm.Task{
+		s.v2Task("ka8a7cwzf0pq4opcscd1yf7rn", "prod_web-id", 2, swarm.TaskStateFailed),
+		s.v2Task("evv1jw9o7981mrp0p50j1gy5k", "prod_web-id", 1, swarm.TaskStateRunning),
+		s.v2Task("jq8gfkj4ghj3qfp8xw6dvb0we", "prod_web-id", 2, swarm.TaskStateRunning),
+	}, nil)
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/services/prod_web/tasks?state=running", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+
+	list := struct {
+		List
+		Items []TaskResource
+	}{}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &list))
+	s.Equal(2, list.Total)
+	s.Require().Len(list.Items, 2)
+	s.Equal("evv1jw9o7981mrp0p50j1gy5k", list.Items[0].ID)
+	s.Equal("prod_web", list.Items[0].ServiceName)
+	s.Equal("acme/web:1.0.0", list.Items[0].Image)
+	s.Equal("jq8gfkj4ghj3qfp8xw6dvb0we", list.Items[1].ID)
+}
+
+func (s *ServerTestSuite) Test_V2_ListTasks_OnlyListsTasksInScope() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{s.dashboardService("prod_web", "prod"), s.dashboardService("billing_api", "billing")}, nil)
+	serviceMock.On("GetTask", mock.Anything).Return([]swarm.Task{
+		s.v2Task("evv1jw9o7981mrp0p50j1gy5k", "prod_web-id", 1, swarm.TaskStateRunning),
+		s.v2Task("ka8a7cwzf0pq4opcscd1yf7rn", "billing_api-id", 1, swarm.TaskStateRunning),
+	}, nil)
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/tasks?node=x8mjy3ys2ntcq3ypbhvbs6jyf", "t0k3n")
+
+	s.Equal(200, rec.Code)
+	s.Contains(rec.Body.String(), `"Total":1`)
+	s.Contains(rec.Body.String(), "ka8a7cwzf0pq4opcscd1yf7rn")
+	s.NotContains(rec.Body.String(), "evv1jw9o7981mrp0p50j1gy5k")
+	serviceMock.AssertCalled(s.T(), "GetTask", mock.MatchedBy(func(filter filters.Args) bool {
+		return filter.ExactMatch("node", "x8mjy3ys2ntcq3ypbhvbs6jyf")
+	}))
+}
+
+func (s *ServerTestSuite) Test_V2_GetTask() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetTask", mock.Anything).Return([]swarm.Task{s.v2Task("evv1jw9o7981mrp0p50j1gy5k", "prod_web-id", 1, swarm.TaskStateRunning)}, nil)
+	serviceMock.On("GetServices", mock.Anything).Return([]swarm.Service{s.dashboardService("prod_web", "prod")}, nil)
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/tasks/evv1jw9o7981mrp0p50j1gy5k", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+	s.Contains(rec.Body.String(), `"ServiceName":"prod_web"`)
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/tasks/evv1jw9o7981mrp0p50j1gy5k", "t0k3n")
+
+	s.Equal(403, rec.Code)
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/tasks/evv1jw9o", "s3cr3t")
+
+	s.Equal(404, rec.Code)
+	s.JSONEq(`{"Error": {"Status": 404, "Code": "NotFound", "Message": "The evv1jw9o task was not found in the cluster."}}`, rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_V2_Nodes() {
+	manager := swarm.Node{ID: "x8mjy3ys2ntcq3ypbhvbs6jyf", ManagerStatus: &swarm.ManagerStatus{Leader: true, Reachability: swarm.ReachabilityReachable}}
+	manager.Description.Hostname = "manager-1"
+	manager.Spec.Role = swarm.NodeRoleManager
+	manager.Spec.Availability = swarm.NodeAvailabilityActive
+	manager.Status.State = swarm.NodeStateReady
+
+	worker := swarm.Node{ID: "9j3xvl0ps9kd5ax7cxwx4hnf2"}
+	worker.Description.Hostname = "worker-1"
+	worker.Spec.Role = swarm.NodeRoleWorker
+	worker.Spec.Availability = swarm.NodeAvailabilityDrain
+	worker.Status.State = swarm.NodeStateDown
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetNodes", mock.Anything).Return([]swarm.Node{worker, manager}, nil)
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/nodes?availability=active", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+
+	list := struct {
+		List
+		Items []NodeResource
+	}{}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &list))
+	s.Require().Len(list.Items, 1)
+	s.Equal("manager-1", list.Items[0].Hostname)
+	s.True(list.Items[0].Leader)
+	s.Equal("reachable", list.Items[0].Reachability)
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/nodes/worker-1", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+	s.Contains(rec.Body.String(), `"ID":"9j3xvl0ps9kd5ax7cxwx4hnf2"`)
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/nodes?role=leader", "s3cr3t")
+
+	s.Equal(400, rec.Code)
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/nodes", "t0k3n")
+
+	s.Equal(403, rec.Code)
+	s.JSONEq(`{"Error": {"Status": 403, "Code": "Forbidden", "Message": "ci is not allowed to query the nodes."}}`, rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_V2_Stacks() {
+	db := s.dashboardService("prod_db", "prod")
+	db.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStateRollbackCompleted}
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", mock.MatchedBy(func(filter filters.Args) bool {
+		return filter.ExactMatch("label", service.StackNamespaceLabel)
+	})).Return([]swarm.Service{s.dashboardService("prod_web", "prod"), s.dashboardService("billing_api", "billing"), db}, nil)
+	s.onTasks(serviceMock, "prod_web-id", s.runningTask("prod_web"))
+	s.onTasks(serviceMock, "prod_db-id")
+	s.onTasks(serviceMock, "billing_api-id", s.runningTask("billing_api"))
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/stacks", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+
+	list := struct {
+		List
+		Items []StackResource
+	}{}
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &list))
+	s.Equal([]StackResource{
+		{Name: "billing", Verdict: service.VerdictSucceeded, Services: []string{"billing_api"}},
+		{Name: "prod", Verdict: service.VerdictRolledBack, Services: []string{"prod_web", "prod_db"}},
+	}, list.Items)
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/stacks?verdict=rolled-back", "s3cr3t")
+
+	s.Contains(rec.Body.String(), `"Total":1`)
+	s.Contains(rec.Body.String(), `"Name":"prod"`)
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/stacks", "t0k3n")
+
+	s.Contains(rec.Body.String(), `"Total":1`)
+	s.Contains(rec.Body.String(), `"Name":"billing"`)
+}
+
+func (s *ServerTestSuite) Test_V2_GetStack_NotFound() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", mock.Anything).Return([]swarm.Service{}, nil)
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/stacks/prod", "s3cr3t")
+
+	s.Equal(404, rec.Code)
+	s.JSONEq(`{"Error": {"Status": 404, "Code": "NotFound", "Message": "The prod stack was not found in the cluster."}}`, rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_V2_GetDeployment() {
+	serviceMock := new(ServiceMock)
+	replicas := uint64(1)
+	serviceMock.On("GetServices", mock.Anything).Return([]swarm.Service{s.dashboardService("prod_web", "prod")}, nil)
+	serviceMock.On("GetDeploymentStatus", "prod_web", "acme/prod_web:1.0.0").Return(service.ServiceStatus{ID: "prod_web-id", Name: "prod_web", Replicas: &replicas, RunningReplicas: 1}, nil)
+	serviceMock.On("GetDeploymentStatus", "prod_web", "acme/prod_web:2.0.0").Return(service.ServiceStatus{ID: "prod_web-id", Name: "prod_web", Err: "The acme/prod_web:2.0.0 image was not deployed or not found in the current tasks running."}, nil)
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/deployments/prod_web", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+	s.JSONEq(`{"Service": "prod_web", "Image": "acme/prod_web:1.0.0", "Verdict": "succeeded", "Replicas": 1, "RunningReplicas": 1, "FailedReplicas": 0, "Tasks": []}`, rec.Body.String())
+
+	rec = s.serveV2(s.v2Server(serviceMock), "/deployments/prod_web?image=acme/prod_web:2.0.0", "s3cr3t")
+
+	s.Equal(200, rec.Code)
+	s.Contains(rec.Body.String(), `"Verdict":"failed"`)
+}
+
+func (s *ServerTestSuite) Test_V2_ReturnError() {
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", mock.Anything).Return([]swarm.Service{}, errors.New("Cannot connect to the Docker daemon."))
+
+	rec := s.serveV2(s.v2Server(serviceMock), "/services", "s3cr3t")
+
+	s.Equal(500, rec.Code)
+	s.JSONEq(`{"Error": {"Status": 500, "Code": "InternalServerError", "Message": "Cannot connect to the Docker daemon."}}`, rec.Body.String())
+}
+
+func (s *ServerTestSuite) Test_V2_ResponsesConform() {
+	document := s.openAPI()
+
+	started := time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC)
+	node := swarm.Node{ID: "x8mjy3ys2ntcq3ypbhvbs6jyf", ManagerStatus: &swarm.ManagerStatus{Leader: true, Reachability: swarm.ReachabilityReachable}}
+	node.CreatedAt, node.UpdatedAt = started, started
+	node.Description.Hostname = "manager-1"
+	node.Spec.Role = swarm.NodeRoleManager
+	node.Spec.Availability = swarm.NodeAvailabilityActive
+	node.Status.State = swarm.NodeStateReady
+
+	task := s.v2Task("evv1jw9o7981mrp0p50j1gy5k", "prod_web-id", 1, swarm.TaskStateRunning)
+	task.CreatedAt, task.Status.Timestamp = started, started
+
+	web := s.dashboardService("prod_web", "prod")
+	web.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStateCompleted, StartedAt: &started}
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", mock.Anything).Return([]swarm.Service{web}, nil)
+	serviceMock.On("GetDeploymentStatus", "prod_web", "acme/prod_web:1.0.0").Return(service.ServiceStatus{ID: "prod_web-id", Name: "prod_web", TaskStatus: []service.TaskStatus{{TaskID: task.ID, Slot: 1, State: swarm.TaskStateRunning, Timestamp: started}}}, nil)
+	serviceMock.On("GetTask", mock.Anything).Return([]swarm.Task{task}, nil)
+	serviceMock.On("GetNodes", mock.Anything).Return([]swarm.Node{node}, nil)
+
+	requests := []struct {
+		route  string
+		target string
+		token  string
+		code   int
+	}{
+		{"/services", "/services?limit=1", "s3cr3t", 200},
+		{"/services", "/services?limit=x", "s3cr3t", 400},
+		{"/services", "/services", "", 401},
+		{"/services/{service}", "/services/prod_web", "s3cr3t", 200},
+		{"/services/{service}", "/services/prod_api", "s3cr3t", 404},
+		{"/services/{service}", "/services/prod_web", "t0k3n", 403},
+		{"/services/{service}", "/services/prod_web?format=junit", "s3cr3t", 406},
+		{"/services/{service}/tasks", "/services/prod_web/tasks", "s3cr3t", 200},
+		{"/tasks", "/tasks", "s3cr3t", 200},
+		{"/tasks/{task}", "/tasks/evv1jw9o7981mrp0p50j1gy5k", "s3cr3t", 200},
+		{"/nodes", "/nodes", "s3cr3t", 200},
+		{"/nodes/{node}", "/nodes/manager-1", "s3cr3t", 200},
+		{"/stacks", "/stacks", "s3cr3t", 200},
+		{"/stacks/{stack}", "/stacks/prod", "s3cr3t", 200},
+		{"/deployments/{service}", "/deployments/prod_web", "s3cr3t", 200},
+	}
+
+	for _, request := range requests {
+		rec := s.serveV2(s.v2Server(serviceMock), request.target, request.token)
+
+		s.Equal(request.code, rec.Code, request.target)
+		s.assertConforms(document, "GET", V2Path+request.route, rec)
+	}
+}
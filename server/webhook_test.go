@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/albertogviana/docker-swarm-service-status/webhook"
+	"github.com/gorilla/mux"
+)
+
+func (s *ServerTestSuite) Test_WebhookDeliveries_ReturnDeliveryLog() {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	dispatcher := webhook.NewDispatcher([]webhook.Webhook{{URL: receiver.URL}})
+	dispatcher.Notify(webhook.NewEvent("default", service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web", Err: "The image was not deployed."}, nil, service.VerdictInProgress))
+	dispatcher.Notify(webhook.NewEvent("default", service.ServiceStatus{ID: "evv1jw9o7981mrp0p50j1gy5k", Name: "billing_api", Err: "The image was not deployed."}, nil, service.VerdictInProgress))
+
+	server := &Server{
+		Service:       new(ServiceMock),
+		Authenticator: NewTokenAuthenticator([]Credential{{"admin", "s3cr3t", []string{"*"}}, {"ci", "t0k3n", []string{"stack:prod"}}}),
+		Webhooks:      dispatcher,
+	}
+
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, server)
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/webhooks/deliveries", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+
+	muxRouter.ServeHTTP(rec, req)
+
+	deliveries := webhook.Deliveries{}
+	json.Unmarshal(rec.Body.Bytes(), &deliveries)
+
+	s.Equal(200, rec.Code)
+	s.Len(deliveries, 2)
+	s.Equal("billing_api", deliveries[0].Service)
+	s.True(deliveries[0].Delivered)
+	s.Equal(receiver.URL, deliveries[0].URL)
+
+	rec = httptest.NewRecorder()
+	req.Header.Set("Authorization", "Bearer t0k3n")
+
+	muxRouter.ServeHTTP(rec, req)
+
+	deliveries = webhook.Deliveries{}
+	json.Unmarshal(rec.Body.Bytes(), &deliveries)
+
+	s.Len(deliveries, 1)
+	s.Equal("prod_web", deliveries[0].Service)
+}
+
+func (s *ServerTestSuite) Test_WebhookDeliveries_ReturnEmptyLogWithoutWebhooks() {
+	muxRouter := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+	router(muxRouter, &Server{Service: new(ServiceMock)})
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/docker-swarm-service-status/webhooks/deliveries", nil)
+
+	muxRouter.ServeHTTP(rec, req)
+
+	s.Equal(200, rec.Code)
+	s.Equal("[]", rec.Body.String())
+}
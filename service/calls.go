@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// CallCounter counts the Docker API calls made on behalf of a request
+type CallCounter struct {
+	calls int64
+}
+
+// Calls returns how many Docker API calls were counted
+func (c *CallCounter) Calls() int64 {
+	return atomic.LoadInt64(&c.calls)
+}
+
+// add counts one call, a nil counter counts nothing
+func (c *CallCounter) add() {
+	if c == nil {
+		return
+	}
+
+	atomic.AddInt64(&c.calls, 1)
+}
+
+type callCounterKey struct{}
+
+// ContextWithCallCounter returns a context counting the Docker API calls made with it in counter
+func ContextWithCallCounter(ctx context.Context, counter *CallCounter) context.Context {
+	return context.WithValue(ctx, callCounterKey{}, counter)
+}
+
+func callCounterFromContext(ctx context.Context) *CallCounter {
+	counter, _ := ctx.Value(callCounterKey{}).(*CallCounter)
+	return counter
+}
+
+// WithContext returns a view of the service making its Docker API calls with ctx, so they are cancelled with it,
+// traced as its children and counted by its CallCounter. The view shares the client, the limiter and the
+// negotiated API version with s.
+func (s *Service) WithContext(ctx context.Context) Services {
+	return &Service{
+		Host:         s.Host,
+		DockerClient: s.DockerClient,
+		Limiter:      s.Limiter,
+		ctx:          ctx,
+		origin:       s.negotiated(),
+	}
+}
+
+// context returns the context of the Docker API calls
+func (s *Service) context() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+
+	return context.Background()
+}
+
+// negotiated returns the service keeping the info recorded by Negotiate
+func (s *Service) negotiated() *Service {
+	if s.origin != nil {
+		return s.origin
+	}
+
+	return s
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/stretchr/testify/suite"
+)
+
+type CallCounterTestSuite struct {
+	suite.Suite
+}
+
+func TestCallCounterTestSuite(t *testing.T) {
+	suite.Run(t, new(CallCounterTestSuite))
+}
+
+func (s *CallCounterTestSuite) Test_WithContext_CountsDockerCalls() {
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer daemon.Close()
+
+	svc, err := NewServiceWithConfig(Config{Host: "tcp://" + strings.TrimPrefix(daemon.URL, "http://"), APIVersion: "v1.33"})
+	s.Require().NoError(err)
+
+	counter := &CallCounter{}
+	view := svc.WithContext(ContextWithCallCounter(context.Background(), counter))
+
+	_, err = view.GetServices(filters.NewArgs())
+	s.NoError(err)
+	_, err = view.GetNodes(filters.NewArgs())
+	s.NoError(err)
+	s.Equal(int64(2), counter.Calls())
+
+	_, err = svc.GetServices(filters.NewArgs())
+	s.NoError(err)
+	s.Equal(int64(2), counter.Calls(), "the service itself does not count")
+}
+
+func (s *CallCounterTestSuite) Test_WithContext_SharesNegotiatedInfo() {
+	svc := &Service{info: &Info{APIVersion: "1.41"}}
+
+	info, err := svc.WithContext(context.Background()).GetInfo()
+
+	s.NoError(err)
+	s.Equal("1.41", info.APIVersion)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/cli/cli/connhelper"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/tlsconfig"
+)
+
+// DefaultDockerHost is used when DOCKER_HOST is not set
+const DefaultDockerHost = "unix:///var/run/docker.sock"
+
+// DefaultCluster is the name of the cluster configured from the DOCKER_* environment variables
+const DefaultCluster = "default"
+
+// Config defines how to reach the Docker daemon
+type Config struct {
+	// Host accepts unix://, tcp:// and ssh:// addresses
+	Host string
+	// APIVersion pins the API version, when empty it is negotiated with the daemon
+	APIVersion string
+	// TLSVerify verifies the daemon certificate against the CA in CertPath
+	TLSVerify bool
+	// CertPath is a directory holding ca.pem, cert.pem and key.pem, setting it enables TLS
+	CertPath       string
+	DefaultHeaders map[string]string
+}
+
+// ConfigFromEnv returns a Config built from the DOCKER_HOST, DOCKER_API_VERSION, DOCKER_TLS_VERIFY
+// and DOCKER_CERT_PATH environment variables, the same ones honoured by the docker CLI
+func ConfigFromEnv() Config {
+	config := Config{
+		Host:      DefaultDockerHost,
+		TLSVerify: os.Getenv("DOCKER_TLS_VERIFY") != "",
+		CertPath:  os.Getenv("DOCKER_CERT_PATH"),
+	}
+
+	if os.Getenv("DOCKER_HOST") != "" {
+		config.Host = os.Getenv("DOCKER_HOST")
+	}
+
+	if os.Getenv("DOCKER_API_VERSION") != "" {
+		config.APIVersion = os.Getenv("DOCKER_API_VERSION")
+	}
+
+	if config.TLSVerify && config.CertPath == "" {
+		home, _ := os.UserHomeDir()
+		config.CertPath = filepath.Join(home, ".docker")
+	}
+
+	return config
+}
+
+// LoadClusterConfigs reads a JSON file mapping cluster names to their Config, for instance
+// {"staging": {"Host": "tcp://staging-manager:2376", "TLSVerify": true, "CertPath": "/run/secrets/staging"}}
+func LoadClusterConfigs(filename string) (map[string]Config, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	configs := map[string]Config{}
+	if err := json.NewDecoder(file).Decode(&configs); err != nil {
+		return nil, fmt.Errorf("Unable to parse the clusters file %s: %s", filename, err.Error())
+	}
+
+	for name, config := range configs {
+		if config.Host == "" {
+			return nil, fmt.Errorf("The %s cluster has no Host.", name)
+		}
+	}
+
+	return configs, nil
+}
+
+func (c Config) tlsEnabled() bool {
+	return c.TLSVerify || c.CertPath != ""
+}
+
+func (c Config) clientOpts() ([]client.Opt, error) {
+	opts := []client.Opt{client.WithHTTPHeaders(c.DefaultHeaders)}
+
+	if c.APIVersion != "" {
+		opts = append(opts, client.WithVersion(strings.TrimPrefix(c.APIVersion, "v")))
+	} else {
+		opts = append(opts, client.WithAPIVersionNegotiation())
+	}
+
+	helper, err := connhelper.GetConnectionHelper(c.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	// ssh:// hosts are reached through "docker system dial-stdio" on the remote machine
+	if helper != nil {
+		httpClient := &http.Client{Transport: &http.Transport{DialContext: helper.Dialer}}
+		return append(opts, client.WithHTTPClient(httpClient), client.WithHost(helper.Host), client.WithDialContext(helper.Dialer)), nil
+	}
+
+	if c.tlsEnabled() {
+		tlsConfig, err := tlsconfig.Client(tlsconfig.Options{
+			CAFile:             filepath.Join(c.CertPath, "ca.pem"),
+			CertFile:           filepath.Join(c.CertPath, "cert.pem"),
+			KeyFile:            filepath.Join(c.CertPath, "key.pem"),
+			InsecureSkipVerify: !c.TLSVerify,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Unable to load the TLS configuration from %s: %s", c.CertPath, err.Error())
+		}
+
+		opts = append(opts, client.WithHTTPClient(&http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}))
+	}
+
+	return append(opts, client.WithHost(c.Host)), nil
+}
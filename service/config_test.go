@@ -0,0 +1,121 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var configVariables = []string{"DOCKER_HOST", "DOCKER_API_VERSION", "DOCKER_TLS_VERIFY", "DOCKER_CERT_PATH"}
+
+type ConfigTestSuite struct {
+	suite.Suite
+	env map[string]string
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
+
+func (s *ConfigTestSuite) SetupTest() {
+	s.env = map[string]string{}
+	for _, name := range configVariables {
+		if value, ok := os.LookupEnv(name); ok {
+			s.env[name] = value
+		}
+		os.Unsetenv(name)
+	}
+}
+
+func (s *ConfigTestSuite) TearDownTest() {
+	for _, name := range configVariables {
+		if value, ok := s.env[name]; ok {
+			os.Setenv(name, value)
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func (s *ConfigTestSuite) Test_ConfigFromEnv_ReturnDefaults() {
+	config := ConfigFromEnv()
+
+	s.Equal(DefaultDockerHost, config.Host)
+	s.Empty(config.APIVersion)
+	s.False(config.TLSVerify)
+	s.Empty(config.CertPath)
+}
+
+func (s *ConfigTestSuite) Test_ConfigFromEnv_ReturnEnvironment() {
+	os.Setenv("DOCKER_HOST", "tcp://manager:2376")
+	os.Setenv("DOCKER_API_VERSION", "1.40")
+	os.Setenv("DOCKER_TLS_VERIFY", "1")
+	os.Setenv("DOCKER_CERT_PATH", "/certs")
+
+	config := ConfigFromEnv()
+
+	s.Equal("tcp://manager:2376", config.Host)
+	s.Equal("1.40", config.APIVersion)
+	s.True(config.TLSVerify)
+	s.Equal("/certs", config.CertPath)
+}
+
+func (s *ConfigTestSuite) Test_NewServiceWithConfig_ReturnService_TCPHost() {
+	service, err := NewServiceWithConfig(Config{Host: "tcp://manager:2375", APIVersion: "v1.33"})
+
+	s.NoError(err)
+	s.Equal("tcp://manager:2375", service.Host)
+	s.Equal("1.33", service.DockerClient.ClientVersion())
+}
+
+func (s *ConfigTestSuite) Test_NewServiceWithConfig_ReturnService_NegotiatedVersion() {
+	service, err := NewServiceWithConfig(Config{Host: "tcp://manager:2375"})
+
+	s.NoError(err)
+	s.Nil(service.info)
+}
+
+func (s *ConfigTestSuite) Test_NewServiceWithConfig_ReturnService_SSHHost() {
+	service, err := NewServiceWithConfig(Config{Host: "ssh://deploy@manager", APIVersion: "v1.33"})
+
+	s.NoError(err)
+	s.Equal("ssh://deploy@manager", service.Host)
+}
+
+func (s *ConfigTestSuite) Test_NewServiceWithConfig_ReturnError_MissingCertificates() {
+	_, err := NewServiceWithConfig(Config{Host: "tcp://manager:2376", TLSVerify: true, CertPath: "/does/not/exist"})
+
+	s.Error(err)
+}
+
+func (s *ConfigTestSuite) Test_NewServiceWithConfig_ReturnError_InvalidHost() {
+	_, err := NewServiceWithConfig(Config{Host: "manager"})
+
+	s.Error(err)
+}
+
+func (s *ConfigTestSuite) Test_LoadClusterConfigs_ReturnConfigs() {
+	file, _ := ioutil.TempFile("", "clusters")
+	defer os.Remove(file.Name())
+	file.WriteString(`{"staging": {"Host": "tcp://staging:2376", "TLSVerify": true, "CertPath": "/certs/staging"}, "production": {"Host": "ssh://deploy@production", "APIVersion": "1.41"}}`)
+	file.Close()
+
+	configs, err := LoadClusterConfigs(file.Name())
+
+	s.NoError(err)
+	s.Equal(Config{Host: "tcp://staging:2376", TLSVerify: true, CertPath: "/certs/staging"}, configs["staging"])
+	s.Equal(Config{Host: "ssh://deploy@production", APIVersion: "1.41"}, configs["production"])
+}
+
+func (s *ConfigTestSuite) Test_LoadClusterConfigs_ReturnError_MissingHost() {
+	file, _ := ioutil.TempFile("", "clusters")
+	defer os.Remove(file.Name())
+	file.WriteString(`{"staging": {"APIVersion": "1.41"}}`)
+	file.Close()
+
+	_, err := LoadClusterConfigs(file.Name())
+
+	s.EqualError(err, "The staging cluster has no Host.")
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker/docker/api/types/versions"
+)
+
+// NegotiationTimeout is the maximum time spent negotiating the API version with the Docker daemon
+const NegotiationTimeout = 10 * time.Second
+
+// Info structure
+type Info struct {
+	// APIVersion is the version used by this service to talk to the daemon
+	APIVersion          string
+	ServerAPIVersion    string `json:",omitempty"`
+	ServerMinAPIVersion string `json:",omitempty"`
+	ServerVersion       string `json:",omitempty"`
+	Features            []Feature
+}
+
+// Feature structure
+type Feature struct {
+	Name          string
+	Description   string
+	MinAPIVersion string
+	Supported     bool
+}
+
+// features lists the daemon capabilities that change what ServiceStatus reports
+var features = []Feature{
+	{Name: "update-status", Description: "UpdateStatus is reported for services that were updated.", MinAPIVersion: "1.25"},
+	{Name: "rollback", Description: "UpdateStatus reports rollback_started, rollback_paused and rollback_completed.", MinAPIVersion: "1.28"},
+	{Name: "job-modes", Description: "Replicated and global job services are listed, Replicas is only reported for replicated services.", MinAPIVersion: "1.41"},
+}
+
+// Negotiate agrees on the API version with the daemon, unless a version was pinned in the Config,
+// and records which features the daemon supports
+func (s *Service) Negotiate() (Info, error) {
+	ctx, cancel := context.WithTimeout(s.context(), NegotiationTimeout)
+	defer cancel()
+
+	ctx, end := s.startCall(ctx, "ServerVersion")
+	if err := s.Limiter.acquire(ctx); err != nil {
+		end(err)
+		return Info{}, err
+	}
+	defer s.Limiter.release()
+
+	s.DockerClient.NegotiateAPIVersion(ctx)
+
+	version, err := s.DockerClient.ServerVersion(ctx)
+	end(err)
+	if err != nil {
+		return Info{}, err
+	}
+
+	info := Info{
+		APIVersion:          s.DockerClient.ClientVersion(),
+		ServerAPIVersion:    version.APIVersion,
+		ServerMinAPIVersion: version.MinAPIVersion,
+		ServerVersion:       version.Version,
+	}
+
+	for _, feature := range features {
+		feature.Supported = versions.GreaterThanOrEqualTo(info.APIVersion, feature.MinAPIVersion)
+		info.Features = append(info.Features, feature)
+	}
+
+	origin := s.negotiated()
+	origin.mutex.Lock()
+	origin.info = &info
+	origin.mutex.Unlock()
+
+	return info, nil
+}
+
+// GetInfo returns the API version and features recorded by Negotiate, negotiating first when needed
+func (s *Service) GetInfo() (Info, error) {
+	origin := s.negotiated()
+	origin.mutex.Lock()
+	info := origin.info
+	origin.mutex.Unlock()
+
+	if info != nil {
+		return *info, nil
+	}
+
+	return s.Negotiate()
+}
@@ -0,0 +1,45 @@
+package service
+
+import "context"
+
+// DefaultMaxConcurrentCalls is how many Docker API calls the server runs at the same time by default
+const DefaultMaxConcurrentCalls = 16
+
+// Limiter caps how many Docker API calls run at the same time. Sharing one Limiter between the Service of every
+// cluster caps the calls of the whole process.
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter returns a limiter allowing max concurrent calls
+func NewLimiter(max int) *Limiter {
+	return &Limiter{slots: make(chan struct{}, max)}
+}
+
+// Max returns how many calls may run at the same time
+func (l *Limiter) Max() int {
+	return cap(l.slots)
+}
+
+// acquire waits for a free slot until ctx is done, a nil limiter never waits
+func (l *Limiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the slot taken by acquire
+func (l *Limiter) release() {
+	if l == nil {
+		return
+	}
+
+	<-l.slots
+}
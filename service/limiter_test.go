@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LimiterTestSuite struct {
+	suite.Suite
+}
+
+func TestLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(LimiterTestSuite))
+}
+
+func (s *LimiterTestSuite) Test_Limiter_WaitsForAFreeSlot() {
+	limiter := NewLimiter(1)
+	s.Equal(1, limiter.Max())
+
+	s.NoError(limiter.acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	s.Equal(context.DeadlineExceeded, limiter.acquire(ctx))
+
+	limiter.release()
+	s.NoError(limiter.acquire(context.Background()))
+}
+
+func (s *LimiterTestSuite) Test_Limiter_NilNeverWaits() {
+	var limiter *Limiter
+
+	s.NoError(limiter.acquire(context.Background()))
+	limiter.release()
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -16,6 +18,14 @@ import (
 type Service struct {
 	Host         string
 	DockerClient *client.Client
+	// Limiter caps the concurrent Docker API calls, there is no cap when nil. Readiness checks are not limited.
+	Limiter *Limiter
+	mutex   sync.Mutex
+	info    *Info
+	// ctx is the context of the Docker API calls of a view, see WithContext
+	ctx context.Context
+	// origin is the service this view was made from, it keeps the negotiated info
+	origin *Service
 }
 
 // ServiceStatus structure
@@ -28,11 +38,17 @@ type ServiceStatus struct {
 	RunningReplicas int                 `json:",omitempty"`
 	FailedReplicas  int                 `json:",omitempty"`
 	UpdateStatus    *swarm.UpdateStatus `json:",omitempty"`
+	// Version is the version index of the service, Docker bumps it on every update of the service
+	Version uint64 `json:",omitempty"`
+	// Image is the image of the service spec without its digest
+	Image string `json:",omitempty"`
 }
 
 // TaskStatus structure
 type TaskStatus struct {
 	TaskID       string          `json:",omitempty"`
+	Slot         int             `json:",omitempty"`
+	NodeID       string          `json:",omitempty"`
 	Timestamp    time.Time       `json:",omitempty"`
 	DesiredState swarm.TaskState `json:",omitempty"`
 	State        swarm.TaskState `json:",omitempty"`
@@ -41,31 +57,119 @@ type TaskStatus struct {
 	Image        string          `json:",omitempty"`
 }
 
+// StackNamespaceLabel is set by "docker stack deploy" on every service of a stack
+const StackNamespaceLabel = "com.docker.stack.namespace"
+
+// StackStatus structure
+type StackStatus struct {
+	Name     string
+	Err      string          `json:",omitempty"`
+	Services []ServiceStatus `json:",omitempty"`
+}
+
+// ClusterStatus structure
+type ClusterStatus struct {
+	Cluster string
+	Status  *ServiceStatus `json:",omitempty"`
+	Err     string         `json:",omitempty"`
+}
+
+// DeploymentCheck is a service together with the image expected to be deployed
+type DeploymentCheck struct {
+	Service string
+	Image   string
+}
+
+// BatchRequest lists the deployments to check at once
+type BatchRequest struct {
+	Deployments []DeploymentCheck
+}
+
+// DeploymentResult structure
+type DeploymentResult struct {
+	Service string
+	Image   string
+	Verdict Verdict
+	Status  *ServiceStatus `json:",omitempty"`
+	// Err is why the status of the deployment could not be read, its verdict is then unknown
+	Err string `json:",omitempty"`
+}
+
+// BatchStatus structure
+type BatchStatus struct {
+	Verdict     Verdict
+	Deployments []DeploymentResult
+}
+
+// Readiness structure
+type Readiness struct {
+	Ready      bool
+	APIVersion string `json:",omitempty"`
+	Latency    string `json:",omitempty"`
+	Checks     []Check
+}
+
+// Check structure
+type Check struct {
+	Name    string
+	OK      bool
+	Message string `json:",omitempty"`
+}
+
 // Services defines interfaces with the required methods
 type Services interface {
 	GetService(filter filters.Args) (swarm.Service, error)
+	GetServices(filter filters.Args) ([]swarm.Service, error)
 	GetTask(filter filters.Args) ([]swarm.Task, error)
+	GetNodes(filter filters.Args) ([]swarm.Node, error)
 	GetDeploymentStatus(serviceName string, image string) (ServiceStatus, error)
 	GetServiceStatus(serviceName string) (ServiceStatus, error)
+	GetStackStatus(stackName string) (StackStatus, error)
+	GetReadiness() Readiness
+	GetInfo() (Info, error)
 }
 
-// NewService returns a new instance of the Service structure
-func NewService(host, dockerAPIVersion string, defaultHeaders map[string]string) *Service {
-	client, err := client.NewClient(host, dockerAPIVersion, nil, defaultHeaders)
+// ReadinessTimeout is the maximum time spent talking to the Docker daemon during a readiness check
+const ReadinessTimeout = 5 * time.Second
+
+// NewService returns a new instance of the Service structure. The TLS settings are read from the
+// environment, see ConfigFromEnv, and an empty host or API version falls back to the environment as well.
+func NewService(host, dockerAPIVersion string, defaultHeaders map[string]string) (*Service, error) {
+	config := ConfigFromEnv()
+	if host != "" {
+		config.Host = host
+	}
+	if dockerAPIVersion != "" {
+		config.APIVersion = dockerAPIVersion
+	}
+	config.DefaultHeaders = defaultHeaders
+
+	return NewServiceWithConfig(config)
+}
+
+// NewServiceWithConfig returns a new instance of the Service structure connected as described by config
+func NewServiceWithConfig(config Config) (*Service, error) {
+	opts, err := config.clientOpts()
 	if err != nil {
-		fmt.Printf("Something went wrong when tries to connect on the docker host: %s", err.Error())
+		return nil, err
 	}
 
-	return &Service{
-		host,
-		client,
+	client, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("Something went wrong when tries to connect on the docker host: %s", err.Error())
 	}
+
+	return &Service{
+		Host:         config.Host,
+		DockerClient: client,
+	}, nil
 }
 
 // GetService returns swarm.Service struct
 // You will find the available filters on https://docs.docker.com/engine/api/v1.32/#operation/ServiceList
+// Docker matches the name filter as a prefix, only a service named exactly as one of the name filters is returned.
 func (s *Service) GetService(filter filters.Args) (swarm.Service, error) {
-	serviceList, err := s.DockerClient.ServiceList(context.Background(), types.ServiceListOptions{Filters: filter})
+	serviceList, err := s.serviceList(filter)
 
 	swarmService := swarm.Service{}
 	if err != nil {
@@ -73,16 +177,52 @@ func (s *Service) GetService(filter filters.Args) (swarm.Service, error) {
 	}
 
 	for _, service := range serviceList {
+		if !filter.ExactMatch("name", service.Spec.Name) {
+			continue
+		}
 		swarmService = service
 	}
 
 	return swarmService, nil
 }
 
+// GetServices returns every swarm.Service matching the filter
+// You will find the available filters on https://docs.docker.com/engine/api/v1.32/#operation/ServiceList
+func (s *Service) GetServices(filter filters.Args) ([]swarm.Service, error) {
+	serviceList, err := s.serviceList(filter)
+	if err != nil {
+		return []swarm.Service{}, err
+	}
+
+	return serviceList, nil
+}
+
+func (s *Service) serviceList(filter filters.Args) ([]swarm.Service, error) {
+	ctx, end := s.startCall(s.context(), "ServiceList")
+	if err := s.Limiter.acquire(ctx); err != nil {
+		end(err)
+		return nil, err
+	}
+	defer s.Limiter.release()
+
+	serviceList, err := s.DockerClient.ServiceList(ctx, types.ServiceListOptions{Filters: filter})
+	end(err)
+
+	return serviceList, err
+}
+
 // GetTask returns the tasks related to a specific service id
 // You will find the available filters on https://docs.docker.com/engine/api/v1.32/#operation/TaskList
 func (s *Service) GetTask(filter filters.Args) ([]swarm.Task, error) {
-	tasks, err := s.DockerClient.TaskList(context.Background(), types.TaskListOptions{Filters: filter})
+	ctx, end := s.startCall(s.context(), "TaskList")
+	if err := s.Limiter.acquire(ctx); err != nil {
+		end(err)
+		return []swarm.Task{}, err
+	}
+	defer s.Limiter.release()
+
+	tasks, err := s.DockerClient.TaskList(ctx, types.TaskListOptions{Filters: filter})
+	end(err)
 
 	if err != nil {
 		return []swarm.Task{}, err
@@ -91,6 +231,25 @@ func (s *Service) GetTask(filter filters.Args) ([]swarm.Task, error) {
 	return tasks, nil
 }
 
+// GetNodes returns every swarm.Node matching the filter
+// You will find the available filters on https://docs.docker.com/engine/api/v1.32/#operation/NodeList
+func (s *Service) GetNodes(filter filters.Args) ([]swarm.Node, error) {
+	ctx, end := s.startCall(s.context(), "NodeList")
+	if err := s.Limiter.acquire(ctx); err != nil {
+		end(err)
+		return []swarm.Node{}, err
+	}
+	defer s.Limiter.release()
+
+	nodes, err := s.DockerClient.NodeList(ctx, types.NodeListOptions{Filters: filter})
+	end(err)
+	if err != nil {
+		return []swarm.Node{}, err
+	}
+
+	return nodes, nil
+}
+
 // GetDeploymentStatus returns the information about a service and it verifies if the tasks are running
 // or for some reason it failed
 func (s *Service) GetDeploymentStatus(serviceName string, image string) (ServiceStatus, error) {
@@ -119,19 +278,21 @@ func (s *Service) GetDeploymentStatus(serviceName string, image string) (Service
 	}
 
 	deploymentStatus.ID = swarmService.ID
+	deploymentStatus.Version = swarmService.Version.Index
+	deploymentStatus.Image = SpecImage(&swarmService.Spec)
+	deploymentStatus.Replicas = replicas(swarmService)
+	deploymentStatus.TaskStatus = parseTaskState(swarmTask)
+	deploymentStatus.UpdateStatus = swarmService.UpdateStatus
 
-	if s.isImageDeploy(swarmTask, image) == false {
+	// the spec is updated before the scheduler creates the tasks running the new image
+	if isImageDeploy(swarmTask, image) == false && deploymentStatus.Image != image {
 		deploymentStatus.Err = fmt.Sprintf("The %s image was not deployed or not found in the current tasks running.", image)
 		return deploymentStatus, nil
 	}
 
-	deploymentStatus.Replicas = swarmService.Spec.Mode.Replicated.Replicas
-	deploymentStatus.TaskStatus = s.parseTaskState(swarmTask)
-	deploymentStatus.UpdateStatus = swarmService.UpdateStatus
-
-	deploymentStatus.RunningReplicas, deploymentStatus.FailedReplicas = s.taskStateCount(deploymentStatus, image)
+	deploymentStatus.RunningReplicas, deploymentStatus.FailedReplicas = taskStateCount(deploymentStatus, image)
 
-	if deploymentStatus.FailedReplicas > deploymentStatus.RunningReplicas && uint64(deploymentStatus.RunningReplicas) < *deploymentStatus.Replicas {
+	if deploymentStatus.FailedReplicas > deploymentStatus.RunningReplicas && (deploymentStatus.Replicas == nil || uint64(deploymentStatus.RunningReplicas) < *deploymentStatus.Replicas) {
 		deploymentStatus.Err = fmt.Sprintf("Looks like something went wrong during the deployment, because the %s service failed %d time(s) since last deployment", serviceName, deploymentStatus.FailedReplicas)
 	}
 
@@ -161,37 +322,151 @@ func (s *Service) GetServiceStatus(serviceName string) (ServiceStatus, error) {
 		return serviceStatus, nil
 	}
 
+	return StatusOf(s, swarmService)
+}
+
+// GetStackStatus returns the information about every service deployed by "docker stack deploy" with the given
+// stack name, it relies on the com.docker.stack.namespace label set by Docker
+func (s *Service) GetStackStatus(stackName string) (StackStatus, error) {
+	filterService := filters.NewArgs()
+	filterService.Add("label", fmt.Sprintf("%s=%s", StackNamespaceLabel, stackName))
+	swarmServices, err := s.GetServices(filterService)
+
+	stackStatus := StackStatus{Name: stackName}
+	if err != nil {
+		return stackStatus, err
+	}
+
+	if len(swarmServices) == 0 {
+		stackStatus.Err = fmt.Sprintf("The %s stack was not found in the cluster.", stackName)
+		return stackStatus, nil
+	}
+
+	sort.Slice(swarmServices, func(i, j int) bool {
+		return swarmServices[i].Spec.Name < swarmServices[j].Spec.Name
+	})
+
+	for _, swarmService := range swarmServices {
+		serviceStatus, err := StatusOf(s, swarmService)
+		if err != nil {
+			return stackStatus, err
+		}
+
+		stackStatus.Services = append(stackStatus.Services, serviceStatus)
+	}
+
+	return stackStatus, nil
+}
+
+// StatusOf returns the status of a service listed with svc. Only its tasks are fetched: those Docker wants to run,
+// the completed tasks of jobs and the tasks of the current update that failed.
+func StatusOf(svc Services, swarmService swarm.Service) (ServiceStatus, error) {
+	serviceStatus := ServiceStatus{Name: swarmService.Spec.Name}
+
 	filterTask := filters.NewArgs()
 	filterTask.Add("service", swarmService.ID)
-	filterTask.Add("desired-state", "running")
 
-	swarmTask, err := s.GetTask(filterTask)
+	swarmTask, err := svc.GetTask(filterTask)
 	if err != nil {
 		return serviceStatus, err
 	}
 
 	serviceStatus.ID = swarmService.ID
+	serviceStatus.Version = swarmService.Version.Index
+	serviceStatus.Image = SpecImage(&swarmService.Spec)
 
-	serviceStatus.Replicas = swarmService.Spec.Mode.Replicated.Replicas
-	serviceStatus.TaskStatus = s.parseTaskState(swarmTask)
+	serviceStatus.Replicas = replicas(swarmService)
+	serviceStatus.TaskStatus = parseTaskState(currentTasks(swarmService, swarmTask))
 	serviceStatus.UpdateStatus = swarmService.UpdateStatus
 
-	serviceStatus.RunningReplicas, serviceStatus.FailedReplicas = s.taskStateCount(serviceStatus, "")
+	serviceStatus.RunningReplicas, serviceStatus.FailedReplicas = taskStateCount(serviceStatus, "")
 
 	return serviceStatus, nil
 }
 
-func (s *Service) parseTaskState(swarmTask []swarm.Task) []TaskStatus {
+// currentTasks drops the tasks replaced by an update and the failed tasks of the previous updates, which Docker
+// keeps in the task history
+func currentTasks(swarmService swarm.Service, swarmTask []swarm.Task) []swarm.Task {
+	image := SpecImage(&swarmService.Spec)
+
+	since := time.Time{}
+	if swarmService.UpdateStatus != nil && swarmService.UpdateStatus.StartedAt != nil {
+		since = *swarmService.UpdateStatus.StartedAt
+	}
+
+	tasks := []swarm.Task{}
+	for _, task := range swarmTask {
+		switch {
+		case task.DesiredState == swarm.TaskStateRunning, task.DesiredState == swarm.TaskStateComplete:
+			tasks = append(tasks, task)
+		case task.Status.State == swarm.TaskStateFailed || task.Status.State == swarm.TaskStateRejected:
+			if getImage(taskImage(task)) == image && !task.CreatedAt.Before(since) {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	return tasks
+}
+
+// GetReadiness verifies that the Docker daemon is reachable and that the node is an active swarm manager,
+// which is required to list services and tasks
+func (s *Service) GetReadiness() Readiness {
+	ctx, cancel := context.WithTimeout(s.context(), ReadinessTimeout)
+	defer cancel()
+
+	readiness := Readiness{}
+
+	start := time.Now()
+	pingCtx, end := s.startCall(ctx, "Ping")
+	ping, err := s.DockerClient.Ping(pingCtx)
+	end(err)
+	readiness.Latency = time.Since(start).String()
+	if err != nil {
+		readiness.Checks = append(readiness.Checks, Check{"docker", false, err.Error()})
+		return readiness
+	}
+
+	readiness.APIVersion = ping.APIVersion
+	readiness.Checks = append(readiness.Checks, Check{"docker", true, ""})
+
+	infoCtx, end := s.startCall(ctx, "Info")
+	info, err := s.DockerClient.Info(infoCtx)
+	end(err)
+	if err != nil {
+		readiness.Checks = append(readiness.Checks, Check{"swarm", false, err.Error()})
+		return readiness
+	}
+
+	if info.Swarm.LocalNodeState != swarm.LocalNodeStateActive {
+		readiness.Checks = append(readiness.Checks, Check{"swarm", false, fmt.Sprintf("The node is not part of an active swarm, its state is %q.", info.Swarm.LocalNodeState)})
+		return readiness
+	}
+	readiness.Checks = append(readiness.Checks, Check{"swarm", true, ""})
+
+	if !info.Swarm.ControlAvailable {
+		readiness.Checks = append(readiness.Checks, Check{"manager", false, "The node is not a swarm manager."})
+		return readiness
+	}
+	readiness.Checks = append(readiness.Checks, Check{"manager", true, ""})
+
+	readiness.Ready = true
+	return readiness
+}
+
+func parseTaskState(swarmTask []swarm.Task) []TaskStatus {
 	taskStatus := []TaskStatus{}
 	for _, task := range swarmTask {
 		ts := TaskStatus{
-			task.ID,
-			task.Status.Timestamp,
-			task.DesiredState,
-			task.Status.State,
-			task.Status.Message,
-			task.Status.Err,
-			task.Spec.ContainerSpec.Image,
+			TaskID:       task.ID,
+			Slot:         task.Slot,
+			NodeID:       task.NodeID,
+			Timestamp:    task.Status.Timestamp,
+			DesiredState: task.DesiredState,
+			State:        task.Status.State,
+			Message:      task.Status.Message,
+			Err:          task.Status.Err,
+			Image:        taskImage(task),
 		}
 
 		taskStatus = append(taskStatus, ts)
@@ -200,10 +475,38 @@ func (s *Service) parseTaskState(swarmTask []swarm.Task) []TaskStatus {
 	return taskStatus
 }
 
-func (s *Service) isImageDeploy(swarmTask []swarm.Task, image string) bool {
+// replicas returns the desired replicas of replicated services, global and job services have none
+func replicas(swarmService swarm.Service) *uint64 {
+	if swarmService.Spec.Mode.Replicated == nil {
+		return nil
+	}
+
+	return swarmService.Spec.Mode.Replicated.Replicas
+}
+
+// SpecImage returns the image of the service spec without its digest, it is empty for a nil spec and for the
+// specs of plugin services, which have no container spec
+func SpecImage(spec *swarm.ServiceSpec) string {
+	if spec == nil || spec.TaskTemplate.ContainerSpec == nil {
+		return ""
+	}
+
+	return getImage(spec.TaskTemplate.ContainerSpec.Image)
+}
+
+// taskImage returns the image of the task, plugin tasks have none
+func taskImage(task swarm.Task) string {
+	if task.Spec.ContainerSpec == nil {
+		return ""
+	}
+
+	return task.Spec.ContainerSpec.Image
+}
+
+func isImageDeploy(swarmTask []swarm.Task, image string) bool {
 	imageDeployed := false
 	for _, task := range swarmTask {
-		if s.getImage(task.Spec.ContainerSpec.Image) == image {
+		if getImage(taskImage(task)) == image {
 			imageDeployed = true
 		}
 	}
@@ -211,22 +514,22 @@ func (s *Service) isImageDeploy(swarmTask []swarm.Task, image string) bool {
 	return imageDeployed
 }
 
-func (s *Service) getImage(image string) string {
+func getImage(image string) string {
 	currentImage := strings.Split(image, "@")
 	return currentImage[0]
 }
 
-func (s *Service) taskStateCount(serviceStatus ServiceStatus, image string) (int, int) {
+func taskStateCount(serviceStatus ServiceStatus, image string) (int, int) {
 	runningTaskCount := 0
 	errorTaskCount := 0
 
 	for _, ds := range serviceStatus.TaskStatus {
 
-		if (ds.State == swarm.TaskStateFailed || ds.State == swarm.TaskStateRejected) && ds.DesiredState == swarm.TaskStateShutdown && (s.getImage(ds.Image) == image || image == "") {
+		if (ds.State == swarm.TaskStateFailed || ds.State == swarm.TaskStateRejected) && ds.DesiredState == swarm.TaskStateShutdown && (getImage(ds.Image) == image || image == "") {
 			errorTaskCount = errorTaskCount + 1
 		}
 
-		if ds.State == swarm.TaskStateRunning && ds.DesiredState == swarm.TaskStateRunning && (s.getImage(ds.Image) == image || image == "") {
+		if ds.State == swarm.TaskStateRunning && ds.DesiredState == swarm.TaskStateRunning && (getImage(ds.Image) == image || image == "") {
 			runningTaskCount = runningTaskCount + 1
 		}
 	}
@@ -25,8 +25,9 @@ func TestServiceTestSuite(t *testing.T) {
 }
 
 func (s *ServiceTestSuite) Test_NewService_ReturnService() {
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, err := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 
+	assert.NoError(s.T(), err)
 	assert.NotNil(s.T(), service)
 	assert.Equal(s.T(), DockerHost, service.Host)
 }
@@ -36,7 +37,7 @@ func (s *ServiceTestSuite) Test_GetService_ReturnSwarmService() {
 	filterList := filters.NewArgs()
 	filterList.Add("name", serviceName)
 
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	swarmService, err := service.GetService(filterList)
 
 	assert.NoError(s.T(), err)
@@ -49,7 +50,7 @@ func (s *ServiceTestSuite) Test_GetService_ReturnEmptySwarmService_NoServiceFoun
 	filterList := filters.NewArgs()
 	filterList.Add("name", serviceName)
 
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	swarmService, err := service.GetService(filterList)
 
 	assert.NoError(s.T(), err)
@@ -61,7 +62,7 @@ func (s *ServiceTestSuite) Test_GetService_ReturnError_InvalidFilter() {
 	filterList := filters.NewArgs()
 	filterList.Add("invalidFilter", serviceName)
 
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	_, err := service.GetService(filterList)
 
 	assert.Error(s.T(), err, "Error response from daemon: {\"message\":\"Invalid filter 'invalidFilter'\"}")
@@ -72,7 +73,7 @@ func (s *ServiceTestSuite) Test_GetTask_ReturnSwarmTask() {
 	filterList := filters.NewArgs()
 	filterList.Add("name", serviceName)
 
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	swarmService, err := service.GetService(filterList)
 
 	assert.NoError(s.T(), err)
@@ -91,7 +92,7 @@ func (s *ServiceTestSuite) Test_GetTask_ReturnError_InvalidFilter() {
 	filterList := filters.NewArgs()
 	filterList.Add("name", serviceName)
 
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	swarmService, err := service.GetService(filterList)
 
 	assert.NoError(s.T(), err)
@@ -105,7 +106,7 @@ func (s *ServiceTestSuite) Test_GetTask_ReturnError_InvalidFilter() {
 }
 
 func (s *ServiceTestSuite) Test_GetDeploymentStatus_ReturnServiceStatus_ServiceNotExists() {
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	deploymentStatus, err := service.GetDeploymentStatus("my-service", "my-image:1.0.0")
 
 	assert.NoError(s.T(), err)
@@ -113,7 +114,7 @@ func (s *ServiceTestSuite) Test_GetDeploymentStatus_ReturnServiceStatus_ServiceN
 }
 
 func (s *ServiceTestSuite) Test_GetDeploymentStatus_ReturnDeploymentStatus_RunningDifferentImage() {
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	serviceName := "docker-routing-mesh"
 	deploymentStatus, err := service.GetDeploymentStatus(serviceName, "albertogviana/docker-routing-mesh:1.0.1")
 
@@ -128,7 +129,7 @@ func (s *ServiceTestSuite) Test_GetDeploymentStatus_ReturnServiceStatus() {
 		exec.Command("docker", "service", "scale", "docker-routing-mesh=1").Output()
 	}()
 
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	serviceName := "docker-routing-mesh"
 	image := "albertogviana/docker-routing-mesh:1.0.0"
 	deploymentStatus, err := service.GetDeploymentStatus(serviceName, image)
@@ -161,7 +162,7 @@ func (s *ServiceTestSuite) Test_GetDeploymentStatus_ReturnServiceStatusWithUpdat
 
 	exec.Command("docker", "service", "update", "--image", "albertogviana/docker-routing-mesh:2.0.0", "--replicas", "2", "docker-routing-mesh").Output()
 
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	serviceName := "docker-routing-mesh"
 	deploymentStatus, err := service.GetDeploymentStatus(serviceName, "albertogviana/docker-routing-mesh:2.0.0")
 
@@ -184,7 +185,7 @@ func (s *ServiceTestSuite) Test_GetDeploymentStatus_ReturnServiceStatusWithUpdat
 
 	exec.Command("docker", "service", "update", "--image", "albertogviana/docker-routing-mesh:error", "--replicas", "2", "docker-routing-mesh").Output()
 
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	serviceName := "docker-routing-mesh"
 	deploymentStatus, err := service.GetDeploymentStatus(serviceName, "albertogviana/docker-routing-mesh:error")
 
@@ -205,7 +206,7 @@ func (s *ServiceTestSuite) Test_GetDeploymentStatus_ReturnServiceStatusWithUpdat
 }
 
 func (s *ServiceTestSuite) Test_GetGetServiceStatus_ReturnServiceNotExists() {
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	deploymentStatus, err := service.GetServiceStatus("my-service")
 
 	assert.NoError(s.T(), err)
@@ -217,7 +218,7 @@ func (s *ServiceTestSuite) Test_GetServiceStatus_ReturnServiceStatus() {
 		exec.Command("docker", "service", "scale", "docker-routing-mesh=1").Output()
 	}()
 
-	service := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
 	serviceName := "docker-routing-mesh"
 	deploymentStatus, err := service.GetServiceStatus(serviceName)
 
@@ -239,6 +240,69 @@ func (s *ServiceTestSuite) Test_GetServiceStatus_ReturnServiceStatus() {
 	assert.Nil(s.T(), deploymentStatus2.UpdateStatus)
 }
 
+func (s *ServiceTestSuite) Test_GetStackStatus_ReturnStackStatus() {
+	defer removeTestService("status-test_web")
+
+	createTestService("status-test_web", []string{"com.docker.stack.namespace=status-test"}, "", "albertogviana/docker-routing-mesh:1.0.0")
+
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	stackStatus, err := service.GetStackStatus("status-test")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "status-test", stackStatus.Name)
+	assert.Len(s.T(), stackStatus.Services, 1)
+	assert.Equal(s.T(), "status-test_web", stackStatus.Services[0].Name)
+}
+
+func (s *ServiceTestSuite) Test_GetStackStatus_ReturnStackNotExists() {
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	stackStatus, err := service.GetStackStatus("my-stack")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "The my-stack stack was not found in the cluster.", stackStatus.Err)
+}
+
+func (s *ServiceTestSuite) Test_GetReadiness_ReturnReady() {
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	readiness := service.GetReadiness()
+
+	assert.True(s.T(), readiness.Ready)
+	assert.NotEmpty(s.T(), readiness.APIVersion)
+	assert.Len(s.T(), readiness.Checks, 3)
+}
+
+func (s *ServiceTestSuite) Test_GetReadiness_ReturnNotReady_DockerUnreachable() {
+	service, _ := NewService("tcp://127.0.0.1:1", DockerAPIVersion, map[string]string{})
+	readiness := service.GetReadiness()
+
+	assert.False(s.T(), readiness.Ready)
+	assert.Equal(s.T(), "docker", readiness.Checks[0].Name)
+	assert.False(s.T(), readiness.Checks[0].OK)
+}
+
+func (s *ServiceTestSuite) Test_Negotiate_ReturnInfo() {
+	service, _ := NewService(DockerHost, "", map[string]string{})
+	info, err := service.Negotiate()
+
+	assert.NoError(s.T(), err)
+	assert.NotEmpty(s.T(), info.APIVersion)
+	assert.NotEmpty(s.T(), info.ServerAPIVersion)
+	assert.Len(s.T(), info.Features, len(features))
+
+	cached, err := service.GetInfo()
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), info, cached)
+}
+
+func (s *ServiceTestSuite) Test_Negotiate_KeepsPinnedVersion() {
+	service, _ := NewService(DockerHost, DockerAPIVersion, map[string]string{})
+	info, err := service.Negotiate()
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "1.33", info.APIVersion)
+}
+
 // Util
 
 func createTestServices() {
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name of the spans of the Docker API calls
+const TracerName = "github.com/albertogviana/docker-swarm-service-status/service"
+
+var tracer = otel.Tracer(TracerName)
+
+// startCall starts the span of a Docker API call and counts the call. end records the error of the call, if any,
+// and ends the span.
+func (s *Service) startCall(ctx context.Context, operation string) (context.Context, func(err error)) {
+	ctx, span := tracer.Start(ctx, "docker."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("docker.host", s.Host), attribute.String("docker.operation", operation)))
+
+	callCounterFromContext(ctx).add()
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
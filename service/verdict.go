@@ -0,0 +1,108 @@
+package service
+
+import "github.com/docker/docker/api/types/swarm"
+
+// Verdict summarises the outcome of a deployment
+type Verdict string
+
+const (
+	// VerdictSucceeded means every desired replica is running the expected image
+	VerdictSucceeded Verdict = "succeeded"
+	// VerdictInProgress means the update is still rolling out
+	VerdictInProgress Verdict = "in-progress"
+	// VerdictFailed means the update was paused or the tasks keep failing
+	VerdictFailed Verdict = "failed"
+	// VerdictRolledBack means Docker is rolling back or has rolled back the update
+	VerdictRolledBack Verdict = "rolled-back"
+	// VerdictNotFound means the service does not exist in the cluster
+	VerdictNotFound Verdict = "not-found"
+	// VerdictUnknown means the status of the deployment could not be read from Docker
+	VerdictUnknown Verdict = "unknown"
+)
+
+// Verdicts lists every verdict from the best to the worst outcome
+var Verdicts = []Verdict{VerdictSucceeded, VerdictInProgress, VerdictUnknown, VerdictNotFound, VerdictFailed, VerdictRolledBack}
+
+// Verdict returns the outcome of the deployment described by the status
+func (s ServiceStatus) Verdict() Verdict {
+	if s.ID == "" {
+		return VerdictNotFound
+	}
+
+	if s.UpdateStatus != nil {
+		switch s.UpdateStatus.State {
+		case swarm.UpdateStateRollbackStarted, swarm.UpdateStateRollbackPaused, swarm.UpdateStateRollbackCompleted:
+			return VerdictRolledBack
+		case swarm.UpdateStatePaused:
+			return VerdictFailed
+		case swarm.UpdateStateUpdating:
+			return VerdictInProgress
+		}
+	}
+
+	if s.Err != "" {
+		return VerdictFailed
+	}
+
+	if s.pending() {
+		return VerdictInProgress
+	}
+
+	if s.Replicas != nil && uint64(s.RunningReplicas) < *s.Replicas {
+		return VerdictInProgress
+	}
+
+	return VerdictSucceeded
+}
+
+// pending reports whether the service spec has an image no task was created for yet, which happens right after
+// the service is updated
+func (s ServiceStatus) pending() bool {
+	if s.Image == "" || (s.Replicas != nil && *s.Replicas == 0) {
+		return false
+	}
+
+	for _, task := range s.TaskStatus {
+		if getImage(task.Image) == s.Image {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Verdict returns the worst verdict of the stack services, or VerdictNotFound when the stack has none
+func (s StackStatus) Verdict() Verdict {
+	if len(s.Services) == 0 {
+		return VerdictNotFound
+	}
+
+	verdicts := make([]Verdict, len(s.Services))
+	for i, serviceStatus := range s.Services {
+		verdicts[i] = serviceStatus.Verdict()
+	}
+
+	return WorstVerdict(verdicts...)
+}
+
+// WorstVerdict returns the worst of the verdicts, or VerdictSucceeded when there is none
+func WorstVerdict(verdicts ...Verdict) Verdict {
+	worst := VerdictSucceeded
+	for _, verdict := range verdicts {
+		if severity[verdict] > severity[worst] {
+			worst = verdict
+		}
+	}
+
+	return worst
+}
+
+// severity orders the verdicts from the best to the worst outcome
+var severity = map[Verdict]int{
+	VerdictSucceeded:  0,
+	VerdictInProgress: 1,
+	VerdictUnknown:    2,
+	VerdictNotFound:   3,
+	VerdictFailed:     4,
+	VerdictRolledBack: 5,
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/suite"
+)
+
+type VerdictTestSuite struct {
+	suite.Suite
+}
+
+func TestVerdictTestSuite(t *testing.T) {
+	suite.Run(t, new(VerdictTestSuite))
+}
+
+func (s *VerdictTestSuite) Test_Verdict_NotFound() {
+	status := ServiceStatus{Name: "my-service", Err: "The my-service service was not found in the cluster."}
+
+	s.Equal(VerdictNotFound, status.Verdict())
+}
+
+func (s *VerdictTestSuite) Test_Verdict_Succeeded() {
+	replicas := uint64(2)
+	status := ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Replicas: &replicas, RunningReplicas: 2}
+
+	s.Equal(VerdictSucceeded, status.Verdict())
+}
+
+func (s *VerdictTestSuite) Test_Verdict_InProgress_MissingReplicas() {
+	replicas := uint64(2)
+	status := ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Replicas: &replicas, RunningReplicas: 1}
+
+	s.Equal(VerdictInProgress, status.Verdict())
+}
+
+func (s *VerdictTestSuite) Test_Verdict_InProgress_Updating() {
+	status := ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Err: "failed 1 time(s)", UpdateStatus: &swarm.UpdateStatus{State: swarm.UpdateStateUpdating}}
+
+	s.Equal(VerdictInProgress, status.Verdict())
+}
+
+func (s *VerdictTestSuite) Test_Verdict_Failed() {
+	status := ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Err: "The image was not deployed."}
+
+	s.Equal(VerdictFailed, status.Verdict())
+
+	status = ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", UpdateStatus: &swarm.UpdateStatus{State: swarm.UpdateStatePaused}}
+
+	s.Equal(VerdictFailed, status.Verdict())
+}
+
+func (s *VerdictTestSuite) Test_Verdict_RolledBack() {
+	for _, state := range []swarm.UpdateState{swarm.UpdateStateRollbackStarted, swarm.UpdateStateRollbackPaused, swarm.UpdateStateRollbackCompleted} {
+		status := ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", UpdateStatus: &swarm.UpdateStatus{State: state}}
+
+		s.Equal(VerdictRolledBack, status.Verdict())
+	}
+}
+
+func (s *VerdictTestSuite) Test_StackVerdict_ReturnWorstVerdict() {
+	replicas := uint64(1)
+	succeeded := ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Replicas: &replicas, RunningReplicas: 1}
+	failed := ServiceStatus{ID: "evv1jw9o7981mrp0p50j1gy5k", UpdateStatus: &swarm.UpdateStatus{State: swarm.UpdateStatePaused}}
+
+	s.Equal(VerdictNotFound, StackStatus{Name: "prod"}.Verdict())
+	s.Equal(VerdictSucceeded, StackStatus{Name: "prod", Services: []ServiceStatus{succeeded}}.Verdict())
+	s.Equal(VerdictFailed, StackStatus{Name: "prod", Services: []ServiceStatus{succeeded, failed}}.Verdict())
+}
+
+func (s *VerdictTestSuite) Test_WorstVerdict() {
+	s.Equal(VerdictSucceeded, WorstVerdict())
+	s.Equal(VerdictInProgress, WorstVerdict(VerdictSucceeded, VerdictInProgress))
+	s.Equal(VerdictRolledBack, WorstVerdict(VerdictFailed, VerdictRolledBack, VerdictNotFound))
+	s.Equal(VerdictUnknown, WorstVerdict(VerdictSucceeded, VerdictUnknown, VerdictInProgress))
+	s.Equal(VerdictFailed, WorstVerdict(VerdictUnknown, VerdictFailed))
+}
+
+func (s *VerdictTestSuite) Test_Verdict_InProgress_NoTaskRunsTheSpecImage() {
+	replicas := uint64(2)
+	tasks := []TaskStatus{
+		{TaskID: "6c9bqmkd2c4sodi1ktsmm0jbd", State: swarm.TaskStateRunning, DesiredState: swarm.TaskStateRunning, Image: "nginx:1.24@sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac"},
+		{TaskID: "xk9b2tsjbhkxc3wgba1xwpx2w", State: swarm.TaskStateRunning, DesiredState: swarm.TaskStateRunning, Image: "nginx:1.24@sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac"},
+	}
+	status := ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Image: "nginx:1.25", Replicas: &replicas, RunningReplicas: 2, TaskStatus: tasks, UpdateStatus: &swarm.UpdateStatus{State: swarm.UpdateStateCompleted}}
+
+	s.Equal(VerdictInProgress, status.Verdict())
+
+	status.Replicas = nil
+	s.Equal(VerdictInProgress, status.Verdict())
+
+	status.Image = "nginx:1.24"
+	s.Equal(VerdictSucceeded, status.Verdict())
+}
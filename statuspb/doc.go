@@ -0,0 +1,4 @@
+// Package statuspb holds the gRPC API of docker-swarm-service-status, generated from status.proto
+package statuspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative status.proto
@@ -0,0 +1,453 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: status.proto
+
+package statuspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Status_GetServices_FullMethodName         = "/dockerswarmservicestatus.v1.Status/GetServices"
+	Status_GetTasks_FullMethodName            = "/dockerswarmservicestatus.v1.Status/GetTasks"
+	Status_GetNodes_FullMethodName            = "/dockerswarmservicestatus.v1.Status/GetNodes"
+	Status_GetServiceStatus_FullMethodName    = "/dockerswarmservicestatus.v1.Status/GetServiceStatus"
+	Status_GetDeploymentStatus_FullMethodName = "/dockerswarmservicestatus.v1.Status/GetDeploymentStatus"
+	Status_GetStackStatus_FullMethodName      = "/dockerswarmservicestatus.v1.Status/GetStackStatus"
+	Status_GetReadiness_FullMethodName        = "/dockerswarmservicestatus.v1.Status/GetReadiness"
+	Status_GetInfo_FullMethodName             = "/dockerswarmservicestatus.v1.Status/GetInfo"
+	Status_WatchDeployment_FullMethodName     = "/dockerswarmservicestatus.v1.Status/WatchDeployment"
+)
+
+// StatusClient is the client API for Status service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Status reports the deployment state of Docker Swarm services. Requests select a cluster with the
+// x-swarm-cluster metadata and authenticate with the authorization metadata, as the HTTP API does.
+type StatusClient interface {
+	// GetServices returns the services matching the Docker filters
+	GetServices(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ServiceList, error)
+	// GetTasks returns the tasks matching the Docker filters
+	GetTasks(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*TaskList, error)
+	// GetNodes returns the nodes matching the Docker filters, it requires the * scope
+	GetNodes(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*NodeList, error)
+	// GetServiceStatus returns the current state of a service
+	GetServiceStatus(ctx context.Context, in *ServiceStatusRequest, opts ...grpc.CallOption) (*ServiceStatus, error)
+	// GetDeploymentStatus returns the state of a service and whether the image was deployed
+	GetDeploymentStatus(ctx context.Context, in *DeploymentStatusRequest, opts ...grpc.CallOption) (*ServiceStatus, error)
+	// GetStackStatus returns the current state of every service of a stack
+	GetStackStatus(ctx context.Context, in *StackStatusRequest, opts ...grpc.CallOption) (*StackStatus, error)
+	// GetReadiness reports whether the Docker daemon is reachable and the node is an active swarm manager
+	GetReadiness(ctx context.Context, in *ReadinessRequest, opts ...grpc.CallOption) (*Readiness, error)
+	// GetInfo returns the negotiated Docker API version and the features supported by the daemon
+	GetInfo(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*Info, error)
+	// WatchDeployment streams the deployment status every time it changes until its verdict is no longer in-progress
+	WatchDeployment(ctx context.Context, in *WatchDeploymentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceStatus], error)
+}
+
+type statusClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStatusClient(cc grpc.ClientConnInterface) StatusClient {
+	return &statusClient{cc}
+}
+
+func (c *statusClient) GetServices(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ServiceList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServiceList)
+	err := c.cc.Invoke(ctx, Status_GetServices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) GetTasks(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*TaskList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskList)
+	err := c.cc.Invoke(ctx, Status_GetTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) GetNodes(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*NodeList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeList)
+	err := c.cc.Invoke(ctx, Status_GetNodes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) GetServiceStatus(ctx context.Context, in *ServiceStatusRequest, opts ...grpc.CallOption) (*ServiceStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServiceStatus)
+	err := c.cc.Invoke(ctx, Status_GetServiceStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) GetDeploymentStatus(ctx context.Context, in *DeploymentStatusRequest, opts ...grpc.CallOption) (*ServiceStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServiceStatus)
+	err := c.cc.Invoke(ctx, Status_GetDeploymentStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) GetStackStatus(ctx context.Context, in *StackStatusRequest, opts ...grpc.CallOption) (*StackStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StackStatus)
+	err := c.cc.Invoke(ctx, Status_GetStackStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) GetReadiness(ctx context.Context, in *ReadinessRequest, opts ...grpc.CallOption) (*Readiness, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Readiness)
+	err := c.cc.Invoke(ctx, Status_GetReadiness_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) GetInfo(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*Info, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Info)
+	err := c.cc.Invoke(ctx, Status_GetInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) WatchDeployment(ctx context.Context, in *WatchDeploymentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServiceStatus], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Status_ServiceDesc.Streams[0], Status_WatchDeployment_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchDeploymentRequest, ServiceStatus]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Status_WatchDeploymentClient = grpc.ServerStreamingClient[ServiceStatus]
+
+// StatusServer is the server API for Status service.
+// All implementations must embed UnimplementedStatusServer
+// for forward compatibility.
+//
+// Status reports the deployment state of Docker Swarm services. Requests select a cluster with the
+// x-swarm-cluster metadata and authenticate with the authorization metadata, as the HTTP API does.
+type StatusServer interface {
+	// GetServices returns the services matching the Docker filters
+	GetServices(context.Context, *ListRequest) (*ServiceList, error)
+	// GetTasks returns the tasks matching the Docker filters
+	GetTasks(context.Context, *ListRequest) (*TaskList, error)
+	// GetNodes returns the nodes matching the Docker filters, it requires the * scope
+	GetNodes(context.Context, *ListRequest) (*NodeList, error)
+	// GetServiceStatus returns the current state of a service
+	GetServiceStatus(context.Context, *ServiceStatusRequest) (*ServiceStatus, error)
+	// GetDeploymentStatus returns the state of a service and whether the image was deployed
+	GetDeploymentStatus(context.Context, *DeploymentStatusRequest) (*ServiceStatus, error)
+	// GetStackStatus returns the current state of every service of a stack
+	GetStackStatus(context.Context, *StackStatusRequest) (*StackStatus, error)
+	// GetReadiness reports whether the Docker daemon is reachable and the node is an active swarm manager
+	GetReadiness(context.Context, *ReadinessRequest) (*Readiness, error)
+	// GetInfo returns the negotiated Docker API version and the features supported by the daemon
+	GetInfo(context.Context, *InfoRequest) (*Info, error)
+	// WatchDeployment streams the deployment status every time it changes until its verdict is no longer in-progress
+	WatchDeployment(*WatchDeploymentRequest, grpc.ServerStreamingServer[ServiceStatus]) error
+	mustEmbedUnimplementedStatusServer()
+}
+
+// UnimplementedStatusServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStatusServer struct{}
+
+func (UnimplementedStatusServer) GetServices(context.Context, *ListRequest) (*ServiceList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServices not implemented")
+}
+func (UnimplementedStatusServer) GetTasks(context.Context, *ListRequest) (*TaskList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTasks not implemented")
+}
+func (UnimplementedStatusServer) GetNodes(context.Context, *ListRequest) (*NodeList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNodes not implemented")
+}
+func (UnimplementedStatusServer) GetServiceStatus(context.Context, *ServiceStatusRequest) (*ServiceStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServiceStatus not implemented")
+}
+func (UnimplementedStatusServer) GetDeploymentStatus(context.Context, *DeploymentStatusRequest) (*ServiceStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeploymentStatus not implemented")
+}
+func (UnimplementedStatusServer) GetStackStatus(context.Context, *StackStatusRequest) (*StackStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStackStatus not implemented")
+}
+func (UnimplementedStatusServer) GetReadiness(context.Context, *ReadinessRequest) (*Readiness, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReadiness not implemented")
+}
+func (UnimplementedStatusServer) GetInfo(context.Context, *InfoRequest) (*Info, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInfo not implemented")
+}
+func (UnimplementedStatusServer) WatchDeployment(*WatchDeploymentRequest, grpc.ServerStreamingServer[ServiceStatus]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchDeployment not implemented")
+}
+func (UnimplementedStatusServer) mustEmbedUnimplementedStatusServer() {}
+func (UnimplementedStatusServer) testEmbeddedByValue()                {}
+
+// UnsafeStatusServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StatusServer will
+// result in compilation errors.
+type UnsafeStatusServer interface {
+	mustEmbedUnimplementedStatusServer()
+}
+
+func RegisterStatusServer(s grpc.ServiceRegistrar, srv StatusServer) {
+	// If the following call pancis, it indicates UnimplementedStatusServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Status_ServiceDesc, srv)
+}
+
+func _Status_GetServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).GetServices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_GetServices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).GetServices(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_GetTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).GetTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_GetTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).GetTasks(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_GetNodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).GetNodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_GetNodes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).GetNodes(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_GetServiceStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).GetServiceStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_GetServiceStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).GetServiceStatus(ctx, req.(*ServiceStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_GetDeploymentStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeploymentStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).GetDeploymentStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_GetDeploymentStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).GetDeploymentStatus(ctx, req.(*DeploymentStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_GetStackStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StackStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).GetStackStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_GetStackStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).GetStackStatus(ctx, req.(*StackStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_GetReadiness_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadinessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).GetReadiness(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_GetReadiness_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).GetReadiness(ctx, req.(*ReadinessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_GetInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).GetInfo(ctx, req.(*InfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_WatchDeployment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchDeploymentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StatusServer).WatchDeployment(m, &grpc.GenericServerStream[WatchDeploymentRequest, ServiceStatus]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Status_WatchDeploymentServer = grpc.ServerStreamingServer[ServiceStatus]
+
+// Status_ServiceDesc is the grpc.ServiceDesc for Status service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Status_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dockerswarmservicestatus.v1.Status",
+	HandlerType: (*StatusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetServices",
+			Handler:    _Status_GetServices_Handler,
+		},
+		{
+			MethodName: "GetTasks",
+			Handler:    _Status_GetTasks_Handler,
+		},
+		{
+			MethodName: "GetNodes",
+			Handler:    _Status_GetNodes_Handler,
+		},
+		{
+			MethodName: "GetServiceStatus",
+			Handler:    _Status_GetServiceStatus_Handler,
+		},
+		{
+			MethodName: "GetDeploymentStatus",
+			Handler:    _Status_GetDeploymentStatus_Handler,
+		},
+		{
+			MethodName: "GetStackStatus",
+			Handler:    _Status_GetStackStatus_Handler,
+		},
+		{
+			MethodName: "GetReadiness",
+			Handler:    _Status_GetReadiness_Handler,
+		},
+		{
+			MethodName: "GetInfo",
+			Handler:    _Status_GetInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchDeployment",
+			Handler:       _Status_WatchDeployment_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "status.proto",
+}
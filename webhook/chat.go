@@ -0,0 +1,252 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// Format is the payload sent to a webhook
+type Format string
+
+const (
+	// FormatJSON sends the event as JSON
+	FormatJSON Format = "json"
+	// FormatSlack sends a Slack Block Kit message
+	FormatSlack Format = "slack"
+	// FormatTeams sends a Microsoft Teams MessageCard
+	FormatTeams Format = "teams"
+)
+
+// Templates are text/template overrides of the chat message, they are executed with the Event and may use the
+// tag and repository functions to split an image reference
+type Templates struct {
+	Title string `json:",omitempty"`
+	Text  string `json:",omitempty"`
+	// DiffURL links the message to the changes between the previous and the new image, there is no link when empty
+	DiffURL string `json:",omitempty"`
+}
+
+// DefaultTemplates are used for the templates a webhook does not override
+var DefaultTemplates = Templates{
+	Title: `{{.Service}} deployment {{.Verdict}}`,
+	Text:  `{{if .Image}}{{.Image}}{{if .PreviousImage}} (was {{.PreviousImage}}){{end}} {{end}}on the {{.Cluster}} cluster`,
+}
+
+var templateFuncs = template.FuncMap{
+	"tag":        tag,
+	"repository": repository,
+}
+
+// Message is a deployment event rendered for humans
+type Message struct {
+	Title   string
+	Text    string
+	Verdict service.Verdict
+	Fields  [][2]string
+	// Errors are the errors of the failed tasks
+	Errors  []string
+	DiffURL string
+}
+
+// NewMessage renders the event with the templates, falling back to DefaultTemplates
+func NewMessage(event Event, templates Templates) (Message, error) {
+	message := Message{Verdict: event.Verdict, Errors: taskErrors(event.Status)}
+
+	rendered := []*string{&message.Title, &message.Text, &message.DiffURL}
+	sources := []string{or(templates.Title, DefaultTemplates.Title), or(templates.Text, DefaultTemplates.Text), or(templates.DiffURL, DefaultTemplates.DiffURL)}
+
+	for i, source := range sources {
+		text, err := execute(source, event)
+		if err != nil {
+			return message, err
+		}
+		*rendered[i] = text
+	}
+
+	message.Fields = [][2]string{{"Service", event.Service}, {"Verdict", string(event.Verdict)}, {"Cluster", event.Cluster}}
+	if event.Image != "" {
+		message.Fields = append(message.Fields, [2]string{"Image", event.Image})
+	}
+	if event.Status.Err != "" {
+		message.Fields = append(message.Fields, [2]string{"Error", event.Status.Err})
+	}
+
+	return message, nil
+}
+
+// validate parses the format and the templates of the webhook
+func (w Webhook) validate() error {
+	switch w.Format {
+	case "", FormatJSON, FormatSlack, FormatTeams:
+	default:
+		return fmt.Errorf("the %s format is not supported, use json, slack or teams", w.Format)
+	}
+
+	for _, source := range []string{w.Templates.Title, w.Templates.Text, w.Templates.DiffURL} {
+		if _, err := template.New("message").Funcs(templateFuncs).Parse(source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// payload renders the event in the format of the webhook
+func (w Webhook) payload(event Event) ([]byte, error) {
+	if w.Format == "" || w.Format == FormatJSON {
+		return json.Marshal(event)
+	}
+
+	message, err := NewMessage(event, w.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.Format == FormatTeams {
+		return json.Marshal(teamsCard(message))
+	}
+
+	return json.Marshal(slackMessage(message))
+}
+
+// slackMessage renders the message as Slack Block Kit blocks, with the title as notification fallback
+func slackMessage(message Message) map[string]interface{} {
+	title := fmt.Sprintf("%s %s", slackEmoji[message.Verdict], message.Title)
+
+	fields := []map[string]interface{}{}
+	for _, field := range message.Fields {
+		fields = append(fields, map[string]interface{}{"type": "mrkdwn", "text": fmt.Sprintf("*%s*\n%s", field[0], field[1])})
+	}
+
+	blocks := []map[string]interface{}{
+		{"type": "header", "text": map[string]interface{}{"type": "plain_text", "text": title}},
+		{"type": "section", "text": map[string]interface{}{"type": "mrkdwn", "text": message.Text}, "fields": fields},
+	}
+
+	if len(message.Errors) > 0 {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]interface{}{"type": "mrkdwn", "text": "*Failed tasks*\n• " + strings.Join(message.Errors, "\n• ")},
+		})
+	}
+
+	if message.DiffURL != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "actions",
+			"elements": []map[string]interface{}{
+				{"type": "button", "text": map[string]interface{}{"type": "plain_text", "text": "View diff"}, "url": message.DiffURL},
+			},
+		})
+	}
+
+	return map[string]interface{}{"text": title, "blocks": blocks}
+}
+
+// teamsCard renders the message as a Microsoft Teams MessageCard
+func teamsCard(message Message) map[string]interface{} {
+	facts := []map[string]interface{}{}
+	for _, field := range message.Fields {
+		facts = append(facts, map[string]interface{}{"name": field[0], "value": field[1]})
+	}
+
+	sections := []map[string]interface{}{{"facts": facts}}
+	if len(message.Errors) > 0 {
+		sections = append(sections, map[string]interface{}{"title": "Failed tasks", "text": strings.Join(message.Errors, "<br>")})
+	}
+
+	card := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"themeColor": teamsColour[message.Verdict],
+		"summary":    message.Title,
+		"title":      message.Title,
+		"text":       message.Text,
+		"sections":   sections,
+	}
+
+	if message.DiffURL != "" {
+		card["potentialAction"] = []map[string]interface{}{
+			{"@type": "OpenUri", "name": "View diff", "targets": []map[string]interface{}{{"os": "default", "uri": message.DiffURL}}},
+		}
+	}
+
+	return card
+}
+
+var slackEmoji = map[service.Verdict]string{
+	service.VerdictSucceeded:  ":white_check_mark:",
+	service.VerdictInProgress: ":hourglass_flowing_sand:",
+	service.VerdictUnknown:    ":grey_question:",
+	service.VerdictNotFound:   ":grey_question:",
+	service.VerdictFailed:     ":x:",
+	service.VerdictRolledBack: ":rewind:",
+}
+
+var teamsColour = map[service.Verdict]string{
+	service.VerdictSucceeded:  "2EB67D",
+	service.VerdictInProgress: "36C5F0",
+	service.VerdictUnknown:    "808080",
+	service.VerdictNotFound:   "808080",
+	service.VerdictFailed:     "E01E5A",
+	service.VerdictRolledBack: "ECB22E",
+}
+
+func execute(source string, event Event) (string, error) {
+	tmpl, err := template.New("message").Funcs(templateFuncs).Parse(source)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := tmpl.Execute(buffer, event); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func taskErrors(status service.ServiceStatus) []string {
+	errors := []string{}
+	for _, task := range status.TaskStatus {
+		if task.Err == "" || (task.State != swarm.TaskStateFailed && task.State != swarm.TaskStateRejected) {
+			continue
+		}
+		errors = append(errors, fmt.Sprintf("%s: %s", task.TaskID, task.Err))
+	}
+
+	return errors
+}
+
+// tag returns the tag of an image reference, without the digest
+func tag(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		return image[idx+1:]
+	}
+
+	return "latest"
+}
+
+// repository returns an image reference without its tag and digest
+func repository(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		return image[:idx]
+	}
+
+	return image
+}
+
+func or(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
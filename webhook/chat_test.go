@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+func (s *WebhookTestSuite) failedEvent() Event {
+	event := NewEvent("production", service.ServiceStatus{
+		ID:   "tt3otdsnkd1kgh80u45bwmcb4",
+		Name: "prod_web",
+		Err:  "The image was not deployed.",
+		TaskStatus: []service.TaskStatus{
+			{TaskID: "evv1jw9o7981mrp0p50j1gy5k", State: swarm.TaskStateRunning, Timestamp: time.Now()},
+			{TaskID: "ka8a7cwzf0pq4opcscd1yf7rn", State: swarm.TaskStateFailed, Err: "task: non-zero exit (1)"},
+		},
+	}, map[string]string{"com.docker.stack.namespace": "prod", "team": "payments"}, service.VerdictInProgress)
+	event.Image = "acme/web:1.1.0"
+	event.PreviousImage = "acme/web:1.0.0"
+
+	return event
+}
+
+func (s *WebhookTestSuite) Test_NewMessage_DefaultTemplates() {
+	message, err := NewMessage(s.failedEvent(), Templates{})
+
+	s.NoError(err)
+	s.Equal("prod_web deployment failed", message.Title)
+	s.Equal("acme/web:1.1.0 (was acme/web:1.0.0) on the production cluster", message.Text)
+	s.Equal([]string{"ka8a7cwzf0pq4opcscd1yf7rn: task: non-zero exit (1)"}, message.Errors)
+	s.Empty(message.DiffURL)
+}
+
+func (s *WebhookTestSuite) Test_NewMessage_ListsFailedTasksOfPolledStatus() {
+	event, err := pollFailedDeployment()
+	s.NoError(err)
+
+	message, err := NewMessage(event, Templates{})
+
+	s.NoError(err)
+	s.Equal("prod_web deployment failed", message.Title)
+	s.Equal([]string{"j1bps1jgxx0vkb4d0fd6dtbyy: task: non-zero exit (1)", "m4pbfaq0khbk9tq6tfozrldw3: task: non-zero exit (1)"}, message.Errors)
+}
+
+func (s *WebhookTestSuite) Test_NewMessage_OverriddenTemplates() {
+	message, err := NewMessage(s.failedEvent(), Templates{
+		Title:   `[{{index .Labels "team"}}] {{.Service}} is {{.Verdict}}`,
+		DiffURL: `https://github.com/{{repository .Image}}/compare/{{tag .PreviousImage}}...{{tag .Image}}`,
+	})
+
+	s.NoError(err)
+	s.Equal("[payments] prod_web is failed", message.Title)
+	s.Equal("acme/web:1.1.0 (was acme/web:1.0.0) on the production cluster", message.Text)
+	s.Equal("https://github.com/acme/web/compare/1.0.0...1.1.0", message.DiffURL)
+}
+
+func (s *WebhookTestSuite) Test_Payload_Slack() {
+	webhook := Webhook{URL: s.receiver.URL, Format: FormatSlack, Templates: Templates{DiffURL: "https://example.com/diff"}}
+
+	payload, err := webhook.payload(s.failedEvent())
+	s.NoError(err)
+
+	message := struct {
+		Text   string
+		Blocks []map[string]interface{}
+	}{}
+	s.NoError(json.Unmarshal(payload, &message))
+
+	s.Equal(":x: prod_web deployment failed", message.Text)
+	s.Len(message.Blocks, 4)
+	s.Equal("header", message.Blocks[0]["type"])
+	s.Equal("section", message.Blocks[1]["type"])
+	s.Contains(string(payload), `"text":"*Image*\nacme/web:1.1.0"`)
+	s.Contains(string(payload), `"text":"*Failed tasks*\n• ka8a7cwzf0pq4opcscd1yf7rn: task: non-zero exit (1)"`)
+	s.Equal("actions", message.Blocks[3]["type"])
+	s.Contains(string(payload), `"url":"https://example.com/diff"`)
+}
+
+func (s *WebhookTestSuite) Test_Payload_Teams() {
+	webhook := Webhook{URL: s.receiver.URL, Format: FormatTeams}
+
+	payload, err := webhook.payload(s.failedEvent())
+	s.NoError(err)
+
+	card := map[string]interface{}{}
+	s.NoError(json.Unmarshal(payload, &card))
+
+	s.Equal("MessageCard", card["@type"])
+	s.Equal("E01E5A", card["themeColor"])
+	s.Equal("prod_web deployment failed", card["title"])
+	s.Contains(string(payload), `{"name":"Service","value":"prod_web"}`)
+	s.Contains(string(payload), `{"text":"ka8a7cwzf0pq4opcscd1yf7rn: task: non-zero exit (1)","title":"Failed tasks"}`)
+	s.NotContains(card, "potentialAction")
+}
+
+func (s *WebhookTestSuite) Test_Notify_RecordsTemplateErrors() {
+	dispatcher := s.dispatcher(Webhook{URL: s.receiver.URL, Format: FormatSlack, Templates: Templates{Title: "{{.Missing}}"}})
+
+	dispatcher.Notify(s.failedEvent())
+
+	s.Len(s.requests, 0)
+	s.Contains(dispatcher.Deliveries()[0].Err, "can't evaluate field Missing")
+}
+
+func (s *WebhookTestSuite) Test_Matches_StacksAndLabels() {
+	event := s.failedEvent()
+
+	s.True(Webhook{Stacks: []string{"pro*"}}.Matches(event))
+	s.False(Webhook{Stacks: []string{"staging"}}.Matches(event))
+	s.True(Webhook{Labels: map[string]string{"team": "payments"}}.Matches(event))
+	s.True(Webhook{Labels: map[string]string{"team": ""}}.Matches(event))
+	s.False(Webhook{Labels: map[string]string{"team": "search"}}.Matches(event))
+	s.False(Webhook{Labels: map[string]string{"owner": ""}}.Matches(event))
+
+	delete(event.Labels, "com.docker.stack.namespace")
+	s.False(Webhook{Stacks: []string{"*"}}.Matches(event))
+}
+
+func (s *WebhookTestSuite) Test_Validate() {
+	s.NoError(Webhook{Format: FormatTeams, Templates: Templates{Title: "{{.Service}}"}}.validate())
+	s.EqualError(Webhook{Format: "discord"}.validate(), "the discord format is not supported, use json, slack or teams")
+	s.Error(Webhook{Templates: Templates{Text: "{{.Service"}}.validate())
+}
+
+func (s *WebhookTestSuite) Test_Tag() {
+	s.Equal("1.0.0", tag("registry:5000/acme/web:1.0.0@sha256:87e5c74f"))
+	s.Equal("latest", tag("registry:5000/acme/web"))
+	s.Equal("registry:5000/acme/web", repository("registry:5000/acme/web:1.0.0"))
+}
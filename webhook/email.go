@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+)
+
+// RecipientsLabel is the service label listing the comma separated addresses notified about its deployments
+const RecipientsLabel = "com.docker-swarm-service-status.email"
+
+// DefaultEmailVerdicts are the verdicts an email is sent for when the SMTPConfig does not list any
+var DefaultEmailVerdicts = []service.Verdict{service.VerdictFailed, service.VerdictRolledBack}
+
+// SMTPConfig defines how to send emails
+type SMTPConfig struct {
+	// Address is the host:port of the SMTP server
+	Address string
+	// Username and Password authenticate with PLAIN auth when Username is not empty
+	Username string
+	Password string
+	From     string
+	// Recipients are notified about the services without the RecipientsLabel
+	Recipients []string
+	// Verdicts limits the events sent by email, it defaults to DefaultEmailVerdicts
+	Verdicts []service.Verdict
+}
+
+// EmailNotifier sends a summary of the failed and rolled back deployments by email
+type EmailNotifier struct {
+	Config SMTPConfig
+	send   func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailNotifier returns a new instance of the EmailNotifier structure
+func NewEmailNotifier(config SMTPConfig) *EmailNotifier {
+	return &EmailNotifier{
+		Config: config,
+		send:   smtp.SendMail,
+	}
+}
+
+// Notify implements Notifier
+func (n *EmailNotifier) Notify(event Event) {
+	if err := n.Send(event); err != nil {
+		log.Printf("Unable to email the %s event of the %s service: %s", event.Type, event.Service, err.Error())
+	}
+}
+
+// Send emails the event summary to the recipients of the service, it does nothing when the event verdict is
+// not notified or the service has no recipients
+func (n *EmailNotifier) Send(event Event) error {
+	if !n.notifies(event.Verdict) {
+		return nil
+	}
+
+	recipients := n.recipients(event)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	var auth smtp.Auth
+	if n.Config.Username != "" {
+		host, _, _ := net.SplitHostPort(n.Config.Address)
+		auth = smtp.PlainAuth("", n.Config.Username, n.Config.Password, host)
+	}
+
+	return n.send(n.Config.Address, auth, n.Config.From, recipients, Email(n.Config.From, recipients, event))
+}
+
+func (n *EmailNotifier) notifies(verdict service.Verdict) bool {
+	verdicts := n.Config.Verdicts
+	if len(verdicts) == 0 {
+		verdicts = DefaultEmailVerdicts
+	}
+
+	for _, notified := range verdicts {
+		if notified == verdict {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *EmailNotifier) recipients(event Event) []string {
+	label, ok := event.Labels[RecipientsLabel]
+	if !ok {
+		return n.Config.Recipients
+	}
+
+	recipients := []string{}
+	for _, recipient := range strings.Split(label, ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+
+	return recipients
+}
+
+// Email returns the RFC 5322 message summarising the event: service, image, verdict, task errors and a timeline
+func Email(from string, to []string, event Event) []byte {
+	message := &bytes.Buffer{}
+
+	fmt.Fprintf(message, "From: %s\r\n", from)
+	fmt.Fprintf(message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(message, "Subject: [%s] %s deployment on the %s cluster\r\n", event.Verdict, event.Service, event.Cluster)
+	fmt.Fprintf(message, "Date: %s\r\n", event.Timestamp.Format(time.RFC1123Z))
+	fmt.Fprintf(message, "Message-ID: <%s@docker-swarm-service-status>\r\n", event.ID)
+	fmt.Fprintf(message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(message, "Content-Type: text/plain; charset=UTF-8\r\n")
+	fmt.Fprintf(message, "\r\n")
+
+	fmt.Fprintf(message, "Service: %s\r\n", event.Service)
+	fmt.Fprintf(message, "Cluster: %s\r\n", event.Cluster)
+	if event.Image != "" {
+		fmt.Fprintf(message, "Image: %s\r\n", event.Image)
+	}
+	if event.PreviousImage != "" {
+		fmt.Fprintf(message, "Previous image: %s\r\n", event.PreviousImage)
+	}
+	fmt.Fprintf(message, "Verdict: %s\r\n", event.Verdict)
+	if event.Status.Err != "" {
+		fmt.Fprintf(message, "Error: %s\r\n", event.Status.Err)
+	}
+
+	if errors := taskErrors(event.Status); len(errors) > 0 {
+		fmt.Fprintf(message, "\r\nFailed tasks:\r\n")
+		for _, err := range errors {
+			fmt.Fprintf(message, "  %s\r\n", err)
+		}
+	}
+
+	fmt.Fprintf(message, "\r\nTimeline:\r\n")
+	for _, entry := range timeline(event) {
+		fmt.Fprintf(message, "  %s  %s\r\n", entry.at.UTC().Format(time.RFC3339), entry.text)
+	}
+
+	return message.Bytes()
+}
+
+type timelineEntry struct {
+	at   time.Time
+	text string
+}
+
+// timeline orders the update and task state changes of the event
+func timeline(event Event) []timelineEntry {
+	entries := []timelineEntry{}
+
+	if update := event.Status.UpdateStatus; update != nil {
+		if update.StartedAt != nil {
+			entries = append(entries, timelineEntry{*update.StartedAt, "update started"})
+		}
+		if update.CompletedAt != nil {
+			entries = append(entries, timelineEntry{*update.CompletedAt, fmt.Sprintf("update %s: %s", update.State, update.Message)})
+		}
+	}
+
+	for _, task := range event.Status.TaskStatus {
+		if task.Timestamp.IsZero() {
+			continue
+		}
+
+		text := fmt.Sprintf("task %s %s", task.TaskID, task.State)
+		if task.Err != "" {
+			text = fmt.Sprintf("%s: %s", text, task.Err)
+		}
+		entries = append(entries, timelineEntry{task.Timestamp, text})
+	}
+
+	entries = append(entries, timelineEntry{event.Timestamp, fmt.Sprintf("deployment %s", event.Verdict)})
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].at.Before(entries[j].at)
+	})
+
+	return entries
+}
@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/suite"
+)
+
+// smtpServer is a minimal SMTP stand-in recording the messages it receives
+type smtpServer struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	messages []smtpMessage
+	wg       sync.WaitGroup
+}
+
+type smtpMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+func newSMTPServer() *smtpServer {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	server := &smtpServer{listener: listener}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.wg.Add(1)
+			go server.handle(conn)
+		}
+	}()
+
+	return server
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+	message := smtpMessage{}
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message.From = strings.Trim(strings.TrimSpace(line)[10:], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.To = append(message.To, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data := &bytes.Buffer{}
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil || line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			message.Data = data.String()
+			s.mutex.Lock()
+			s.messages = append(s.messages, message)
+			s.mutex.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpServer) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+type EmailTestSuite struct {
+	suite.Suite
+	smtp *smtpServer
+}
+
+func TestEmailTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailTestSuite))
+}
+
+func (s *EmailTestSuite) SetupTest() {
+	s.smtp = newSMTPServer()
+}
+
+func (s *EmailTestSuite) TearDownTest() {
+	s.smtp.Close()
+}
+
+func (s *EmailTestSuite) notifier(recipients ...string) *EmailNotifier {
+	return NewEmailNotifier(SMTPConfig{Address: s.smtp.listener.Addr().String(), From: "swarm@example.com", Recipients: recipients})
+}
+
+func (s *EmailTestSuite) event(verdict service.Verdict, labels map[string]string) Event {
+	started := time.Date(2017, time.November, 26, 21, 47, 0, 0, time.UTC)
+	completed := started.Add(2 * time.Minute)
+
+	status := service.ServiceStatus{
+		ID:   "tt3otdsnkd1kgh80u45bwmcb4",
+		Name: "prod_web",
+		TaskStatus: []service.TaskStatus{
+			{TaskID: "ka8a7cwzf0pq4opcscd1yf7rn", State: swarm.TaskStateFailed, Err: "task: non-zero exit (1)", Timestamp: started.Add(time.Minute)},
+		},
+	}
+	switch verdict {
+	case service.VerdictFailed:
+		status.Err = "The image was not deployed."
+	case service.VerdictRolledBack:
+		status.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStateRollbackCompleted, StartedAt: &started, CompletedAt: &completed, Message: "rollback completed"}
+	case service.VerdictSucceeded:
+		status.TaskStatus = nil
+	}
+
+	event := NewEvent("production", status, labels, service.VerdictInProgress)
+	event.Image = "acme/web:1.1.0"
+	event.PreviousImage = "acme/web:1.0.0"
+	event.Timestamp = completed.Add(time.Second)
+
+	return event
+}
+
+func (s *EmailTestSuite) Test_Send_UsesRecipientsLabel() {
+	event := s.event(service.VerdictRolledBack, map[string]string{RecipientsLabel: "payments@example.com, oncall@example.com"})
+
+	s.NoError(s.notifier("ops@example.com").Send(event))
+
+	s.smtp.Close()
+	s.Len(s.smtp.messages, 1)
+	s.Equal("swarm@example.com", s.smtp.messages[0].From)
+	s.Equal([]string{"payments@example.com", "oncall@example.com"}, s.smtp.messages[0].To)
+
+	data := s.smtp.messages[0].Data
+	s.Contains(data, "To: payments@example.com, oncall@example.com\r\n")
+	s.Contains(data, "Subject: [rolled-back] prod_web deployment on the production cluster\r\n")
+	s.Contains(data, "Image: acme/web:1.1.0\r\nPrevious image: acme/web:1.0.0\r\nVerdict: rolled-back\r\n")
+	s.Contains(data, "Failed tasks:\r\n  ka8a7cwzf0pq4opcscd1yf7rn: task: non-zero exit (1)\r\n")
+	s.Contains(data, "Timeline:\r\n"+
+		"  2017-11-26T21:47:00Z  update started\r\n"+
+		"  2017-11-26T21:48:00Z  task ka8a7cwzf0pq4opcscd1yf7rn failed: task: non-zero exit (1)\r\n"+
+		"  2017-11-26T21:49:00Z  update rollback_completed: rollback completed\r\n"+
+		"  2017-11-26T21:49:01Z  deployment rolled-back\r\n")
+}
+
+func (s *EmailTestSuite) Test_Email_ListsTaskErrorsOfPolledStatus() {
+	event, err := pollFailedDeployment()
+	s.NoError(err)
+
+	data := string(Email("swarm@example.com", []string{"payments@example.com"}, event))
+
+	s.Contains(data, "Subject: [failed] prod_web deployment on the production cluster\r\n")
+	s.Contains(data, "Failed tasks:\r\n"+
+		"  j1bps1jgxx0vkb4d0fd6dtbyy: task: non-zero exit (1)\r\n"+
+		"  m4pbfaq0khbk9tq6tfozrldw3: task: non-zero exit (1)\r\n")
+	s.Contains(data, "  2017-11-26T21:47:35Z  update started\r\n"+
+		"  2017-11-26T21:47:36Z  task j1bps1jgxx0vkb4d0fd6dtbyy failed: task: non-zero exit (1)\r\n"+
+		"  2017-11-26T21:48:35Z  task m4pbfaq0khbk9tq6tfozrldw3 failed: task: non-zero exit (1)\r\n"+
+		"  2017-11-26T21:49:35Z  update paused: update paused due to failure or early termination of task m4pbfaq0khbk9tq6tfozrldw3\r\n")
+}
+
+func (s *EmailTestSuite) Test_Send_FallsBackToDefaultRecipients() {
+	s.NoError(s.notifier("ops@example.com").Send(s.event(service.VerdictFailed, nil)))
+
+	s.smtp.Close()
+	s.Len(s.smtp.messages, 1)
+	s.Equal([]string{"ops@example.com"}, s.smtp.messages[0].To)
+}
+
+func (s *EmailTestSuite) Test_Send_SkipsSucceededDeployments() {
+	s.NoError(s.notifier("ops@example.com").Send(s.event(service.VerdictSucceeded, nil)))
+
+	s.smtp.Close()
+	s.Len(s.smtp.messages, 0)
+}
+
+func (s *EmailTestSuite) Test_Send_SkipsServicesWithoutRecipients() {
+	s.NoError(s.notifier().Send(s.event(service.VerdictFailed, nil)))
+
+	s.smtp.Close()
+	s.Len(s.smtp.messages, 0)
+}
@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// Watcher polls the services of a cluster and notifies about every deployment reaching a verdict
+type Watcher struct {
+	Cluster  string
+	Service  service.Services
+	Notifier Notifier
+	// Interval is the delay between two polls
+	Interval time.Duration
+	// Verdicts are the verdicts that end a deployment, it defaults to DefaultVerdicts
+	Verdicts []service.Verdict
+
+	states map[string]state
+	primed bool
+}
+
+// state is what the watcher remembers about a service between two polls
+type state struct {
+	verdict service.Verdict
+	// update identifies the last update of the service, a new update may reach the same verdict
+	update string
+}
+
+// NewWatcher returns a new instance of the Watcher structure polling every 10 seconds
+func NewWatcher(cluster string, svc service.Services, notifier Notifier) *Watcher {
+	return &Watcher{
+		Cluster:  cluster,
+		Service:  svc,
+		Notifier: notifier,
+		Interval: 10 * time.Second,
+		states:   map[string]state{},
+	}
+}
+
+// Run polls the cluster and notifies the Notifier about every event until ctx is done
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := w.Poll()
+		if err != nil {
+			log.Printf("Unable to watch the services of the %s cluster: %s", w.Cluster, err.Error())
+		}
+
+		// deliveries may be retried for minutes, they must not delay the next poll
+		for _, event := range events {
+			go w.Notifier.Notify(event)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll compares the verdict of every service with the previous poll and returns an event for each transition to
+// a final verdict. The first poll only records the current verdicts, services created afterwards are reported.
+func (w *Watcher) Poll() ([]Event, error) {
+	swarmServices, err := w.Service.GetServices(filters.NewArgs())
+	if err != nil {
+		return nil, err
+	}
+
+	events := []Event{}
+	states := map[string]state{}
+	for _, swarmService := range swarmServices {
+		status, err := service.StatusOf(w.Service, swarmService)
+		if err != nil {
+			return nil, err
+		}
+
+		current := state{status.Verdict(), updateKey(swarmService)}
+		previous, seen := w.states[swarmService.ID]
+		states[swarmService.ID] = current
+
+		if !w.primed || !w.final(current.verdict) || (seen && previous == current) {
+			continue
+		}
+
+		event := NewEvent(w.Cluster, status, swarmService.Spec.Labels, previous.verdict)
+		event.Image = service.SpecImage(&swarmService.Spec)
+		event.PreviousImage = service.SpecImage(swarmService.PreviousSpec)
+		events = append(events, event)
+	}
+
+	w.states = states
+	w.primed = true
+
+	return events, nil
+}
+
+func (w *Watcher) final(verdict service.Verdict) bool {
+	verdicts := w.Verdicts
+	if len(verdicts) == 0 {
+		verdicts = DefaultVerdicts
+	}
+
+	for _, final := range verdicts {
+		if final == verdict {
+			return true
+		}
+	}
+
+	return false
+}
+
+func updateKey(swarmService swarm.Service) string {
+	if swarmService.UpdateStatus == nil || swarmService.UpdateStatus.StartedAt == nil {
+		return ""
+	}
+
+	return swarmService.UpdateStatus.StartedAt.String()
+}
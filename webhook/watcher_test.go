@@ -0,0 +1,316 @@
+package webhook
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/api/types/swarm/runtime"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatcherTestSuite struct {
+	suite.Suite
+}
+
+func TestWatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(WatcherTestSuite))
+}
+
+func (s *WatcherTestSuite) swarmService(id string, name string, updateStartedAt time.Time) swarm.Service {
+	replicas := uint64(2)
+	swarmService := swarm.Service{ID: id}
+	swarmService.Spec.Name = name
+	swarmService.Spec.Labels = map[string]string{"com.docker.stack.namespace": "prod"}
+	swarmService.Spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+	swarmService.Spec.TaskTemplate.ContainerSpec = &swarm.ContainerSpec{Image: "acme/web:1.1.0@sha256:87e5c74f8042848893440b24a33ea0e3494b9da475987b0e704f0d3262bce3cd"}
+	swarmService.PreviousSpec = &swarm.ServiceSpec{TaskTemplate: swarm.TaskSpec{ContainerSpec: &swarm.ContainerSpec{Image: "acme/web:1.0.0"}}}
+	swarmService.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStateUpdating, StartedAt: &updateStartedAt}
+
+	return swarmService
+}
+
+// newTask returns a task of a service created, and last updated, at the given time
+func newTask(id string, image string, desired swarm.TaskState, state swarm.TaskState, createdAt time.Time) swarm.Task {
+	task := swarm.Task{ID: id, DesiredState: desired}
+	task.CreatedAt = createdAt
+	task.Status.Timestamp = createdAt
+	task.Status.State = state
+	task.Spec.ContainerSpec = &swarm.ContainerSpec{Image: image}
+	if state == swarm.TaskStateFailed {
+		task.Status.Err = "task: non-zero exit (1)"
+	}
+
+	return task
+}
+
+// pollFailedDeployment polls a service whose update to acme/web:1.1.0 was paused after two of its tasks failed and
+// returns the event of the failed deployment
+func pollFailedDeployment() (Event, error) {
+	started := time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC)
+	image := "acme/web:1.1.0@sha256:87e5c74f8042848893440b24a33ea0e3494b9da475987b0e704f0d3262bce3cd"
+
+	swarmService := swarm.Service{ID: "tt3otdsnkd1kgh80u45bwmcb4"}
+	swarmService.Spec.Name = "prod_web"
+	swarmService.Spec.Labels = map[string]string{"com.docker.stack.namespace": "prod", "team": "payments"}
+	swarmService.Spec.TaskTemplate.ContainerSpec = &swarm.ContainerSpec{Image: image}
+	swarmService.PreviousSpec = &swarm.ServiceSpec{TaskTemplate: swarm.TaskSpec{ContainerSpec: &swarm.ContainerSpec{Image: "acme/web:1.0.0"}}}
+	swarmService.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStateUpdating, StartedAt: &started}
+
+	paused := swarmService
+	completed := started.Add(2 * time.Minute)
+	paused.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStatePaused, StartedAt: &started, CompletedAt: &completed, Message: "update paused due to failure or early termination of task m4pbfaq0khbk9tq6tfozrldw3"}
+
+	tasks := []swarm.Task{
+		newTask("ydk0rdazigdpwi3e4ly1lcd5b", "acme/web:1.0.0", swarm.TaskStateRunning, swarm.TaskStateRunning, started.Add(-time.Hour)),
+		newTask("j1bps1jgxx0vkb4d0fd6dtbyy", image, swarm.TaskStateShutdown, swarm.TaskStateFailed, started.Add(time.Second)),
+		newTask("m4pbfaq0khbk9tq6tfozrldw3", image, swarm.TaskStateShutdown, swarm.TaskStateFailed, started.Add(time.Minute)),
+	}
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{swarmService}, nil).Once()
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{paused}, nil).Once()
+	serviceMock.On("GetTask", tasksOf("tt3otdsnkd1kgh80u45bwmcb4")).Return(tasks, nil)
+
+	watcher := NewWatcher("production", serviceMock, Notifiers{})
+	if _, err := watcher.Poll(); err != nil {
+		return Event{}, err
+	}
+
+	events, err := watcher.Poll()
+	if err != nil || len(events) != 1 {
+		return Event{}, fmt.Errorf("Expected one event, got %d: %v", len(events), err)
+	}
+
+	return events[0], nil
+}
+
+func tasksOf(id string) filters.Args {
+	return filters.NewArgs(filters.Arg("service", id))
+}
+
+func (s *WatcherTestSuite) Test_Poll_ReportsTransitions() {
+	started := time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC)
+	updating := s.swarmService("tt3otdsnkd1kgh80u45bwmcb4", "prod_web", started)
+	completed := s.swarmService("tt3otdsnkd1kgh80u45bwmcb4", "prod_web", started)
+	completed.UpdateStatus.State = swarm.UpdateStateCompleted
+
+	image := "acme/web:1.1.0@sha256:87e5c74f8042848893440b24a33ea0e3494b9da475987b0e704f0d3262bce3cd"
+	first := newTask("evv1jw9o7981mrp0p50j1gy5k", image, swarm.TaskStateRunning, swarm.TaskStateRunning, started.Add(time.Second))
+	second := newTask("xk9b2tsjbhkxc3wgba1xwpx2w", image, swarm.TaskStateRunning, swarm.TaskStateRunning, started.Add(time.Minute))
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{updating}, nil).Twice()
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{completed}, nil).Twice()
+	serviceMock.On("GetTask", tasksOf("tt3otdsnkd1kgh80u45bwmcb4")).Return([]swarm.Task{first}, nil).Twice()
+	serviceMock.On("GetTask", tasksOf("tt3otdsnkd1kgh80u45bwmcb4")).Return([]swarm.Task{first, second}, nil).Twice()
+
+	watcher := NewWatcher("default", serviceMock, Notifiers{})
+
+	events, err := watcher.Poll()
+	s.NoError(err)
+	s.Empty(events, "the first poll only records the verdicts")
+
+	events, _ = watcher.Poll()
+	s.Empty(events, "the deployment is still in progress")
+
+	events, _ = watcher.Poll()
+	s.Len(events, 1)
+	s.Equal("deployment.succeeded", events[0].Type)
+	s.Equal("default", events[0].Cluster)
+	s.Equal("prod_web", events[0].Service)
+	s.Equal(service.VerdictInProgress, events[0].Previous)
+	s.Equal("prod", events[0].Labels["com.docker.stack.namespace"])
+	s.Equal("acme/web:1.1.0", events[0].Image)
+	s.Equal("acme/web:1.0.0", events[0].PreviousImage)
+	s.Equal(2, events[0].Status.RunningReplicas)
+
+	events, _ = watcher.Poll()
+	s.Empty(events, "the verdict did not change")
+
+	serviceMock.AssertNotCalled(s.T(), "GetServiceStatus", mock.Anything)
+	serviceMock.AssertNotCalled(s.T(), "GetService", mock.Anything)
+}
+
+func (s *WatcherTestSuite) Test_Poll_ReportsNewUpdateWithSameVerdict() {
+	first := time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC)
+	paused := s.swarmService("tt3otdsnkd1kgh80u45bwmcb4", "prod_web", first)
+	paused.UpdateStatus.State = swarm.UpdateStatePaused
+	pausedAgain := s.swarmService("tt3otdsnkd1kgh80u45bwmcb4", "prod_web", first.Add(time.Hour))
+	pausedAgain.UpdateStatus.State = swarm.UpdateStatePaused
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{paused}, nil).Once()
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{pausedAgain}, nil).Once()
+	serviceMock.On("GetTask", tasksOf("tt3otdsnkd1kgh80u45bwmcb4")).Return([]swarm.Task{}, nil)
+
+	watcher := NewWatcher("default", serviceMock, Notifiers{})
+	watcher.Poll()
+	events, _ := watcher.Poll()
+
+	s.Len(events, 1)
+	s.Equal(service.VerdictFailed, events[0].Verdict)
+	s.Equal(service.VerdictFailed, events[0].Previous)
+}
+
+func (s *WatcherTestSuite) Test_Poll_ReportsNewServices() {
+	created := s.swarmService("tt3otdsnkd1kgh80u45bwmcb4", "prod_web", time.Time{})
+	created.UpdateStatus = nil
+	image := "acme/web:1.1.0@sha256:87e5c74f8042848893440b24a33ea0e3494b9da475987b0e704f0d3262bce3cd"
+	tasks := []swarm.Task{
+		newTask("evv1jw9o7981mrp0p50j1gy5k", image, swarm.TaskStateRunning, swarm.TaskStateRunning, time.Time{}),
+		newTask("xk9b2tsjbhkxc3wgba1xwpx2w", image, swarm.TaskStateRunning, swarm.TaskStateRunning, time.Time{}),
+	}
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{}, nil).Once()
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{created}, nil).Once()
+	serviceMock.On("GetTask", tasksOf("tt3otdsnkd1kgh80u45bwmcb4")).Return(tasks, nil)
+
+	watcher := NewWatcher("default", serviceMock, Notifiers{})
+	watcher.Poll()
+	events, _ := watcher.Poll()
+
+	s.Len(events, 1)
+	s.Equal(service.VerdictSucceeded, events[0].Verdict)
+	s.Equal(service.Verdict(""), events[0].Previous)
+}
+
+func (s *WatcherTestSuite) Test_Poll_ReportsFailedTasksOfServicesSharingAPrefix() {
+	started := time.Date(2017, time.November, 26, 21, 47, 35, 0, time.UTC)
+	web := s.swarmService("tt3otdsnkd1kgh80u45bwmcb4", "web", started)
+	admin := s.swarmService("evv1jw9o7981mrp0p50j1gy5k", "web-admin", started)
+
+	image := "acme/web:1.1.0@sha256:87e5c74f8042848893440b24a33ea0e3494b9da475987b0e704f0d3262bce3cd"
+	webTasks := []swarm.Task{
+		newTask("6c9bqmkd2c4sodi1ktsmm0jbd", image, swarm.TaskStateRunning, swarm.TaskStateRunning, started.Add(time.Second)),
+		newTask("xk9b2tsjbhkxc3wgba1xwpx2w", image, swarm.TaskStateRunning, swarm.TaskStateRunning, started.Add(time.Minute)),
+		newTask("q3zsd3x2dhgbjmpkq5yq7sl5b", "acme/web:1.0.0", swarm.TaskStateShutdown, swarm.TaskStateShutdown, started.Add(-time.Hour)),
+	}
+	adminTasks := []swarm.Task{
+		newTask("j1bps1jgxx0vkb4d0fd6dtbyy", image, swarm.TaskStateShutdown, swarm.TaskStateFailed, started.Add(time.Second)),
+		newTask("m4pbfaq0khbk9tq6tfozrldw3", image, swarm.TaskStateShutdown, swarm.TaskStateFailed, started.Add(time.Minute)),
+		newTask("u8f2lcfuiyyd2hqywmrl8qsmz", image, swarm.TaskStateShutdown, swarm.TaskStateFailed, started.Add(-time.Hour)),
+		newTask("ydk0rdazigdpwi3e4ly1lcd5b", "acme/web:1.0.0", swarm.TaskStateRunning, swarm.TaskStateRunning, started.Add(-2*time.Hour)),
+	}
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{web, admin}, nil).Once()
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{s.completed(web), s.paused(admin)}, nil).Once()
+	serviceMock.On("GetTask", tasksOf("tt3otdsnkd1kgh80u45bwmcb4")).Return(webTasks, nil)
+	serviceMock.On("GetTask", tasksOf("evv1jw9o7981mrp0p50j1gy5k")).Return(adminTasks, nil)
+
+	watcher := NewWatcher("default", serviceMock, Notifiers{})
+	watcher.Poll()
+	events, err := watcher.Poll()
+
+	s.NoError(err)
+	s.Len(events, 2)
+
+	s.Equal("web", events[0].Service)
+	s.Equal(service.VerdictSucceeded, events[0].Verdict)
+	s.Equal(2, events[0].Status.RunningReplicas)
+	s.Len(events[0].Status.TaskStatus, 2, "the tasks replaced by the update are dropped")
+
+	s.Equal("web-admin", events[1].Service)
+	s.Equal(service.VerdictFailed, events[1].Verdict)
+	s.Equal("evv1jw9o7981mrp0p50j1gy5k", events[1].Status.ID)
+	s.Equal(1, events[1].Status.RunningReplicas)
+	s.Equal(2, events[1].Status.FailedReplicas, "the failures of previous updates are dropped")
+	s.Equal("task: non-zero exit (1)", events[1].Status.TaskStatus[0].Err)
+
+	serviceMock.AssertNumberOfCalls(s.T(), "GetTask", 4)
+	serviceMock.AssertNotCalled(s.T(), "GetServiceStatus", mock.Anything)
+}
+
+func (s *WatcherTestSuite) Test_Poll_ReportsPluginServices() {
+	plugin := swarm.Service{ID: "tt3otdsnkd1kgh80u45bwmcb4"}
+	plugin.Spec.Name = "prod_volumes"
+	plugin.Spec.Mode.Global = &swarm.GlobalService{}
+	plugin.Spec.TaskTemplate.PluginSpec = &runtime.PluginSpec{Name: "vieux/sshfs", Remote: "vieux/sshfs:latest"}
+
+	tasks := []swarm.Task{{ID: "evv1jw9o7981mrp0p50j1gy5k", DesiredState: swarm.TaskStateRunning}, {ID: "xk9b2tsjbhkxc3wgba1xwpx2w", DesiredState: swarm.TaskStateShutdown}}
+	tasks[0].Status.State = swarm.TaskStateRunning
+	tasks[1].Status.State = swarm.TaskStateFailed
+	for i := range tasks {
+		tasks[i].Spec.PluginSpec = plugin.Spec.TaskTemplate.PluginSpec
+	}
+
+	serviceMock := new(ServiceMock)
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{}, nil).Once()
+	serviceMock.On("GetServices", filters.NewArgs()).Return([]swarm.Service{plugin}, nil).Once()
+	serviceMock.On("GetTask", tasksOf("tt3otdsnkd1kgh80u45bwmcb4")).Return(tasks, nil)
+
+	watcher := NewWatcher("default", serviceMock, Notifiers{})
+	watcher.Poll()
+	events, err := watcher.Poll()
+
+	s.NoError(err)
+	s.Require().Len(events, 1)
+	s.Equal("prod_volumes", events[0].Service)
+	s.Require().Len(events[0].Status.TaskStatus, 2)
+	s.Equal("", events[0].Status.TaskStatus[0].Image, "plugin tasks have no image")
+}
+
+func (s *WatcherTestSuite) completed(swarmService swarm.Service) swarm.Service {
+	swarmService.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStateCompleted, StartedAt: swarmService.UpdateStatus.StartedAt}
+	return swarmService
+}
+
+func (s *WatcherTestSuite) paused(swarmService swarm.Service) swarm.Service {
+	swarmService.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStatePaused, StartedAt: swarmService.UpdateStatus.StartedAt, Message: "update paused due to failure or early termination of task j1bps1jgxx0vkb4d0fd6dtbyy"}
+	return swarmService
+}
+
+type ServiceMock struct {
+	mock.Mock
+}
+
+func (s *ServiceMock) GetDeploymentStatus(serviceName string, image string) (service.ServiceStatus, error) {
+	args := s.Called(serviceName, image)
+	return args.Get(0).(service.ServiceStatus), args.Error(1)
+}
+
+func (s *ServiceMock) GetServiceStatus(serviceName string) (service.ServiceStatus, error) {
+	args := s.Called(serviceName)
+	return args.Get(0).(service.ServiceStatus), args.Error(1)
+}
+
+func (s *ServiceMock) GetStackStatus(stackName string) (service.StackStatus, error) {
+	args := s.Called(stackName)
+	return args.Get(0).(service.StackStatus), args.Error(1)
+}
+
+func (s *ServiceMock) GetInfo() (service.Info, error) {
+	args := s.Called()
+	return args.Get(0).(service.Info), args.Error(1)
+}
+
+func (s *ServiceMock) GetReadiness() service.Readiness {
+	args := s.Called()
+	return args.Get(0).(service.Readiness)
+}
+
+func (s *ServiceMock) GetServices(filter filters.Args) ([]swarm.Service, error) {
+	args := s.Called(filter)
+	return args.Get(0).([]swarm.Service), args.Error(1)
+}
+
+func (s *ServiceMock) GetService(filter filters.Args) (swarm.Service, error) {
+	args := s.Called(filter)
+	return args.Get(0).(swarm.Service), args.Error(1)
+}
+
+func (s *ServiceMock) GetTask(filter filters.Args) ([]swarm.Task, error) {
+	args := s.Called(filter)
+	return args.Get(0).([]swarm.Task), args.Error(1)
+}
+
+func (s *ServiceMock) GetNodes(filter filters.Args) ([]swarm.Node, error) {
+	args := s.Called(filter)
+	return args.Get(0).([]swarm.Node), args.Error(1)
+}
@@ -0,0 +1,382 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body, prefixed with "sha256="
+const SignatureHeader = "X-Webhook-Signature"
+
+// EventHeader carries the type of the event, e.g. deployment.failed
+const EventHeader = "X-Webhook-Event"
+
+// DeliveryHeader carries the ID of the event, it is the same on every retry
+const DeliveryHeader = "X-Webhook-Delivery"
+
+// DefaultDeliveryLogSize is how many deliveries the Dispatcher keeps in its log
+const DefaultDeliveryLogSize = 100
+
+// DefaultVerdicts are the verdicts a webhook is notified about when it does not list any
+var DefaultVerdicts = []service.Verdict{service.VerdictSucceeded, service.VerdictFailed, service.VerdictRolledBack}
+
+// Event describes a deployment that reached a verdict
+type Event struct {
+	ID string
+	// Type is "deployment." followed by the verdict
+	Type    string
+	Cluster string
+	Service string
+	Labels  map[string]string `json:",omitempty"`
+	// Image is the image of the service spec, PreviousImage the one it replaced
+	Image         string `json:",omitempty"`
+	PreviousImage string `json:",omitempty"`
+	Verdict       service.Verdict
+	// Previous is the verdict of the service on the previous poll, empty for new services
+	Previous  service.Verdict
+	Status    service.ServiceStatus
+	Timestamp time.Time
+}
+
+// NewEvent returns the event of a service reaching the verdict of its status
+func NewEvent(cluster string, status service.ServiceStatus, labels map[string]string, previous service.Verdict) Event {
+	return Event{
+		ID:        newID(),
+		Type:      fmt.Sprintf("deployment.%s", status.Verdict()),
+		Cluster:   cluster,
+		Service:   status.Name,
+		Labels:    labels,
+		Verdict:   status.Verdict(),
+		Previous:  previous,
+		Status:    status,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// Notifier is notified about deployment events
+type Notifier interface {
+	Notify(event Event)
+}
+
+// Notifiers notifies every notifier in turn
+type Notifiers []Notifier
+
+// Notify implements Notifier
+func (n Notifiers) Notify(event Event) {
+	for _, notifier := range n {
+		notifier.Notify(event)
+	}
+}
+
+// Webhook is an URL notified about deployment events
+type Webhook struct {
+	URL string
+	// Secret signs the payload when not empty
+	Secret string `json:",omitempty"`
+	// Verdicts limits the events sent to the webhook, it defaults to DefaultVerdicts
+	Verdicts []service.Verdict `json:",omitempty"`
+	// Stacks limits the events to the services of the stacks matching one of the patterns, e.g. "prod-*"
+	Stacks []string `json:",omitempty"`
+	// Labels limits the events to the services having every label, an empty value only requires the label to be set
+	Labels map[string]string `json:",omitempty"`
+	// Format is the payload sent to the webhook, the event as JSON when empty
+	Format Format `json:",omitempty"`
+	// Templates override the chat message of the slack and teams formats
+	Templates Templates
+}
+
+// Matches reports whether the webhook should be notified about the event
+func (w Webhook) Matches(event Event) bool {
+	return w.matchesVerdict(event) && w.matchesStack(event) && w.matchesLabels(event)
+}
+
+func (w Webhook) matchesVerdict(event Event) bool {
+	verdicts := w.Verdicts
+	if len(verdicts) == 0 {
+		verdicts = DefaultVerdicts
+	}
+
+	for _, verdict := range verdicts {
+		if verdict == event.Verdict {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w Webhook) matchesStack(event Event) bool {
+	if len(w.Stacks) == 0 {
+		return true
+	}
+
+	stack, ok := event.Labels[service.StackNamespaceLabel]
+	if !ok {
+		return false
+	}
+
+	for _, pattern := range w.Stacks {
+		if matched, _ := path.Match(pattern, stack); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w Webhook) matchesLabels(event Event) bool {
+	for name, value := range w.Labels {
+		actual, ok := event.Labels[name]
+		if !ok || (value != "" && value != actual) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LoadWebhooks reads a JSON file with a list of webhooks, for instance
+// [{"URL": "https://ci.example.com/hooks/swarm", "Secret": "s3cr3t", "Verdicts": ["failed", "rolled-back"]},
+// {"URL": "https://hooks.slack.com/services/T000/B000/XXX", "Format": "slack", "Stacks": ["prod"]}]
+func LoadWebhooks(filename string) ([]Webhook, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	webhooks := []Webhook{}
+	if err := json.NewDecoder(file).Decode(&webhooks); err != nil {
+		return nil, fmt.Errorf("Unable to parse the webhooks file %s: %s", filename, err.Error())
+	}
+
+	for i, webhook := range webhooks {
+		if webhook.URL == "" {
+			return nil, fmt.Errorf("The webhook %d has no URL.", i+1)
+		}
+
+		if err := webhook.validate(); err != nil {
+			return nil, fmt.Errorf("The webhook %d is invalid: %s", i+1, err.Error())
+		}
+	}
+
+	return webhooks, nil
+}
+
+// Sign returns the value of the SignatureHeader for the payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Delivery records the outcome of sending an event to a webhook. Its URL is only the scheme and host of the
+// webhook, the path and query of incoming webhook URLs are secret.
+type Delivery struct {
+	ID         string
+	Type       string
+	Cluster    string
+	Service    string
+	URL        string
+	Delivered  bool
+	Attempts   int
+	StatusCode int    `json:",omitempty"`
+	Err        string `json:",omitempty"`
+	Timestamp  time.Time
+	Duration   string
+}
+
+// Deliveries is the delivery log, from the most to the least recent delivery
+type Deliveries []Delivery
+
+// Rows implements report.Tabular
+func (d Deliveries) Rows() [][]string {
+	rows := [][]string{{"ID", "TYPE", "SERVICE", "URL", "DELIVERED", "ATTEMPTS", "STATUS", "ERROR"}}
+	for _, delivery := range d {
+		rows = append(rows, []string{delivery.ID, delivery.Type, delivery.Service, delivery.URL, fmt.Sprintf("%t", delivery.Delivered), fmt.Sprintf("%d", delivery.Attempts), fmt.Sprintf("%d", delivery.StatusCode), delivery.Err})
+	}
+
+	return rows
+}
+
+// redact returns the scheme and host of the webhook URL
+func redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+}
+
+// Dispatcher POSTs events as JSON to the webhooks interested in them and keeps a log of the deliveries
+type Dispatcher struct {
+	Webhooks   []Webhook
+	HTTPClient *http.Client
+	// Retries is how many times a delivery is retried after a network error, a 429 or a 5xx response
+	Retries int
+	// Backoff is the delay before the first retry, it doubles on every retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// LogSize is how many deliveries are kept in the log
+	LogSize int
+
+	mutex      sync.Mutex
+	deliveries Deliveries
+}
+
+// NewDispatcher returns a new instance of the Dispatcher structure with the default retry policy
+func NewDispatcher(webhooks []Webhook) *Dispatcher {
+	return &Dispatcher{
+		Webhooks:   webhooks,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		Retries:    5,
+		Backoff:    time.Second,
+		MaxBackoff: time.Minute,
+		LogSize:    DefaultDeliveryLogSize,
+	}
+}
+
+// Notify implements Notifier, it delivers the event to every matching webhook concurrently and returns once
+// every delivery succeeded or ran out of retries
+func (d *Dispatcher) Notify(event Event) {
+	var wg sync.WaitGroup
+	for _, webhook := range d.Webhooks {
+		if !webhook.Matches(event) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(webhook Webhook) {
+			defer wg.Done()
+			d.record(d.deliver(webhook, event))
+		}(webhook)
+	}
+	wg.Wait()
+}
+
+// Deliveries returns the delivery log, from the most to the least recent delivery
+func (d *Dispatcher) Deliveries() Deliveries {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	deliveries := make(Deliveries, len(d.deliveries))
+	copy(deliveries, d.deliveries)
+
+	return deliveries
+}
+
+func (d *Dispatcher) record(delivery Delivery) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.deliveries = append(Deliveries{delivery}, d.deliveries...)
+	if d.LogSize > 0 && len(d.deliveries) > d.LogSize {
+		d.deliveries = d.deliveries[:d.LogSize]
+	}
+}
+
+func (d *Dispatcher) deliver(webhook Webhook, event Event) Delivery {
+	start := time.Now()
+	delivery := Delivery{
+		ID:        event.ID,
+		Type:      event.Type,
+		Cluster:   event.Cluster,
+		Service:   event.Service,
+		URL:       redact(webhook.URL),
+		Timestamp: start.UTC(),
+	}
+
+	payload, err := webhook.payload(event)
+	if err != nil {
+		delivery.Err = err.Error()
+		delivery.Duration = time.Since(start).String()
+		return delivery
+	}
+
+	backoff := d.Backoff
+	for {
+		delivery.Attempts++
+		statusCode, err := d.post(webhook, event, payload)
+		delivery.StatusCode = statusCode
+		delivery.Err = ""
+
+		if err == nil && statusCode >= 200 && statusCode < 300 {
+			delivery.Delivered = true
+			break
+		}
+
+		if err != nil {
+			delivery.Err = strings.Replace(err.Error(), webhook.URL, delivery.URL, -1)
+		} else {
+			delivery.Err = fmt.Sprintf("The webhook returned %d %s.", statusCode, http.StatusText(statusCode))
+		}
+
+		if delivery.Attempts > d.Retries || !retryable(statusCode, err) {
+			break
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if d.MaxBackoff > 0 && backoff > d.MaxBackoff {
+			backoff = d.MaxBackoff
+		}
+	}
+
+	delivery.Duration = time.Since(start).String()
+	return delivery
+}
+
+func (d *Dispatcher) post(webhook Webhook, event Event, payload []byte) (int, error) {
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "docker-swarm-service-status-webhook")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID)
+	if webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(webhook.Secret, payload))
+	}
+
+	resp, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// retryable reports whether a delivery failing with the status code or err may succeed when retried
+func retryable(statusCode int, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+func newID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
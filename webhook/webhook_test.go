@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/albertogviana/docker-swarm-service-status/service"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookTestSuite struct {
+	suite.Suite
+	mutex    sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	codes    []int
+	receiver *httptest.Server
+}
+
+func TestWebhookTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookTestSuite))
+}
+
+func (s *WebhookTestSuite) SetupTest() {
+	s.requests = nil
+	s.bodies = nil
+	s.codes = nil
+	s.receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		body, _ := ioutil.ReadAll(r.Body)
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, body)
+
+		code := http.StatusNoContent
+		if len(s.codes) > 0 {
+			code, s.codes = s.codes[0], s.codes[1:]
+		}
+		w.WriteHeader(code)
+	}))
+}
+
+func (s *WebhookTestSuite) TearDownTest() {
+	s.receiver.Close()
+}
+
+func (s *WebhookTestSuite) dispatcher(webhooks ...Webhook) *Dispatcher {
+	dispatcher := NewDispatcher(webhooks)
+	dispatcher.Backoff = time.Millisecond
+
+	return dispatcher
+}
+
+func (s *WebhookTestSuite) event(verdict service.Verdict) Event {
+	status := service.ServiceStatus{ID: "tt3otdsnkd1kgh80u45bwmcb4", Name: "prod_web"}
+	if verdict == service.VerdictFailed {
+		status.Err = "The image was not deployed."
+	}
+
+	return NewEvent("default", status, map[string]string{"com.docker.stack.namespace": "prod"}, service.VerdictInProgress)
+}
+
+func (s *WebhookTestSuite) Test_Notify_PostsSignedPayload() {
+	dispatcher := s.dispatcher(Webhook{URL: s.receiver.URL, Secret: "s3cr3t"})
+	event := s.event(service.VerdictFailed)
+
+	dispatcher.Notify(event)
+
+	s.Len(s.requests, 1)
+	s.Equal("POST", s.requests[0].Method)
+	s.Equal("application/json", s.requests[0].Header.Get("Content-Type"))
+	s.Equal("deployment.failed", s.requests[0].Header.Get(EventHeader))
+	s.Equal(event.ID, s.requests[0].Header.Get(DeliveryHeader))
+	s.Equal(Sign("s3cr3t", s.bodies[0]), s.requests[0].Header.Get(SignatureHeader))
+
+	received := Event{}
+	s.NoError(json.Unmarshal(s.bodies[0], &received))
+	s.Equal("prod_web", received.Service)
+	s.Equal(service.VerdictFailed, received.Verdict)
+	s.Equal(service.VerdictInProgress, received.Previous)
+	s.Equal("prod", received.Labels["com.docker.stack.namespace"])
+}
+
+func (s *WebhookTestSuite) Test_Notify_SkipsUnsignedHeaderWithoutSecret() {
+	s.dispatcher(Webhook{URL: s.receiver.URL}).Notify(s.event(service.VerdictFailed))
+
+	s.Len(s.requests, 1)
+	s.Empty(s.requests[0].Header.Get(SignatureHeader))
+}
+
+func (s *WebhookTestSuite) Test_Notify_FiltersVerdicts() {
+	dispatcher := s.dispatcher(Webhook{URL: s.receiver.URL, Verdicts: []service.Verdict{service.VerdictRolledBack}})
+
+	dispatcher.Notify(s.event(service.VerdictFailed))
+
+	s.Len(s.requests, 0)
+	s.Len(dispatcher.Deliveries(), 0)
+}
+
+func (s *WebhookTestSuite) Test_Notify_RetriesWithBackoff() {
+	s.codes = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+	dispatcher := s.dispatcher(Webhook{URL: s.receiver.URL})
+
+	dispatcher.Notify(s.event(service.VerdictFailed))
+
+	s.Len(s.requests, 3)
+	s.Equal(s.requests[0].Header.Get(DeliveryHeader), s.requests[2].Header.Get(DeliveryHeader))
+
+	deliveries := dispatcher.Deliveries()
+	s.Len(deliveries, 1)
+	s.True(deliveries[0].Delivered)
+	s.Equal(3, deliveries[0].Attempts)
+	s.Equal(http.StatusNoContent, deliveries[0].StatusCode)
+	s.Empty(deliveries[0].Err)
+}
+
+func (s *WebhookTestSuite) Test_Notify_GivesUp() {
+	s.codes = []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}
+	dispatcher := s.dispatcher(Webhook{URL: s.receiver.URL})
+	dispatcher.Retries = 2
+
+	dispatcher.Notify(s.event(service.VerdictFailed))
+
+	deliveries := dispatcher.Deliveries()
+	s.Len(s.requests, 3)
+	s.False(deliveries[0].Delivered)
+	s.Equal(3, deliveries[0].Attempts)
+	s.Equal("The webhook returned 500 Internal Server Error.", deliveries[0].Err)
+}
+
+func (s *WebhookTestSuite) Test_Notify_DoesNotRetryClientErrors() {
+	s.codes = []int{http.StatusBadRequest}
+	dispatcher := s.dispatcher(Webhook{URL: s.receiver.URL})
+
+	dispatcher.Notify(s.event(service.VerdictFailed))
+
+	s.Len(s.requests, 1)
+	s.Equal(1, dispatcher.Deliveries()[0].Attempts)
+}
+
+func (s *WebhookTestSuite) Test_Deliveries_HideTheSecretOfTheURL() {
+	dispatcher := s.dispatcher(Webhook{URL: s.receiver.URL + "/services/T000/B000/XXX?token=s3cr3t"}, Webhook{URL: "http://127.0.0.1:1/services/T000/B000/XXX"})
+
+	dispatcher.Notify(s.event(service.VerdictFailed))
+
+	deliveries := dispatcher.Deliveries()
+	s.Require().Len(deliveries, 2)
+	for _, delivery := range deliveries {
+		s.NotContains(delivery.URL, "XXX")
+		s.NotContains(delivery.Err, "XXX")
+	}
+	s.Contains([]string{deliveries[0].URL, deliveries[1].URL}, s.receiver.URL)
+	s.Contains([]string{deliveries[0].URL, deliveries[1].URL}, "http://127.0.0.1:1")
+}
+
+func (s *WebhookTestSuite) Test_Deliveries_KeepsMostRecent() {
+	dispatcher := s.dispatcher(Webhook{URL: s.receiver.URL})
+	dispatcher.LogSize = 2
+
+	events := []Event{s.event(service.VerdictFailed), s.event(service.VerdictSucceeded), s.event(service.VerdictFailed)}
+	for _, event := range events {
+		dispatcher.Notify(event)
+	}
+
+	deliveries := dispatcher.Deliveries()
+	s.Len(deliveries, 2)
+	s.Equal(events[2].ID, deliveries[0].ID)
+	s.Equal(events[1].ID, deliveries[1].ID)
+}
+
+func (s *WebhookTestSuite) Test_LoadWebhooks() {
+	file, _ := ioutil.TempFile("", "webhooks")
+	defer os.Remove(file.Name())
+	file.WriteString(`[{"URL": "https://ci.example.com/hooks/swarm", "Secret": "s3cr3t", "Verdicts": ["failed"]}]`)
+	file.Close()
+
+	webhooks, err := LoadWebhooks(file.Name())
+
+	s.NoError(err)
+	s.Equal([]Webhook{{URL: "https://ci.example.com/hooks/swarm", Secret: "s3cr3t", Verdicts: []service.Verdict{service.VerdictFailed}}}, webhooks)
+}
+
+func (s *WebhookTestSuite) Test_LoadWebhooks_RequiresURL() {
+	file, _ := ioutil.TempFile("", "webhooks")
+	defer os.Remove(file.Name())
+	file.WriteString(`[{"Secret": "s3cr3t"}]`)
+	file.Close()
+
+	_, err := LoadWebhooks(file.Name())
+
+	s.EqualError(err, "The webhook 1 has no URL.")
+}